@@ -0,0 +1,53 @@
+package smoother
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WESmootherAdaptive applies the Whittaker-Eilers smoothing function to y with a separate lambda for every
+// sample, so sharp transients can be preserved while flat regions are smoothed hard. lambdas must hold one value
+// per value of y. Each difference of order d penalized spans d+1 samples and is weighted by the smallest lambda
+// among them, so a region with a small lambda is not stiffened by its neighbours.
+func WESmootherAdaptive(y []float64, lambdas []float64, d int) ([]float64, error) {
+	if len(lambdas) != len(y) {
+		return nil, fmt.Errorf("%w: lambdas has %d values, y has %d", ErrLengthMismatch, len(lambdas), len(y))
+	}
+	if err := checkLength(len(y), d); err != nil {
+		return nil, err
+	}
+	if err := checkFinite(y); err != nil {
+		return nil, err
+	}
+	for i, lambda := range lambdas {
+		if err := checkLambda(lambda); err != nil {
+			return nil, fmt.Errorf("lambda at index %d: %w", i, err)
+		}
+	}
+	return solvePenalized(y, nil, adaptivePenaltyMatrix(lambdas, d), nil)
+}
+
+// LambdaFunc returns the smoothing parameter at the i-th sample, taken at position x.
+type LambdaFunc func(i int, x float64) float64
+
+// WESmootherLambdaFunc is like WESmootherAdaptive, but asks lambda for the smoothing parameter of every sample, so
+// smoothing can be relaxed near annotated events without building the lambdas up front. x holds the positions
+// passed to lambda and must be as long as y; when x is nil the index is passed as the position.
+func WESmootherLambdaFunc(y, x []float64, lambda LambdaFunc, d int) ([]float64, error) {
+	if lambda == nil {
+		return nil, errors.New("no lambda function given")
+	}
+	if x != nil && len(x) != len(y) {
+		return nil, fmt.Errorf("%w: x has %d values, y has %d", ErrLengthMismatch, len(x), len(y))
+	}
+
+	lambdas := make([]float64, len(y))
+	for i := range lambdas {
+		pos := float64(i)
+		if x != nil {
+			pos = x[i]
+		}
+		lambdas[i] = lambda(i, pos)
+	}
+	return WESmootherAdaptive(y, lambdas, d)
+}
@@ -0,0 +1,119 @@
+package smoother
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherAdaptive(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	n := 400
+	y := make([]float64, n)
+	lambdas := make([]float64, n)
+	for i := range y {
+		// a sharp pulse in the middle of a flat noisy signal
+		if i >= 195 && i < 205 {
+			y[i] = 5
+		}
+		y[i] += rng.NormFloat64() * 0.1
+		lambdas[i] = 1e5
+		if i >= 185 && i < 215 {
+			lambdas[i] = 0.01
+		}
+	}
+
+	adaptive, err := WESmootherAdaptive(y, lambdas, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherAdaptive: %v", err)
+	}
+	uniform, err := WESmoother(y, 1e5, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+
+	if math.Abs(adaptive[200]-5) > 0.3 {
+		t.Errorf("pulse not preserved, got %f at its center", adaptive[200])
+	}
+	if uniform[200] > 2 {
+		t.Errorf("uniform smoothing was expected to flatten the pulse, got %f", uniform[200])
+	}
+	for i := 20; i < 150; i++ {
+		if math.Abs(adaptive[i]) > 0.1 {
+			t.Fatalf("index %d: flat region not smoothed, got %f", i, adaptive[i])
+		}
+	}
+
+	if _, err := WESmootherAdaptive(y, lambdas[1:], 2); err == nil {
+		t.Errorf("expected an error for mismatched lengths")
+	}
+	lambdas[10] = -1
+	var inputErr *InputError
+	if _, err := WESmootherAdaptive(y, lambdas, 2); !errors.As(err, &inputErr) || inputErr.Field != "lambda" {
+		t.Errorf("expected an *InputError for a negative lambda, got %v", err)
+	}
+}
+
+func TestWESmootherLambdaFunc(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	n := 300
+	x := make([]float64, n)
+	y := make([]float64, n)
+	for i := range y {
+		x[i] = float64(i) * 0.5
+		if x[i] >= 70 {
+			y[i] = 3
+		}
+		y[i] += rng.NormFloat64() * 0.1
+	}
+
+	// relax the smoothing around the step at x = 70
+	event := func(i int, x float64) float64 {
+		if math.Abs(x-70) < 3 {
+			return 0.01
+		}
+		return 1e5
+	}
+	z, err := WESmootherLambdaFunc(y, x, event, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherLambdaFunc: %v", err)
+	}
+
+	lambdas := make([]float64, n)
+	for i := range lambdas {
+		lambdas[i] = event(i, x[i])
+	}
+	want, err := WESmootherAdaptive(y, lambdas, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherAdaptive: %v", err)
+	}
+	for i := range z {
+		if z[i] != want[i] {
+			t.Fatalf("index %d: got %f, want %f", i, z[i], want[i])
+		}
+	}
+	if math.Abs(z[135]) > 0.3 || math.Abs(z[145]-3) > 0.3 {
+		t.Errorf("step not preserved: %f before, %f after", z[135], z[145])
+	}
+
+	var seen []float64
+	if _, err := WESmootherLambdaFunc(y[:5], nil, func(i int, x float64) float64 {
+		seen = append(seen, x)
+		return 1
+	}, 2); err != nil {
+		t.Fatalf("Failed to apply WESmootherLambdaFunc: %v", err)
+	}
+	for i, v := range seen {
+		if v != float64(i) {
+			t.Errorf("without x the position of sample %d should be its index, got %f", i, v)
+		}
+	}
+
+	if _, err := WESmootherLambdaFunc(y, x[1:], event, 2); err == nil {
+		t.Errorf("expected an error for mismatched lengths")
+	}
+	if _, err := WESmootherLambdaFunc(y, x, nil, 2); err == nil {
+		t.Errorf("expected an error for a nil lambda function")
+	}
+}
@@ -0,0 +1,129 @@
+// Package anomaly flags stretches of a series that deviate persistently from its Whittaker-Eilers smooth, the
+// usual way the smoother is put to work in monitoring: a single noisy sample is not an anomaly, a run of samples
+// that all sit far from the trend is.
+package anomaly
+
+import (
+	"fmt"
+	"math"
+
+	smoother "github.com/grutz/go-whittaker-eilers"
+	"github.com/grutz/go-whittaker-eilers/internal/robuststat"
+)
+
+// Default settings used for the zero fields of Config.
+const (
+	DefaultLambda    = 1e4
+	DefaultOrder     = 2
+	DefaultThreshold = 3
+	DefaultMinLength = 3
+)
+
+// Config controls Detect. Zero fields take the defaults above.
+type Config struct {
+	// Lambda is the smoothing parameter of the trend, larger values give a stiffer trend.
+	Lambda float64
+	// Order is the order of differences of the smoothing penalty.
+	Order int
+	// Threshold is the absolute residual z-score above which a sample deviates from the trend.
+	Threshold float64
+	// MinLength is the number of consecutive deviating samples that make up an anomaly.
+	MinLength int
+}
+
+// withDefaults returns c with its zero fields replaced by the defaults.
+func (c Config) withDefaults() Config {
+	if c.Lambda == 0 {
+		c.Lambda = DefaultLambda
+	}
+	if c.Order == 0 {
+		c.Order = DefaultOrder
+	}
+	if c.Threshold == 0 {
+		c.Threshold = DefaultThreshold
+	}
+	if c.MinLength == 0 {
+		c.MinLength = DefaultMinLength
+	}
+	return c
+}
+
+// Window is a run of samples whose residual z-scores all exceed the threshold.
+type Window struct {
+	// Start is the index of the first sample of the window and End the index after the last one.
+	Start, End int
+	// Peak is the z-score of largest magnitude in the window, negative for a dip below the trend.
+	Peak float64
+}
+
+// Result holds the trend fitted by Detect, the residual z-scores and the anomalous windows found.
+type Result struct {
+	Trend   []float64
+	ZScores []float64
+	// Sigma is the robust estimate of the standard deviation of the residuals.
+	Sigma   float64
+	Windows []Window
+}
+
+// Detect smooths y with a robust Whittaker-Eilers smoother, so the trend is not pulled into the anomalies it is
+// meant to reveal, and standardizes the residuals by their robust standard deviation, 1.4826 times the median
+// absolute deviation. Every run of at least MinLength consecutive samples with an absolute z-score above
+// Threshold is reported as a window.
+func Detect(y []float64, cfg Config) (*Result, error) {
+	cfg = cfg.withDefaults()
+	if !(cfg.Threshold > 0) {
+		return nil, fmt.Errorf("threshold %f must be positive", cfg.Threshold)
+	}
+	if cfg.MinLength < 1 {
+		return nil, fmt.Errorf("minimum window length %d must be positive", cfg.MinLength)
+	}
+
+	trend, _, err := smoother.WESmootherRobust(y, cfg.Lambda, cfg.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	residuals := make([]float64, len(y))
+	for i := range y {
+		residuals[i] = y[i] - trend[i]
+	}
+	sigma := robuststat.Scale(residuals)
+
+	z := make([]float64, len(y))
+	for i, r := range residuals {
+		switch {
+		case sigma > 0:
+			z[i] = r / sigma
+		case r != 0:
+			// every deviation is infinitely unlikely when the residuals are almost all exactly zero
+			z[i] = math.Copysign(math.Inf(1), r)
+		}
+	}
+
+	return &Result{Trend: trend, ZScores: z, Sigma: sigma, Windows: windows(z, cfg.Threshold, cfg.MinLength)}, nil
+}
+
+// windows returns the runs of at least minLength consecutive z-scores above threshold in absolute value.
+func windows(z []float64, threshold float64, minLength int) []Window {
+	var found []Window
+	start := -1
+	for i := 0; i <= len(z); i++ {
+		if i < len(z) && math.Abs(z[i]) > threshold {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= minLength {
+			w := Window{Start: start, End: i}
+			for _, v := range z[start:i] {
+				if math.Abs(v) > math.Abs(w.Peak) {
+					w.Peak = v
+				}
+			}
+			found = append(found, w)
+		}
+		start = -1
+	}
+	return found
+}
@@ -0,0 +1,53 @@
+package anomaly
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	n := 500
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/50) + rng.NormFloat64()*0.1
+	}
+	// a lone spike is noise, a sustained excursion is an anomaly
+	y[100] += 2
+	for i := 300; i < 310; i++ {
+		y[i] -= 1.5
+	}
+
+	res, err := Detect(y, Config{})
+	if err != nil {
+		t.Fatalf("Failed to apply Detect: %v", err)
+	}
+	if math.Abs(res.Sigma-0.1) > 0.03 {
+		t.Errorf("Sigma: got %f, want about 0.1", res.Sigma)
+	}
+	if len(res.Windows) != 1 {
+		t.Fatalf("got %d windows, want 1: %v", len(res.Windows), res.Windows)
+	}
+	w := res.Windows[0]
+	if w.Start < 298 || w.Start > 302 || w.End < 308 || w.End > 312 {
+		t.Errorf("window [%d, %d), want about [300, 310)", w.Start, w.End)
+	}
+	if w.Peak > -10 {
+		t.Errorf("Peak: got %f, want a large negative z-score", w.Peak)
+	}
+
+	if res, err = Detect(y, Config{MinLength: 1}); err != nil {
+		t.Fatalf("Failed to apply Detect: %v", err)
+	}
+	if len(res.Windows) < 2 || res.Windows[0].Start != 100 {
+		t.Errorf("expected the lone spike to be reported with a minimum length of 1, got %v", res.Windows)
+	}
+
+	if _, err := Detect(y, Config{Threshold: -1}); err == nil {
+		t.Errorf("expected an error for a negative threshold")
+	}
+	if _, err := Detect([]float64{1, 2}, Config{}); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
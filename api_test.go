@@ -0,0 +1,186 @@
+package smoother
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var updateAPI = flag.Bool("update-api", false, "record the current exported API of the public packages in testdata/api")
+
+// apiPackages lists the directories of the public packages covered by COMPATIBILITY.md, relative to this one. The
+// API of every package is recorded in testdata/api/<name>.txt, where the root package is named smoother.
+var apiPackages = map[string]string{
+	"smoother": ".",
+	"anomaly":  "anomaly",
+	"dataio":   "dataio",
+	"datasets": "datasets",
+	"peaks":    "peaks",
+}
+
+// exportedAPI returns one line per exported identifier of the package in dir: functions, methods, types with
+// their exported fields and interface methods, constants and variables.
+func exportedAPI(t *testing.T, dir string) []string {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse package: %v", err)
+	}
+
+	format := func(node any) string {
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, fset, node); err != nil {
+			t.Fatalf("Failed to print node: %v", err)
+		}
+		return strings.Join(strings.Fields(buf.String()), " ")
+	}
+
+	var lines []string
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			if !ast.FileExports(file) {
+				continue
+			}
+			for _, decl := range file.Decls {
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					sig := strings.TrimPrefix(format(decl.Type), "func")
+					if decl.Recv == nil {
+						lines = append(lines, "func "+decl.Name.Name+sig)
+						continue
+					}
+					recv := format(decl.Recv.List[0].Type)
+					if ast.IsExported(strings.TrimLeft(recv, "*")) {
+						lines = append(lines, "method ("+recv+") "+decl.Name.Name+sig)
+					}
+				case *ast.GenDecl:
+					for _, spec := range decl.Specs {
+						lines = append(lines, specAPI(spec, decl.Tok, format)...)
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// specAPI returns the API lines of a type, constant or variable spec.
+func specAPI(spec ast.Spec, tok token.Token, format func(any) string) []string {
+	var lines []string
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		name := spec.Name.Name
+		switch typ := spec.Type.(type) {
+		case *ast.StructType:
+			lines = append(lines, "type "+name+" struct")
+			for _, field := range typ.Fields.List {
+				for _, n := range field.Names {
+					lines = append(lines, "field "+name+"."+n.Name+" "+format(field.Type))
+				}
+				if len(field.Names) == 0 {
+					lines = append(lines, "embedded "+name+" "+format(field.Type))
+				}
+			}
+		case *ast.InterfaceType:
+			lines = append(lines, "type "+name+" interface")
+			for _, m := range typ.Methods.List {
+				for _, n := range m.Names {
+					lines = append(lines, "method "+name+"."+n.Name+strings.TrimPrefix(format(m.Type), "func"))
+				}
+			}
+		default:
+			lines = append(lines, "type "+name+" "+format(spec.Type))
+		}
+	case *ast.ValueSpec:
+		for i, n := range spec.Names {
+			line := tok.String() + " " + n.Name
+			if spec.Type != nil {
+				line += " " + format(spec.Type)
+			}
+			if tok == token.CONST && i < len(spec.Values) {
+				line += " = " + format(spec.Values[i])
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// TestAPICompatibility fails when an identifier recorded for a public package was removed or changed, which would
+// break downstream users. A change shows as the removal of its recorded line. New identifiers are compatible and
+// only logged; they are recorded with "go test -run TestAPICompatibility -update-api" when they are released.
+func TestAPICompatibility(t *testing.T) {
+	for name, dir := range apiPackages {
+		golden := filepath.Join("testdata", "api", name+".txt")
+		current := exportedAPI(t, dir)
+		if *updateAPI {
+			data := strings.Join(current, "\n") + "\n"
+			if err := os.WriteFile(golden, []byte(data), 0o644); err != nil {
+				t.Fatalf("Failed to update %s: %v", golden, err)
+			}
+			continue
+		}
+
+		data, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", golden, err)
+		}
+		recorded := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+		added, removed := apiChanges(recorded, current)
+		for _, line := range added {
+			t.Logf("%s: new API not recorded in %s yet: %s", name, golden, line)
+		}
+		for _, line := range removed {
+			t.Errorf("%s: incompatible change, API removed or changed: %s", name, line)
+		}
+	}
+}
+
+// apiChanges returns the lines of the current API that are not recorded and the recorded lines that are missing
+// from it, both sorted.
+func apiChanges(recorded, current []string) (added, removed []string) {
+	have := map[string]bool{}
+	for _, line := range current {
+		have[line] = true
+	}
+	was := map[string]bool{}
+	for _, line := range recorded {
+		was[line] = true
+		if !have[line] {
+			removed = append(removed, line)
+		}
+	}
+	for _, line := range current {
+		if !was[line] {
+			added = append(added, line)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func TestAPIChanges(t *testing.T) {
+	recorded := []string{"func A() int", "func B(x int)", "type C struct"}
+	current := []string{"func A() int", "func B(x int, opts ...Option)", "type C struct", "type D int"}
+	added, removed := apiChanges(recorded, current)
+	if want := []string{"func B(x int, opts ...Option)", "type D int"}; strings.Join(added, ";") != strings.Join(want, ";") {
+		t.Errorf("got added %v, want %v", added, want)
+	}
+	// a changed signature shows as the removal of its recorded line, which fails TestAPICompatibility
+	if want := []string{"func B(x int)"}; strings.Join(removed, ";") != strings.Join(want, ";") {
+		t.Errorf("got removed %v, want %v", removed, want)
+	}
+}
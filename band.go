@@ -0,0 +1,253 @@
+package smoother
+
+import (
+	"context"
+	"math"
+)
+
+// cancelCheckRows is the number of rows a factorization processes between checks of its context.
+const cancelCheckRows = 1 << 12
+
+// Float is a floating point type that series can be smoothed in, see WESmootherOf.
+type Float interface {
+	~float32 | ~float64
+}
+
+// symBandOf is a symmetric n x n band matrix with bw superdiagonals and elements of type T. Row i stores A(i, i)
+// through A(i, i+bw), so the matrix takes n*(bw+1) values instead of n*n.
+type symBandOf[T Float] struct {
+	n, bw int
+	data  []T
+}
+
+// symBand is the float64 symmetric band matrix used by most of the package.
+type symBand = symBandOf[float64]
+
+// newSymBand returns a zero symmetric band matrix.
+func newSymBand(n, bw int) *symBand {
+	return newSymBandOf[float64](n, bw)
+}
+
+// newSymBandOf returns a zero symmetric band matrix with elements of type T.
+func newSymBandOf[T Float](n, bw int) *symBandOf[T] {
+	return &symBandOf[T]{n: n, bw: bw, data: make([]T, n*(bw+1))}
+}
+
+// at returns A(i, j), which is zero outside the band.
+func (b *symBandOf[T]) at(i, j int) T {
+	if i > j {
+		i, j = j, i
+	}
+	if j-i > b.bw {
+		return 0
+	}
+	return b.data[i*(b.bw+1)+j-i]
+}
+
+// add adds v to A(i, j) and A(j, i), which must lie within the band.
+func (b *symBandOf[T]) add(i, j int, v T) {
+	if i > j {
+		i, j = j, i
+	}
+	b.data[i*(b.bw+1)+j-i] += v
+}
+
+// differencePenaltyBand returns D'D as a band matrix, where D is the difference matrix of order d for a series of
+// length n. Its bandwidth is d.
+func differencePenaltyBand(n, d int) *symBand {
+	return differencePenaltyBandOf[float64](n, d)
+}
+
+// differencePenaltyBandOf is differencePenaltyBand with elements of type T.
+func differencePenaltyBandOf[T Float](n, d int) *symBandOf[T] {
+	P := newSymBandOf[T](n, d)
+	addDifferencePenaltyBand(P)
+	return P
+}
+
+// addDifferencePenaltyBand adds D'D to the band matrix P, where D is the difference matrix whose order is the
+// bandwidth of P. D'D(i, i+k) sums c(i-r)·c(i+k-r) over the rows r of D covering both columns, which for the rows
+// d through n-1-d are all d+1 rows, so these rows take the same values and only the d rows at either end are
+// summed one by one. The band is assembled in a single pass over its storage.
+func addDifferencePenaltyBand[T Float](P *symBandOf[T]) {
+	n, d := P.n, P.bw
+	w := d + 1
+	coeffs := differenceCoefficients(d)
+	interior := make([]T, w)
+	for k := range interior {
+		var sum float64
+		for j := 0; j+k <= d; j++ {
+			sum += coeffs[j] * coeffs[j+k]
+		}
+		interior[k] = T(sum)
+	}
+	for i := 0; i < n; i++ {
+		row := P.data[i*w : i*w+w]
+		if i >= d && i <= n-1-d {
+			for k, v := range interior {
+				row[k] += v
+			}
+			continue
+		}
+		for k := 0; k < w && i+k < n; k++ {
+			var sum float64
+			for r := max(0, i+k-d); r <= min(i, n-1-d); r++ {
+				sum += coeffs[i-r] * coeffs[i+k-r]
+			}
+			row[k] += T(sum)
+		}
+	}
+}
+
+// bandCholeskyOf is the lower triangular Cholesky factor L of a symmetric positive definite band matrix with
+// elements of type T. Row i stores L(i, i-bw) through L(i, i), entries left of the first column being zero.
+type bandCholeskyOf[T Float] struct {
+	n, bw int
+	data  []T
+}
+
+// bandCholesky is the float64 band Cholesky factor used by most of the package.
+type bandCholesky = bandCholeskyOf[float64]
+
+// l returns L(i, j) for i-bw <= j <= i.
+func (c *bandCholeskyOf[T]) l(i, j int) T {
+	return c.data[i*(c.bw+1)+j-i+c.bw]
+}
+
+// factorizeBand computes the Cholesky factorization of the band matrix A in O(n*bw²). The factor is stored in
+// the element type of A, the sums are accumulated in float64 so a float32 factor only loses precision once.
+func factorizeBand[T Float](A *symBandOf[T]) (*bandCholeskyOf[T], error) {
+	return factorizeBandContext(context.Background(), A)
+}
+
+// factorizeBandContext is factorizeBand, returning the error of ctx if it is done before the factorization
+// completes.
+func factorizeBandContext[T Float](ctx context.Context, A *symBandOf[T]) (*bandCholeskyOf[T], error) {
+	c := &bandCholeskyOf[T]{n: A.n, bw: A.bw, data: make([]T, len(A.data))}
+	if err := factorizeBandInto(ctx, c, A); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// factorizeBandInto computes the Cholesky factorization of A into c, which must have the size and bandwidth of A
+// and zeros left of the first column, returning the error of ctx if it is done before the factorization
+// completes.
+func factorizeBandInto[T Float](ctx context.Context, c *bandCholeskyOf[T], A *symBandOf[T]) error {
+	n, bw := A.n, A.bw
+	w := bw + 1
+	for i := 0; i < n; i++ {
+		if i%cancelCheckRows == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		// li[k-i+bw] is L(i, k), lj[k-j+bw] is L(j, k) and A(i, j) is stored in row j at offset i-j
+		li := c.data[i*w : i*w+w]
+		for j := max(0, i-bw); j <= i; j++ {
+			lj := c.data[j*w : j*w+w]
+			sum := float64(A.data[j*w+i-j])
+			for k := max(0, i-bw); k < j; k++ {
+				sum -= float64(li[k-i+bw]) * float64(lj[k-j+bw])
+			}
+			if j < i {
+				li[j-i+bw] = T(sum / float64(lj[bw]))
+				continue
+			}
+			if !(sum > 0) {
+				return ErrNotPositiveDefinite
+			}
+			li[bw] = T(math.Sqrt(sum))
+		}
+	}
+	return nil
+}
+
+// solve returns x with A * x = b by forward and back substitution.
+func (c *bandCholeskyOf[T]) solve(b []T) []T {
+	x := make([]T, c.n)
+	c.solveTo(x, b)
+	return x
+}
+
+// solveTo sets x to the solution of A * x = b without allocating. x and b may be the same slice, since every
+// substitution step only reads the right hand side at the index it writes.
+func (c *bandCholeskyOf[T]) solveTo(x, b []T) {
+	n, bw := c.n, c.bw
+	w := bw + 1
+	for i := 0; i < n; i++ {
+		li := c.data[i*w : i*w+w]
+		sum := float64(b[i])
+		for k := max(0, i-bw); k < i; k++ {
+			sum -= float64(li[k-i+bw]) * float64(x[k])
+		}
+		x[i] = T(sum / float64(li[bw]))
+	}
+	// L(k, i) is stored in row k at offset i-k+bw
+	for i := n - 1; i >= 0; i-- {
+		sum := float64(x[i])
+		for k := i + 1; k <= min(n-1, i+bw); k++ {
+			sum -= float64(c.data[k*w+i-k+bw]) * float64(x[k])
+		}
+		x[i] = T(sum / float64(c.data[i*w+bw]))
+	}
+}
+
+// inverseDiagonal returns the diagonal of the inverse of A with the recursion of Takahashi, Fagan and Chin, which
+// only computes the entries of the inverse within the band: from L'Z = L⁻¹ it follows for j >= i that
+//
+//	Z(i, j) = (δ_ij / L(i, i) - Σ_{k=i+1}^{i+bw} L(k, i) Z(k, j)) / L(i, i)
+//
+// which only needs entries of later rows within the band. This takes O(n*bw²) instead of the O(n³) of a full
+// inverse. The entries are computed in float64 whatever the element type of the factor.
+func (c *bandCholeskyOf[T]) inverseDiagonal() []float64 {
+	n, bw := c.n, c.bw
+	z := &symBand{n: n, bw: bw, data: *getFloats(n * (bw + 1))}
+	defer putBand(z)
+	diag := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		lii := float64(c.l(i, i))
+		for j := min(n-1, i+bw); j >= i; j-- {
+			var sum float64
+			for k := i + 1; k <= min(n-1, i+bw); k++ {
+				sum += float64(c.l(k, i)) * z.at(k, j)
+			}
+			v := -sum / lii
+			if j == i {
+				v += 1 / (lii * lii)
+			}
+			z.add(i, j, v)
+		}
+		diag[i] = z.at(i, i)
+	}
+	return diag
+}
+
+// factorizeScaledBand computes the Cholesky factorization of I + lambda * P for the band penalty P.
+func factorizeScaledBand[T Float](P *symBandOf[T], lambda float64) (*bandCholeskyOf[T], error) {
+	return factorizeBand(scaledBand(P, lambda))
+}
+
+// scaledBand returns I + lambda * P for the band penalty P.
+func scaledBand[T Float](P *symBandOf[T], lambda float64) *symBandOf[T] {
+	A := newSymBandOf[T](P.n, P.bw)
+	for i, v := range P.data {
+		A.data[i] = T(lambda * float64(v))
+	}
+	for i := 0; i < A.n; i++ {
+		A.add(i, i, 1)
+	}
+	return A
+}
+
+// weightedBand returns W + lambda * P for the band penalty P, where W is the diagonal matrix of the weights w.
+func weightedBand(P *symBand, lambda float64, w []float64) *symBand {
+	A := newSymBand(P.n, P.bw)
+	for i, v := range P.data {
+		A.data[i] = lambda * v
+	}
+	for i, wi := range w {
+		A.add(i, i, wi)
+	}
+	return A
+}
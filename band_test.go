@@ -0,0 +1,118 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestBandCholesky(t *testing.T) {
+	n, d, lambda := 40, 3, 7.0
+	band := differencePenaltyBand(n, d)
+	dense := penaltyMatrix(n, lambda, d)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if math.Abs(lambda*band.at(i, j)-dense.At(i, j)) > 1e-12 {
+				t.Fatalf("penalty (%d, %d): band %f, dense %f", i, j, lambda*band.at(i, j), dense.At(i, j))
+			}
+		}
+	}
+
+	A := newSymBand(n, d)
+	for i := range A.data {
+		A.data[i] = lambda * band.data[i]
+	}
+	for i := 0; i < n; i++ {
+		A.add(i, i, 1)
+	}
+	chol, err := factorizeBand(A)
+	if err != nil {
+		t.Fatalf("Failed to factorize: %v", err)
+	}
+
+	full := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			full.Set(i, j, A.at(i, j))
+		}
+	}
+	b := make([]float64, n)
+	for i := range b {
+		b[i] = math.Sin(float64(i))
+	}
+	var want mat.VecDense
+	if err := want.SolveVec(full, mat.NewVecDense(n, b)); err != nil {
+		t.Fatalf("Failed to solve: %v", err)
+	}
+	var inv mat.Dense
+	if err := inv.Inverse(full); err != nil {
+		t.Fatalf("Failed to invert: %v", err)
+	}
+
+	x := chol.solve(b)
+	diag := chol.inverseDiagonal()
+	for i := 0; i < n; i++ {
+		if math.Abs(x[i]-want.AtVec(i)) > 1e-10 {
+			t.Errorf("solution %d: got %f, want %f", i, x[i], want.AtVec(i))
+		}
+		if math.Abs(diag[i]-inv.At(i, i)) > 1e-10 {
+			t.Errorf("inverse diagonal %d: got %f, want %f", i, diag[i], inv.At(i, i))
+		}
+	}
+
+	A.add(n/2, n/2, -1e3)
+	if _, err := factorizeBand(A); err == nil {
+		t.Errorf("expected an error for an indefinite matrix")
+	}
+}
+
+func TestDifferencePenaltyBand(t *testing.T) {
+	for _, d := range []int{1, 2, 3, 4} {
+		for _, n := range []int{d + 1, 2*d + 1, 3 * d, 20} {
+			P := differencePenaltyBand(n, d)
+			want := penaltyMatrix(n, 1, d)
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					if P.at(i, j) != want.At(i, j) {
+						t.Fatalf("order %d, length %d: D'D(%d, %d) is %f, want %f", d, n, i, j, P.at(i, j), want.At(i, j))
+					}
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkLargeSeries smooths full-day telemetry sized series of a million and ten million samples.
+func BenchmarkLargeSeries(b *testing.B) {
+	for _, n := range []int{1e6, 1e7} {
+		y := make([]float64, n)
+		for i := range y {
+			y[i] = math.Sin(float64(i)/1000) + 0.1*math.Sin(float64(i))
+		}
+		for _, d := range []int{1, 2, 3} {
+			b.Run(fmt.Sprintf("n=%d/d=%d", n, d), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := WESmoother(y, 1e4, d); err != nil {
+						b.Fatalf("Failed to smooth: %v", err)
+					}
+				}
+			})
+		}
+		b.Run(fmt.Sprintf("n=%d/Smoother", n), func(b *testing.B) {
+			s, err := NewSmoother(1e4, 2)
+			if err != nil {
+				b.Fatalf("Failed to create smoother: %v", err)
+			}
+			z := make([]float64, n)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := s.SmoothTo(z, y); err != nil {
+					b.Fatalf("Failed to smooth: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Band is a smoothed series together with its pointwise standard errors and confidence band.
+type Band struct {
+	Smooth []float64
+	StdErr []float64
+	Lower  []float64
+	Upper  []float64
+
+	// Level is the confidence level of Lower and Upper.
+	Level float64
+	// Sigma is the estimated standard deviation of the noise in the input.
+	Sigma float64
+}
+
+// WESmootherBand applies the Whittaker-Eilers smoothing function to y like WESmoother and returns the smooth with
+// a pointwise confidence band at the given level, such as 0.95, so plots can show its uncertainty.
+//
+// The standard errors are σ·√h, where h is the diagonal of the hat matrix (I + λD'D)⁻¹ and σ² is the residual
+// variance corrected by the effective degrees of freedom. This is the Bayesian interval of Wahba, which also
+// accounts for the bias of the smooth. The hat diagonal is computed from the band Cholesky factor in O(n·d²), so
+// bands of long series cost little more than their smooth.
+func WESmootherBand(y []float64, lambda float64, d int, level float64) (*Band, error) {
+	if err := checkLength(len(y), d); err != nil {
+		return nil, err
+	}
+	if err := checkLambda(lambda); err != nil {
+		return nil, err
+	}
+	if level <= 0 || level >= 1 {
+		return nil, fmt.Errorf("confidence level %f not in (0, 1)", level)
+	}
+
+	P := pooledPenaltyBand(len(y), d)
+	defer putBand(P)
+	z, h, err := bandSmooth(y, P, lambda)
+	if err != nil {
+		return nil, err
+	}
+
+	sigma2, _ := residualVariance(y, z, nil, h, nil)
+	return newBand(z, h, sigma2, level), nil
+}
+
+// newBand builds the confidence band of the smooth z at the given level from the diagonal inv of the inverse of
+// the penalized system and the noise variance sigma2.
+func newBand(z, inv []float64, sigma2, level float64) *Band {
+	q := normalQuantile(level)
+	b := &Band{
+		Smooth: z,
+		StdErr: make([]float64, len(z)),
+		Lower:  make([]float64, len(z)),
+		Upper:  make([]float64, len(z)),
+		Level:  level,
+		Sigma:  math.Sqrt(sigma2),
+	}
+	for i := range z {
+		b.StdErr[i] = math.Sqrt(sigma2 * inv[i])
+		b.Lower[i] = z[i] - q*b.StdErr[i]
+		b.Upper[i] = z[i] + q*b.StdErr[i]
+	}
+	return b
+}
+
+// residualVariance estimates the variance of the noise from the weighted residuals of the smooth z, corrected by
+// the effective degrees of freedom. inv is the diagonal of the inverse of the penalized system, so w_i·inv_i is
+// the hat diagonal. Pinned values have no residual and are left out. It returns the variance and the effective
+// degrees of freedom; the variance is zero when the fit has no residual degrees of freedom left.
+func residualVariance(y, z, w, inv []float64, pinned []bool) (sigma2, edf float64) {
+	var rss, count float64
+	for i := range y {
+		if pinned != nil && pinned[i] {
+			continue
+		}
+		wi := 1.0
+		if w != nil {
+			wi = w[i]
+		}
+		r := y[i] - z[i]
+		rss += wi * r * r
+		edf += wi * inv[i]
+		count += wi
+	}
+	if count <= edf {
+		return 0, edf
+	}
+	return rss / (count - edf), edf
+}
+
+// normalQuantile returns the two-sided standard normal quantile for a confidence level, 1.96 for 0.95.
+func normalQuantile(level float64) float64 {
+	return distuv.UnitNormal.Quantile(0.5 + level/2)
+}
@@ -0,0 +1,65 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherBand(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	n := 400
+	truth := make([]float64, n)
+	y := make([]float64, n)
+	for i := range y {
+		truth[i] = math.Sin(float64(i) / 40)
+		y[i] = truth[i] + rng.NormFloat64()*0.3
+	}
+
+	band, err := WESmootherBand(y, 1000, 2, 0.95)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherBand: %v", err)
+	}
+
+	if math.Abs(band.Sigma-0.3) > 0.05 {
+		t.Errorf("Sigma: got %f, want about 0.3", band.Sigma)
+	}
+
+	covered := 0
+	for i := range truth {
+		if band.Lower[i] > band.Smooth[i] || band.Upper[i] < band.Smooth[i] {
+			t.Fatalf("index %d: band [%f, %f] does not contain the smooth", i, band.Lower[i], band.Upper[i])
+		}
+		if band.Lower[i] <= truth[i] && truth[i] <= band.Upper[i] {
+			covered++
+		}
+	}
+	if coverage := float64(covered) / float64(n); coverage < 0.85 {
+		t.Errorf("band covers only %.0f%% of the true curve", coverage*100)
+	}
+
+	if _, err := WESmootherBand(y, 1000, 2, 1.5); err == nil {
+		t.Errorf("expected an error for an invalid confidence level")
+	}
+	if _, err := WESmootherBand([]float64{1, 2}, 1000, 2, 0.95); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+	if _, err := WESmootherBand(y, -1, 2, 0.95); err == nil {
+		t.Errorf("expected an error for a negative lambda")
+	}
+}
+
+func TestWESmootherBandLong(t *testing.T) {
+	n := 200000
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/1000) + 0.1*math.Sin(float64(i)*2.3)
+	}
+	band, err := WESmootherBand(y, 1e4, 2, 0.95)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherBand to %d values: %v", n, err)
+	}
+	if !(band.StdErr[n/2] > 0) || !(band.StdErr[0] > band.StdErr[n/2]) {
+		t.Errorf("got standard errors %g at the end and %g in the middle", band.StdErr[0], band.StdErr[n/2])
+	}
+}
@@ -0,0 +1,62 @@
+package smoother
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SmoothAll smooths every series of series with a Smoother configured by opts, spreading the series over the
+// given number of goroutines, for services smoothing many independent metrics at once. A worker count of zero or
+// less uses the workers set by SetParallelism, GOMAXPROCS by default. The smooths are in the order of series
+// whatever the number of workers.
+//
+// A series that fails does not stop the others: its smooth is nil and its error, wrapped with its index, is
+// joined into the returned error, so errors.Is and errors.As see through it. Once ctx is done no further series
+// are started, their smooths are nil and the error of ctx is joined in as well.
+func SmoothAll(ctx context.Context, series [][]float64, opts []Option, workers int) ([][]float64, error) {
+	s, err := New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = defaultWorkers()
+	}
+	workers = max(1, min(workers, len(series)))
+
+	// The Smoother is safe for concurrent use, so the workers share it and its cached factorization
+	results := make([][]float64, len(series))
+	errs := make([]error, len(series))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				z, err := s.Smooth(series[k])
+				if err != nil {
+					errs[k] = fmt.Errorf("series %d: %w", k, err)
+					continue
+				}
+				results[k] = z
+			}
+		}()
+	}
+dispatch:
+	for k := range series {
+		select {
+		case jobs <- k:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return results, errors.Join(errs...)
+}
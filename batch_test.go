@@ -0,0 +1,61 @@
+package smoother
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSmoothAll(t *testing.T) {
+	series := make([][]float64, 20)
+	for k := range series {
+		series[k] = make([]float64, 50+k)
+		for i := range series[k] {
+			series[k][i] = math.Sin(float64(i*(k+1)) / 30)
+		}
+	}
+	series[3] = []float64{1}
+	series[11][5] = math.NaN()
+
+	opts := []Option{WithLambda(20), WithOrder(2)}
+	for _, workers := range []int{0, 1, 4, 100} {
+		got, err := SmoothAll(context.Background(), series, opts, workers)
+		if !errors.Is(err, ErrSeriesTooShort) {
+			t.Errorf("workers %d: got %v, want it to match ErrSeriesTooShort", workers, err)
+		}
+		var inputErr *InputError
+		if !errors.As(err, &inputErr) {
+			t.Errorf("workers %d: got %v, want an *InputError", workers, err)
+		}
+		for k, y := range series {
+			if k == 3 || k == 11 {
+				if got[k] != nil {
+					t.Errorf("workers %d: expected no smooth for the failed series %d", workers, k)
+				}
+				continue
+			}
+			want, err := WESmoother(y, 20, 2)
+			if err != nil {
+				t.Fatalf("Failed to apply WESmoother: %v", err)
+			}
+			for i := range want {
+				if math.Abs(got[k][i]-want[i]) > 1e-9 {
+					t.Fatalf("workers %d, series %d, index %d: got %f, want %f", workers, k, i, got[k][i], want[i])
+				}
+			}
+		}
+	}
+
+	if _, err := SmoothAll(context.Background(), series, []Option{WithLambda(-1)}, 2); err == nil {
+		t.Errorf("expected an error for invalid options")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := SmoothAll(ctx, series, opts, 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if got, err := SmoothAll(context.Background(), nil, opts, 2); err != nil || len(got) != 0 {
+		t.Errorf("got %v and %v for no series", got, err)
+	}
+}
@@ -0,0 +1,65 @@
+package smoother
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// WESmootherBinomial smooths a series of success proportions by penalized logistic regression: the log odds of
+// success are penalized with the usual difference penalty and fitted by penalized iteratively reweighted least
+// squares. The result is the smooth probability of success, strictly inside (0, 1), and every sample is weighed by
+// its number of trials.
+//
+// successes holds the number of successes at every sample and trials the number of trials, at least as many.
+// When trials is nil, successes holds proportions in [0, 1] that are each treated as a single trial. Every
+// reweighting step is reported to the ProgressFunc set by WithProgress.
+func WESmootherBinomial(successes, trials []float64, lambda float64, d int, opts ...FitOption) ([]float64, error) {
+	if err := Validate(successes, lambda, d); err != nil {
+		return nil, err
+	}
+	if trials != nil && len(trials) != len(successes) {
+		return nil, fmt.Errorf("%w: trials has %d values, successes has %d", ErrLengthMismatch, len(trials), len(successes))
+	}
+	total := func(i int) float64 {
+		if trials == nil {
+			return 1
+		}
+		return trials[i]
+	}
+	for i, s := range successes {
+		n := total(i)
+		if !(n > 0) || math.IsInf(n, 0) {
+			return nil, fmt.Errorf("trials %f at index %d must be positive", n, i)
+		}
+		if s < 0 || s > n {
+			return nil, fmt.Errorf("successes %f at index %d not in [0, %f]", s, i, n)
+		}
+	}
+
+	eta, err := penalizedIRLS(context.Background(), successes, lambda, d, family{
+		start: func(i int, s float64) float64 {
+			n := total(i)
+			p := (s + 0.5) / (n + 1)
+			return math.Log(p / (1 - p))
+		},
+		working: func(i int, s, eta float64) (float64, float64) {
+			n := total(i)
+			p := logistic(eta)
+			v := n * p * (1 - p)
+			return eta + (s-n*p)/v, v
+		},
+	}, newProgressReporter(opts))
+	if err != nil {
+		return nil, err
+	}
+	for i := range eta {
+		eta[i] = logistic(eta[i])
+	}
+	return eta, nil
+}
+
+// logistic returns 1 / (1 + exp(-x)), the inverse of the log odds.
+func logistic(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
@@ -0,0 +1,62 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherBinomial(t *testing.T) {
+	rng := rand.New(rand.NewSource(17))
+	n := 300
+	prob := make([]float64, n)
+	successes := make([]float64, n)
+	trials := make([]float64, n)
+	for i := range prob {
+		// a success rate rising from almost never to almost always
+		prob[i] = logistic((float64(i) - 150) / 25)
+		trials[i] = float64(5 + rng.Intn(20))
+		for k := 0; k < int(trials[i]); k++ {
+			if rng.Float64() < prob[i] {
+				successes[i]++
+			}
+		}
+	}
+
+	p, err := WESmootherBinomial(successes, trials, 1000, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherBinomial: %v", err)
+	}
+	for i := range p {
+		if p[i] <= 0 || p[i] >= 1 {
+			t.Fatalf("index %d: probability %f outside (0, 1)", i, p[i])
+		}
+		if math.Abs(p[i]-prob[i]) > 0.07 {
+			t.Errorf("index %d: got %f, want about %f", i, p[i], prob[i])
+		}
+	}
+
+	// proportions alone, all at the edges of the valid range, still give probabilities inside it
+	props := make([]float64, n)
+	for i := range props {
+		if rng.Float64() < prob[i] {
+			props[i] = 1
+		}
+	}
+	if p, err = WESmootherBinomial(props, nil, 1000, 2); err != nil {
+		t.Fatalf("Failed to apply WESmootherBinomial: %v", err)
+	}
+	for i := range p {
+		if p[i] <= 0 || p[i] >= 1 {
+			t.Fatalf("index %d: probability %f outside (0, 1)", i, p[i])
+		}
+	}
+
+	successes[4] = trials[4] + 1
+	if _, err := WESmootherBinomial(successes, trials, 1000, 2); err == nil {
+		t.Errorf("expected an error for more successes than trials")
+	}
+	if _, err := WESmootherBinomial(successes, trials[1:], 1000, 2); err == nil {
+		t.Errorf("expected an error for mismatched lengths")
+	}
+}
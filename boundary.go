@@ -0,0 +1,59 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+)
+
+// Boundary selects how the smoother treats the ends of the series.
+type Boundary int
+
+const (
+	// BoundaryNatural applies the penalty to the series as is, the behaviour of WESmoother. The smooth near the
+	// ends is determined by fewer neighbours and follows the noise more closely there.
+	BoundaryNatural Boundary = iota
+	// BoundaryReflect pads the series with its mirror image at both ends, which suits signals that are
+	// symmetric about their end points or flat near them.
+	BoundaryReflect
+	// BoundaryRepeat pads the series by repeating its end values, which holds the smooth at the level of the
+	// last values instead of extrapolating their trend.
+	BoundaryRepeat
+)
+
+// boundaryPadWidths is the number of smoothing kernel widths, about lambda^(1/2d) samples each, by which
+// WESmootherBoundary pads the series.
+const boundaryPadWidths = 3
+
+// WESmootherBoundary applies the Whittaker-Eilers smoothing function to y like WESmoother, with the given
+// treatment of the ends of the series. For the padding modes the series is extended at both ends by about three
+// widths of the smoothing kernel, at most the length of the series minus one, smoothed with the solver of
+// CurrentSolver in O(n·d²) like WESmoother, and cropped back to its original length.
+func WESmootherBoundary(y []float64, lambda float64, d int, boundary Boundary) ([]float64, error) {
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, err
+	}
+	n := len(y)
+	if boundary == BoundaryNatural {
+		return WESmoother(y, lambda, d)
+	}
+	if boundary != BoundaryReflect && boundary != BoundaryRepeat {
+		return nil, fmt.Errorf("unknown boundary %d", boundary)
+	}
+
+	width := math.Pow(math.Max(lambda, 1), 1/float64(2*max(d, 1)))
+	pad := min(n-1, int(math.Ceil(boundaryPadWidths*width)))
+	padded := make([]float64, n+2*pad)
+	copy(padded[pad:], y)
+	for k := 1; k <= pad; k++ {
+		if boundary == BoundaryReflect {
+			padded[pad-k], padded[pad+n-1+k] = y[k], y[n-1-k]
+		} else {
+			padded[pad-k], padded[pad+n-1+k] = y[0], y[n-1]
+		}
+	}
+
+	if err := smoothTo(CurrentSolver(), padded, padded, lambda, d); err != nil {
+		return nil, err
+	}
+	return padded[pad : pad+n], nil
+}
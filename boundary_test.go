@@ -0,0 +1,111 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherBoundary(t *testing.T) {
+	rng := rand.New(rand.NewSource(28))
+	n := 60
+
+	// the natural boundary follows the noise more closely at the ends than padding does
+	endErr := map[Boundary]float64{}
+	for trial := 0; trial < 200; trial++ {
+		y := make([]float64, n)
+		for i := range y {
+			y[i] = rng.NormFloat64()
+		}
+		for _, b := range []Boundary{BoundaryNatural, BoundaryReflect, BoundaryRepeat} {
+			z, err := WESmootherBoundary(y, 10, 2, b)
+			if err != nil {
+				t.Fatalf("Failed to apply WESmootherBoundary: %v", err)
+			}
+			if len(z) != n {
+				t.Fatalf("boundary %d: got %d values, want %d", b, len(z), n)
+			}
+			endErr[b] += z[0]*z[0] + z[n-1]*z[n-1]
+		}
+	}
+	if endErr[BoundaryReflect] >= endErr[BoundaryNatural] || endErr[BoundaryRepeat] >= endErr[BoundaryNatural] {
+		t.Errorf("padding did not reduce the end noise: %v", endErr)
+	}
+
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i) / 8)
+	}
+	natural, err := WESmootherBoundary(y, 10, 2, BoundaryNatural)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherBoundary: %v", err)
+	}
+	want, err := WESmoother(y, 10, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	for i := range want {
+		if natural[i] != want[i] {
+			t.Fatalf("index %d: natural boundary %f, WESmoother %f", i, natural[i], want[i])
+		}
+	}
+
+	// repeating the end values holds a series that levels off at its ends
+	for i := range y {
+		y[i] = math.Tanh(float64(i-n/2) / 5)
+	}
+	repeat, err := WESmootherBoundary(y, 1e3, 2, BoundaryRepeat)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherBoundary: %v", err)
+	}
+	if natural, err = WESmootherBoundary(y, 1e3, 2, BoundaryNatural); err != nil {
+		t.Fatalf("Failed to apply WESmootherBoundary: %v", err)
+	}
+	if math.Abs(repeat[n-1]-y[n-1]) >= math.Abs(natural[n-1]-y[n-1]) {
+		t.Errorf("repeat boundary %f no closer to the end level %f than natural %f", repeat[n-1], y[n-1], natural[n-1])
+	}
+
+	if _, err := WESmootherBoundary(y, 10, 2, Boundary(5)); err == nil {
+		t.Errorf("expected an error for an unknown boundary")
+	}
+}
+
+func TestWESmootherBoundaryLong(t *testing.T) {
+	// the padded series matches the dense solve, which it no longer uses
+	n, lambda, d := 80, 100.0, 3
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/7) + 0.1*float64(i%3)
+	}
+	z, err := WESmootherBoundary(y, lambda, d, BoundaryReflect)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherBoundary: %v", err)
+	}
+	pad := min(n-1, int(math.Ceil(boundaryPadWidths*math.Pow(lambda, 1/float64(2*d)))))
+	padded := make([]float64, n+2*pad)
+	copy(padded[pad:], y)
+	for k := 1; k <= pad; k++ {
+		padded[pad-k], padded[pad+n-1+k] = y[k], y[n-1-k]
+	}
+	want, err := solvePenalized(padded, nil, penaltyMatrix(len(padded), lambda, d), nil)
+	if err != nil {
+		t.Fatalf("Failed to solve the padded series: %v", err)
+	}
+	for i := range z {
+		if math.Abs(z[i]-want[pad+i]) > 1e-9 {
+			t.Fatalf("index %d: got %g, want %g of the dense solve", i, z[i], want[pad+i])
+		}
+	}
+
+	// a dense system of this length would take 8 TB
+	long := make([]float64, 1_000_000)
+	for i := range long {
+		long[i] = math.Sin(float64(i) / 1000)
+	}
+	if z, err = WESmootherBoundary(long, 1e4, 2, BoundaryRepeat); err != nil {
+		t.Fatalf("Failed to smooth a long series: %v", err)
+	}
+	if len(z) != len(long) || math.Abs(z[len(z)/2]-long[len(long)/2]) > 1e-2 {
+		t.Errorf("unexpected smooth of a long series: %d values, %g at the middle", len(z), z[len(z)/2])
+	}
+}
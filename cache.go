@@ -0,0 +1,48 @@
+package smoother
+
+import "sync"
+
+// penaltyKey identifies the penalty D'D of a series length and order.
+type penaltyKey struct {
+	n, d int
+}
+
+// penaltyCache holds the penalties D'D of up to capacity series lengths and orders, evicting the oldest one when
+// full. The cached bands are shared by all callers, which must only read them.
+type penaltyCache struct {
+	mu       sync.Mutex
+	capacity int
+	bands    map[penaltyKey]*symBand
+	// keys holds the cached keys from the oldest to the newest.
+	keys []penaltyKey
+}
+
+// newPenaltyCache returns an empty cache for capacity penalties.
+func newPenaltyCache(capacity int) *penaltyCache {
+	return &penaltyCache{capacity: capacity, bands: make(map[penaltyKey]*symBand, capacity)}
+}
+
+// get returns D'D for a series of length n and order d, building and caching it if it is not cached yet.
+func (c *penaltyCache) get(n, d int) *symBand {
+	key := penaltyKey{n, d}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if P, ok := c.bands[key]; ok {
+		return P
+	}
+	if len(c.keys) == c.capacity {
+		delete(c.bands, c.keys[0])
+		c.keys = append(c.keys[:0], c.keys[1:]...)
+	}
+	P := differencePenaltyBand(n, d)
+	c.bands[key] = P
+	c.keys = append(c.keys, key)
+	return P
+}
+
+// len returns the number of cached penalties.
+func (c *penaltyCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.keys)
+}
@@ -0,0 +1,66 @@
+package smoother
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPenaltyCache(t *testing.T) {
+	c := newPenaltyCache(2)
+	a := c.get(10, 2)
+	if c.get(10, 2) != a {
+		t.Errorf("expected the cached penalty to be reused")
+	}
+	want := differencePenaltyBand(10, 2)
+	for i := range want.data {
+		if a.data[i] != want.data[i] {
+			t.Fatalf("cached penalty differs at %d", i)
+		}
+	}
+	c.get(12, 2)
+	c.get(10, 3)
+	if c.len() != 2 {
+		t.Errorf("got %d cached penalties, want 2", c.len())
+	}
+	if c.get(10, 2) == a {
+		t.Errorf("expected the oldest penalty to be evicted")
+	}
+}
+
+func TestSmootherPenaltyCache(t *testing.T) {
+	y := make([]float64, 40)
+	for i := range y {
+		y[i] = math.Sin(float64(i) / 4)
+	}
+	y[7] = math.NaN()
+	cached, err := New(WithLambda(5), WithNaNPolicy(NaNZeroWeight), WithPenaltyCache(1))
+	if err != nil {
+		t.Fatalf("Failed to create smoother: %v", err)
+	}
+	plain, err := New(WithLambda(5), WithNaNPolicy(NaNZeroWeight))
+	if err != nil {
+		t.Fatalf("Failed to create smoother: %v", err)
+	}
+	for _, n := range []int{40, 40, 30} {
+		got, err := cached.Smooth(y[:n])
+		if err != nil {
+			t.Fatalf("length %d: Failed to smooth: %v", n, err)
+		}
+		want, err := plain.Smooth(y[:n])
+		if err != nil {
+			t.Fatalf("length %d: Failed to smooth: %v", n, err)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("length %d, index %d: got %f, want %f", n, i, got[i], want[i])
+			}
+		}
+	}
+	if cached.cache.len() != 1 {
+		t.Errorf("got %d cached penalties, want 1", cached.cache.len())
+	}
+
+	if _, err := New(WithPenaltyCache(-1)); err == nil {
+		t.Errorf("expected an error for a negative cache size")
+	}
+}
@@ -0,0 +1,304 @@
+package smoother
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// monotoneKappa is the weight of the asymmetric penalty that pushes a calibration curve to be monotone, relative
+// to the smoothing parameter.
+const monotoneKappa = 1e6
+
+// maxMonotoneIterations caps the number of times the asymmetric penalty of a calibration curve is updated.
+const maxMonotoneIterations = 50
+
+// flatTolerance is the rise of a calibration curve segment, relative to the scale of the curve, at or below
+// which the segment is considered flat and cannot be inverted.
+const flatTolerance = 1e-9
+
+// calibrationConfig holds the settings of FitCalibrationCurve.
+type calibrationConfig struct {
+	lambda     float64
+	order      int
+	level      float64
+	decreasing bool
+	anchors    [][2]float64
+	progress   ProgressFunc
+}
+
+// CalibrationOption configures FitCalibrationCurve.
+type CalibrationOption func(*calibrationConfig)
+
+// WithCalibrationLambda sets the smoothing parameter of the calibration curve, 1 by default. The penalty uses
+// divided differences, so a suitable lambda depends on the units of x.
+func WithCalibrationLambda(lambda float64) CalibrationOption {
+	return func(c *calibrationConfig) { c.lambda = lambda }
+}
+
+// WithCalibrationOrder sets the order of differences of the calibration curve penalty, 2 by default.
+func WithCalibrationOrder(d int) CalibrationOption {
+	return func(c *calibrationConfig) { c.order = d }
+}
+
+// WithConfidenceLevel sets the confidence level of the uncertainty band, 0.95 by default.
+func WithConfidenceLevel(level float64) CalibrationOption {
+	return func(c *calibrationConfig) { c.level = level }
+}
+
+// WithDecreasing fits a monotone decreasing calibration curve instead of an increasing one.
+func WithDecreasing() CalibrationOption {
+	return func(c *calibrationConfig) { c.decreasing = true }
+}
+
+// WithAnchor adds a known point, such as a blank, that the calibration curve must pass through exactly. An anchor
+// at the same x as a measurement replaces the measured values there.
+func WithAnchor(x, y float64) CalibrationOption {
+	return func(c *calibrationConfig) { c.anchors = append(c.anchors, [2]float64{x, y}) }
+}
+
+// WithCalibrationProgress makes FitCalibrationCurve call fn after every update of the asymmetric penalty, with
+// the largest change of the fitted values, from the averaged measurements in the first update. An error of fn
+// aborts the fit like one of WithProgress.
+func WithCalibrationProgress(fn ProgressFunc) CalibrationOption {
+	return func(c *calibrationConfig) { c.progress = fn }
+}
+
+// CalibrationCurve is a smooth monotone function fitted to calibration measurements, evaluated between its knots
+// by linear interpolation and extrapolated linearly beyond them.
+type CalibrationCurve struct {
+	// X holds the distinct, increasing measurement and anchor positions.
+	X []float64
+	// Y holds the fitted curve at X.
+	Y []float64
+	// StdErr holds the pointwise standard error of the fit at X, zero at anchors.
+	StdErr []float64
+	// Sigma is the estimated standard deviation of a single measurement.
+	Sigma float64
+	// EffectiveDF is the trace of the hat matrix of the fit.
+	EffectiveDF float64
+	// Level is the confidence level of the band returned by Interval.
+	Level float64
+
+	quantile float64
+}
+
+// FitCalibrationCurve fits a smooth monotone calibration curve through the measurements y taken at x, using a
+// Whittaker-Eilers smoother with divided differences for the unevenly spaced x. Replicate measurements at the
+// same x are averaged and weighted by their count.
+//
+// Monotonicity is enforced with the asymmetric penalty described by Eilers: a large first difference penalty is
+// applied wherever the fit slopes the wrong way, and updated until the set of penalized differences no longer
+// changes. The uncertainty band is derived from the diagonal of the inverse of the penalized system and the
+// residual variance.
+func FitCalibrationCurve(x, y []float64, opts ...CalibrationOption) (*CalibrationCurve, error) {
+	cfg := calibrationConfig{lambda: 1, order: 2, level: 0.95}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if len(x) != len(y) {
+		return nil, fmt.Errorf("%w: x has %d values, y has %d", ErrLengthMismatch, len(x), len(y))
+	}
+	if err := checkLength(cfg.order+1, cfg.order); err != nil {
+		return nil, err
+	}
+	if err := checkLambda(cfg.lambda); err != nil {
+		return nil, err
+	}
+	if err := checkFinite(x); err != nil {
+		return nil, err
+	}
+	if err := checkFinite(y); err != nil {
+		return nil, err
+	}
+	if cfg.level <= 0 || cfg.level >= 1 {
+		return nil, fmt.Errorf("confidence level %f not in (0, 1)", cfg.level)
+	}
+
+	knots, values, weights, pinned := calibrationKnots(x, y, cfg.anchors)
+	m := len(knots)
+	if m <= cfg.order {
+		return nil, fmt.Errorf("calibration curve needs more than %d distinct x values, got %d", cfg.order, m)
+	}
+
+	D := dividedDifferenceMatrix(knots, cfg.order)
+	D1 := dividedDifferenceMatrix(knots, 1)
+	rows, _ := D.Dims()
+	lambdas := make([]float64, rows)
+	for i := range lambdas {
+		lambdas[i] = cfg.lambda
+	}
+	base := gramMatrix(D, lambdas)
+
+	// Update the asymmetric penalty until the set of wrongly sloped differences settles
+	// with a tolerance so rounding noise on flat stretches does not keep toggling the penalty
+	kappa := monotoneKappa * math.Max(cfg.lambda, 1)
+	var tol float64
+	for _, v := range values {
+		tol = math.Max(tol, flatTolerance*math.Abs(v))
+	}
+	v := make([]float64, m-1)
+	var sys *penalizedSystem
+	z := values
+	progress := newProgressReporter([]FitOption{WithProgress(cfg.progress)})
+	settled := false
+	for iter := 0; iter < maxMonotoneIterations && !settled; iter++ {
+		P := &mat.Dense{}
+		P.Add(base, gramMatrix(D1, v))
+
+		var err error
+		sys, err = factorizePenalized(weights, P, pinned)
+		if err != nil {
+			return nil, err
+		}
+		next, err := sys.solve(values)
+		if err != nil {
+			return nil, err
+		}
+		var change float64
+		for i := range next {
+			change = math.Max(change, math.Abs(next[i]-z[i]))
+		}
+		z = next
+		if err := progress.report(change); err != nil {
+			return nil, err
+		}
+
+		settled = true
+		for i := range v {
+			slope := z[i+1] - z[i]
+			wrong := (slope < -tol && !cfg.decreasing) || (slope > tol && cfg.decreasing)
+			next := 0.0
+			if wrong {
+				next = kappa
+			}
+			if next != v[i] {
+				v[i] = next
+				settled = false
+			}
+		}
+	}
+	if !settled {
+		return nil, fmt.Errorf("monotone penalty did not settle in %d iterations", maxMonotoneIterations)
+	}
+
+	// The remaining wrongly sloped differences are tiny, flatten them so the curve is monotone
+	for i := 1; i < m; i++ {
+		if (!cfg.decreasing && z[i] < z[i-1]) || (cfg.decreasing && z[i] > z[i-1]) {
+			z[i] = z[i-1]
+		}
+	}
+
+	inv, err := sys.inverseDiagonal()
+	if err != nil {
+		return nil, err
+	}
+
+	sigma2, edf := residualVariance(values, z, weights, inv, pinned)
+	sigma := math.Sqrt(sigma2)
+	se := make([]float64, m)
+	for i := range se {
+		se[i] = sigma * math.Sqrt(inv[i])
+	}
+
+	return &CalibrationCurve{
+		X:           knots,
+		Y:           z,
+		StdErr:      se,
+		Sigma:       sigma,
+		EffectiveDF: edf,
+		Level:       cfg.level,
+		quantile:    normalQuantile(cfg.level),
+	}, nil
+}
+
+// calibrationKnots sorts the measurements by x, averages replicates and merges in the anchors. It returns the
+// distinct positions, the value at each, its weight (replicate count) and whether it is an anchor.
+func calibrationKnots(x, y []float64, anchors [][2]float64) (knots, values, weights []float64, pinned []bool) {
+	type point struct {
+		x, y   float64
+		anchor bool
+	}
+	points := make([]point, 0, len(x)+len(anchors))
+	for i := range x {
+		points = append(points, point{x: x[i], y: y[i]})
+	}
+	for _, a := range anchors {
+		points = append(points, point{x: a[0], y: a[1], anchor: true})
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].x < points[j].x })
+
+	for _, p := range points {
+		last := len(knots) - 1
+		if last >= 0 && knots[last] == p.x {
+			switch {
+			case pinned[last]:
+				// anchors take precedence over measurements
+			case p.anchor:
+				values[last], weights[last], pinned[last] = p.y, 1, true
+			default:
+				values[last] = (values[last]*weights[last] + p.y) / (weights[last] + 1)
+				weights[last]++
+			}
+			continue
+		}
+		knots = append(knots, p.x)
+		values = append(values, p.y)
+		weights = append(weights, 1)
+		pinned = append(pinned, p.anchor)
+	}
+	return knots, values, weights, pinned
+}
+
+// segment returns the index i of the knot interval [X[i], X[i+1]] used to evaluate the curve at x and the
+// position t of x within it, which is outside of [0, 1] when extrapolating.
+func (c *CalibrationCurve) segment(x float64) (int, float64) {
+	i := sort.SearchFloat64s(c.X, x) - 1
+	i = max(0, min(i, len(c.X)-2))
+	return i, (x - c.X[i]) / (c.X[i+1] - c.X[i])
+}
+
+// Eval returns the value of the calibration curve at x.
+func (c *CalibrationCurve) Eval(x float64) float64 {
+	i, t := c.segment(x)
+	return c.Y[i] + t*(c.Y[i+1]-c.Y[i])
+}
+
+// Interval returns the lower and upper bound of the confidence band of the calibration curve at x.
+func (c *CalibrationCurve) Interval(x float64) (lower, upper float64) {
+	i, t := c.segment(x)
+	t = math.Max(0, math.Min(1, t))
+	se := c.StdErr[i] + t*(c.StdErr[i+1]-c.StdErr[i])
+	v := c.Eval(x)
+	return v - c.quantile*se, v + c.quantile*se
+}
+
+// Inverse returns the x at which the calibration curve takes the value y, which is how a measured signal is
+// converted back into a concentration. An error is returned if the curve is flat at y, that is if the segment
+// containing y rises by no more than a billionth of the scale of the curve.
+func (c *CalibrationCurve) Inverse(y float64) (float64, error) {
+	m := len(c.X)
+	scale := math.Abs(c.Y[m-1] - c.Y[0])
+	for _, v := range c.Y {
+		scale = math.Max(scale, math.Abs(v))
+	}
+	decreasing := c.Y[m-1] < c.Y[0]
+	below := func(v float64) bool {
+		if decreasing {
+			return v > y
+		}
+		return v < y
+	}
+
+	// Find the first knot at or beyond y, then interpolate within its segment
+	i := sort.Search(m, func(i int) bool { return !below(c.Y[i]) })
+	i = max(1, min(i, m-1))
+	dy := c.Y[i] - c.Y[i-1]
+	if math.Abs(dy) <= flatTolerance*scale {
+		return 0, errors.New("calibration curve is flat at the requested value")
+	}
+	return c.X[i-1] + (y-c.Y[i-1])/dy*(c.X[i]-c.X[i-1]), nil
+}
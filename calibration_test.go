@@ -0,0 +1,59 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFitCalibrationCurve(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	var x, y []float64
+	for i := 1; i <= 30; i++ {
+		// duplicate standards at every concentration, with noise that makes the raw data non-monotone
+		for r := 0; r < 2; r++ {
+			c := float64(i) * 0.5
+			x = append(x, c)
+			y = append(y, 2*c/(1+0.05*c)+rng.NormFloat64()*0.3)
+		}
+	}
+
+	curve, err := FitCalibrationCurve(x, y, WithCalibrationLambda(1), WithAnchor(0, 0))
+	if err != nil {
+		t.Fatalf("Failed to fit calibration curve: %v", err)
+	}
+
+	if curve.Eval(0) != 0 {
+		t.Errorf("anchor not preserved: got %f, want 0", curve.Eval(0))
+	}
+	for i := 1; i < len(curve.Y); i++ {
+		if curve.Y[i] < curve.Y[i-1] {
+			t.Fatalf("curve decreases between %f and %f", curve.X[i-1], curve.X[i])
+		}
+	}
+
+	lo, hi := curve.Interval(7.5)
+	if v := curve.Eval(7.5); !(lo < v && v < hi) {
+		t.Errorf("band [%f, %f] does not contain the fit %f", lo, hi, v)
+	}
+
+	c, err := curve.Inverse(curve.Eval(6.25))
+	if err != nil {
+		t.Fatalf("Failed to invert calibration curve: %v", err)
+	}
+	if math.Abs(c-6.25) > 1e-9 {
+		t.Errorf("Inverse: got %f, want 6.25", c)
+	}
+
+	flat, err := FitCalibrationCurve([]float64{1, 2, 3, 4}, []float64{1, 1, 1, 1})
+	if err != nil {
+		t.Fatalf("Failed to fit flat calibration curve: %v", err)
+	}
+	if _, err := flat.Inverse(1); err == nil {
+		t.Errorf("expected an error inverting a flat curve")
+	}
+
+	if _, err := FitCalibrationCurve(x, y[1:]); err == nil {
+		t.Errorf("expected an error for mismatched lengths")
+	}
+}
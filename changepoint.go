@@ -0,0 +1,80 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/grutz/go-whittaker-eilers/internal/robuststat"
+)
+
+// Changepoint is a sample at which the slope of the smooth changes markedly.
+type Changepoint struct {
+	// Index is the position of the change in the series.
+	Index int
+	// Score is the second difference of the smooth at Index in units of its robust spread over the series. It is
+	// positive where the slope increases and negative where it decreases.
+	Score float64
+}
+
+// Changepoints applies the Whittaker-Eilers smoothing function to y and returns the positions where the smooth
+// bends sharply, in order. The second difference of the smooth is standardized by 1.4826 times its median
+// absolute deviation, and every extreme whose standardized magnitude exceeds threshold is a changepoint. An
+// extreme must be the largest within the width of the smoothing kernel, about lambda^(1/2d) samples, so noise
+// does not split a single bend into several changepoints.
+//
+// A kink in the data, where the slope changes, shows up as a single changepoint. A jump in level shows up as a
+// pair of changepoints of opposite sign around the jump, where the smooth bends into and out of the step; a
+// larger lambda widens the pair.
+func Changepoints(y []float64, lambda float64, d int, threshold float64) ([]Changepoint, error) {
+	if !(threshold > 0) {
+		return nil, fmt.Errorf("threshold %f must be positive", threshold)
+	}
+	z, err := WESmoother(y, lambda, d)
+	if err != nil {
+		return nil, err
+	}
+	if len(z) < 3 {
+		return nil, fmt.Errorf("%w: series of length %d too short for changepoints", ErrSeriesTooShort, len(z))
+	}
+
+	curvature := make([]float64, len(z))
+	for i := 1; i < len(z)-1; i++ {
+		curvature[i] = z[i+1] - 2*z[i] + z[i-1]
+	}
+	inner := curvature[1 : len(z)-1]
+	scale := robuststat.Scale(inner)
+	if scale == 0 {
+		// the smooth is exactly straight almost everywhere, measure the bends against rounding noise instead
+		for _, c := range inner {
+			scale = math.Max(scale, math.Abs(c))
+		}
+		scale *= 1e-12
+		if scale == 0 {
+			return nil, nil
+		}
+	}
+
+	radius := max(1, int(math.Ceil(math.Pow(lambda, 1/float64(2*d)))))
+	var changes []Changepoint
+	for i := 1; i < len(z)-1; i++ {
+		if !strongestBend(curvature, i, radius) {
+			continue
+		}
+		if score := curvature[i] / scale; math.Abs(score) > threshold {
+			changes = append(changes, Changepoint{Index: i, Score: score})
+		}
+	}
+	return changes, nil
+}
+
+// strongestBend reports whether curvature[i] has the largest magnitude of curvature within radius of i, counting
+// the first of equal magnitudes.
+func strongestBend(curvature []float64, i, radius int) bool {
+	c := math.Abs(curvature[i])
+	for j := max(0, i-radius); j <= min(len(curvature)-1, i+radius); j++ {
+		if a := math.Abs(curvature[j]); a > c || (a == c && j < i) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestChangepoints(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	n := 450
+	y := make([]float64, n)
+	for i := range y {
+		// rising, flat from 150 and falling from 300
+		x := float64(i)
+		switch {
+		case i < 150:
+			y[i] = x / 50
+		case i < 300:
+			y[i] = 3
+		default:
+			y[i] = 3 - (x-300)/25
+		}
+		y[i] += rng.NormFloat64() * 0.05
+	}
+
+	changes, err := Changepoints(y, 1000, 2, 4)
+	if err != nil {
+		t.Fatalf("Failed to apply Changepoints: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("got %d changepoints, want 2: %v", len(changes), changes)
+	}
+	want := []struct {
+		index    int
+		positive bool
+	}{{150, false}, {300, false}}
+	for k, c := range changes {
+		if math.Abs(float64(c.Index-want[k].index)) > 5 {
+			t.Errorf("changepoint %d at %d, want about %d", k, c.Index, want[k].index)
+		}
+		if (c.Score > 0) != want[k].positive {
+			t.Errorf("changepoint %d: unexpected sign of score %f", k, c.Score)
+		}
+	}
+	if math.Abs(changes[1].Score) <= math.Abs(changes[0].Score) {
+		t.Errorf("the larger slope change at 300 should score higher: %v", changes)
+	}
+
+	if _, err := Changepoints(y, 1000, 2, 0); err == nil {
+		t.Errorf("expected an error for a zero threshold")
+	}
+	if _, err := Changepoints(y[:2], 1000, 2, 10); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
@@ -0,0 +1,79 @@
+package smoother
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// WESmootherChannels applies the Whittaker-Eilers smoothing function with the same lambda and order to every
+// channel of a multichannel recording, such as RGB traces or multi-electrode data, indexed by channel and then by
+// sample. The system is factorized once and the factorization reused for every channel.
+//
+// weights optionally holds per-sample weights for every channel, a zero weight marking a missing sample. It is
+// either nil, for unit weights everywhere, or holds one slice per channel, each nil or as long as the channel.
+// Channels with equal weights share a factorization, so only distinct weightings cost an extra one.
+func WESmootherChannels(channels [][]float64, lambda float64, d int, weights [][]float64) ([][]float64, error) {
+	if len(channels) == 0 {
+		return nil, errors.New("no channels given")
+	}
+	n := len(channels[0])
+	for c := range channels {
+		if len(channels[c]) != n {
+			return nil, fmt.Errorf("%w: channel %d has %d samples, want %d", ErrLengthMismatch, c, len(channels[c]), n)
+		}
+	}
+	if weights != nil && len(weights) != len(channels) {
+		return nil, fmt.Errorf("%w: weights given for %d channels, want %d", ErrLengthMismatch, len(weights), len(channels))
+	}
+	for c := range weights {
+		if weights[c] != nil && len(weights[c]) != n {
+			return nil, fmt.Errorf("%w: channel %d has %d weights, want %d", ErrLengthMismatch, c, len(weights[c]), n)
+		}
+		if err := checkWeights(weights[c]); err != nil {
+			return nil, fmt.Errorf("channel %d: %w", c, err)
+		}
+	}
+	if err := checkLength(n, d); err != nil {
+		return nil, err
+	}
+	if err := checkLambda(lambda); err != nil {
+		return nil, err
+	}
+	for c := range channels {
+		if err := checkFinite(channels[c]); err != nil {
+			return nil, fmt.Errorf("channel %d: %w", c, err)
+		}
+	}
+
+	P := penaltyMatrix(n, lambda, d)
+	var systems []*penalizedSystem
+	smooth := make([][]float64, len(channels))
+	for c := range channels {
+		var w []float64
+		if weights != nil {
+			w = weights[c]
+		}
+		var sys *penalizedSystem
+		for _, s := range systems {
+			if slices.Equal(s.w, w) {
+				sys = s
+				break
+			}
+		}
+		if sys == nil {
+			var err error
+			if sys, err = factorizePenalized(w, P, nil); err != nil {
+				return nil, fmt.Errorf("channel %d: %w", c, err)
+			}
+			systems = append(systems, sys)
+		}
+
+		z, err := sys.solve(channels[c])
+		if err != nil {
+			return nil, err
+		}
+		smooth[c] = z
+	}
+	return smooth, nil
+}
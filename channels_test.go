@@ -0,0 +1,76 @@
+package smoother
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherChannels(t *testing.T) {
+	rng := rand.New(rand.NewSource(20))
+	n := 200
+	channels := make([][]float64, 3)
+	for c := range channels {
+		channels[c] = make([]float64, n)
+		for i := range channels[c] {
+			channels[c][i] = math.Sin(float64(i)/30+float64(c)) + rng.NormFloat64()*0.1
+		}
+	}
+
+	smooth, err := WESmootherChannels(channels, 100, 2, nil)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherChannels: %v", err)
+	}
+	for c := range channels {
+		want, err := WESmoother(channels[c], 100, 2)
+		if err != nil {
+			t.Fatalf("Failed to apply WESmoother: %v", err)
+		}
+		for i := range want {
+			if math.Abs(smooth[c][i]-want[i]) > 1e-12 {
+				t.Fatalf("channel %d, index %d: got %f, want %f", c, i, smooth[c][i], want[i])
+			}
+		}
+	}
+
+	// a zero weighted dropout in one channel is bridged without affecting the others
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1
+	}
+	for i := 90; i < 110; i++ {
+		w[i] = 0
+		channels[1][i] = 100
+	}
+	if smooth, err = WESmootherChannels(channels, 100, 2, [][]float64{nil, w, nil}); err != nil {
+		t.Fatalf("Failed to apply WESmootherChannels: %v", err)
+	}
+	if math.Abs(smooth[1][100]-math.Sin(100.0/30+1)) > 0.1 {
+		t.Errorf("dropout not bridged, got %f", smooth[1][100])
+	}
+	if math.Abs(smooth[0][100]-math.Sin(100.0/30)) > 0.1 {
+		t.Errorf("unweighted channel changed, got %f", smooth[0][100])
+	}
+
+	if _, err := WESmootherChannels([][]float64{channels[0], channels[1][1:]}, 100, 2, nil); err == nil {
+		t.Errorf("expected an error for channels of different lengths")
+	}
+	if _, err := WESmootherChannels(channels, 100, 2, [][]float64{nil}); err == nil {
+		t.Errorf("expected an error for weights of the wrong number of channels")
+	}
+	if _, err := WESmootherChannels(nil, 100, 2, nil); err == nil {
+		t.Errorf("expected an error for no channels")
+	}
+	for _, bad := range []float64{-1, math.NaN(), math.Inf(1)} {
+		w := make([]float64, n)
+		for i := range w {
+			w[i] = 1
+		}
+		w[7] = bad
+		var inputErr *InputError
+		if _, err := WESmootherChannels(channels, 100, 2, [][]float64{nil, w, nil}); !errors.As(err, &inputErr) || inputErr.Field != "w" {
+			t.Errorf("got %v for the weight %g, want an *InputError about the weights", err, bad)
+		}
+	}
+}
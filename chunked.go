@@ -0,0 +1,101 @@
+package smoother
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// chunkOverlapWidths is the number of smoothing kernel widths, about lambda^(1/2d) samples each, that
+// WESmootherChunked overlaps neighbouring chunks by when no overlap is given.
+const chunkOverlapWidths = 20
+
+// WESmootherChunked applies the Whittaker-Eilers smoothing function to y in chunks of the given number of
+// samples, so that series with hundreds of millions of points can be smoothed with working memory proportional
+// to the chunk size rather than to the series. Every chunk is smoothed together with overlap samples on either
+// side, and neighbouring chunks are blended linearly over the overlap around their seam.
+//
+// The influence of a sample on the smooth decays exponentially with its distance in kernel widths, about
+// lambda^(1/2d) samples, so an overlap of many widths makes the seams indistinguishable from smoothing the series
+// at once. An overlap of zero or less picks 20 kernel widths.
+func WESmootherChunked(y []float64, lambda float64, d int, chunk, overlap int) ([]float64, error) {
+	z := make([]float64, len(y))
+	if err := smoothChunkedTo(z, y, lambda, d, chunk, overlap); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// smoothChunkedTo implements WESmootherChunked, writing the smooth into z, which must be as long as y and must not
+// overlap it. Only the chunks being blended are held in memory besides y and z, so both may be memory mapped.
+func smoothChunkedTo(z, y []float64, lambda float64, d int, chunk, overlap int) error {
+	if err := Validate(y, lambda, d); err != nil {
+		return err
+	}
+	if chunk < 1 {
+		return fmt.Errorf("chunk size %d must be positive", chunk)
+	}
+	if overlap <= 0 {
+		width := math.Pow(math.Max(lambda, 1), 1/float64(2*max(d, 1)))
+		overlap = int(math.Ceil(chunkOverlapWidths * width))
+	}
+	overlap = max(overlap, 2)
+	n := len(y)
+	if n <= chunk+2*overlap {
+		cur, err := chunkSmooth(y, nil, lambda, d)
+		if err != nil {
+			return err
+		}
+		copy(z, cur)
+		return nil
+	}
+
+	var P *symBand
+	var prev []float64
+	prevLo := 0
+	half := overlap / 2
+	for start := 0; start < n; start += chunk {
+		lo, hi := max(0, start-overlap), min(n, start+chunk+overlap)
+		// a penalty of the full window length is reused by every chunk but the ones at the ends
+		if P == nil || P.n != hi-lo {
+			P = differencePenaltyBand(hi-lo, d)
+		}
+		cur, err := chunkSmooth(y[lo:hi], P, lambda, d)
+		if err != nil {
+			return err
+		}
+
+		// Blend with the previous chunk in a window around the seam, then take over up to the next seam
+		from := start
+		if prev != nil {
+			from = min(n, start+half)
+			for i := start - half; i < from; i++ {
+				t := float64(i-(start-half)) / float64(2*half)
+				z[i] = (1-t)*prev[i-prevLo] + t*cur[i-lo]
+			}
+		}
+		end := min(n, start+chunk-half)
+		if start+chunk >= n {
+			end = n
+		}
+		for i := from; i < end; i++ {
+			z[i] = cur[i-lo]
+		}
+		prev, prevLo = cur, lo
+	}
+	return nil
+}
+
+// chunkSmooth smooths the chunk y with the band solver, using the band penalty P of its length when given.
+func chunkSmooth(y []float64, P *symBand, lambda float64, d int) ([]float64, error) {
+	if P == nil {
+		P = pooledPenaltyBand(len(y), d)
+		defer putBand(P)
+	}
+	chol, err := pooledFactor(context.Background(), P, lambda, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer putFactor(chol)
+	return chol.solve(y), nil
+}
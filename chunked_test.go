@@ -0,0 +1,60 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherChunked(t *testing.T) {
+	rng := rand.New(rand.NewSource(29))
+	n := 20000
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/300) + rng.NormFloat64()*0.2
+	}
+	const lambda = 1e4
+
+	// the band solver smooths the whole series at once for reference
+	want, _, err := bandSmooth(y, differencePenaltyBand(n, 2), lambda)
+	if err != nil {
+		t.Fatalf("Failed to smooth the whole series: %v", err)
+	}
+
+	for _, chunk := range []int{1000, 3333} {
+		z, err := WESmootherChunked(y, lambda, 2, chunk, 0)
+		if err != nil {
+			t.Fatalf("Failed to apply WESmootherChunked: %v", err)
+		}
+		for i := range want {
+			if math.Abs(z[i]-want[i]) > 1e-5 {
+				t.Fatalf("chunk %d, index %d: got %f, want %f", chunk, i, z[i], want[i])
+			}
+		}
+	}
+
+	// a short overlap leaves visible seams
+	z, err := WESmootherChunked(y, lambda, 2, 1000, 5)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherChunked: %v", err)
+	}
+	var seam float64
+	for i := range want {
+		seam = math.Max(seam, math.Abs(z[i]-want[i]))
+	}
+	if seam < 1e-4 {
+		t.Errorf("expected a short overlap to leave seams, largest deviation %g", seam)
+	}
+
+	short, err := WESmootherChunked(y[:100], lambda, 2, 1000, 0)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherChunked: %v", err)
+	}
+	if len(short) != 100 {
+		t.Errorf("got %d values for a single chunk, want 100", len(short))
+	}
+
+	if _, err := WESmootherChunked(y, lambda, 2, 0, 0); err == nil {
+		t.Errorf("expected an error for an empty chunk")
+	}
+}
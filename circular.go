@@ -0,0 +1,37 @@
+package smoother
+
+import (
+	"math"
+)
+
+// WESmootherAngles applies the Whittaker-Eilers smoothing function to angles in radians, such as wind directions
+// or phases, where smoothing the raw values would average 359° and 1° to 180°. The sine and cosine of the angles
+// are smoothed with one factorization and recombined with atan2, so wrap-around is handled correctly. The
+// result lies in [-π, π]; convert degrees to radians before and back after.
+func WESmootherAngles(theta []float64, lambda float64, d int) ([]float64, error) {
+	if err := Validate(theta, lambda, d); err != nil {
+		return nil, err
+	}
+	sin := make([]float64, len(theta))
+	cos := make([]float64, len(theta))
+	for i, a := range theta {
+		sin[i], cos[i] = math.Sincos(a)
+	}
+
+	sys, err := factorizePenalized(nil, penaltyMatrix(len(theta), lambda, d), nil)
+	if err != nil {
+		return nil, err
+	}
+	if sin, err = sys.solve(sin); err != nil {
+		return nil, err
+	}
+	if cos, err = sys.solve(cos); err != nil {
+		return nil, err
+	}
+
+	angles := make([]float64, len(theta))
+	for i := range angles {
+		angles[i] = math.Atan2(sin[i], cos[i])
+	}
+	return angles, nil
+}
@@ -0,0 +1,44 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherAngles(t *testing.T) {
+	rng := rand.New(rand.NewSource(18))
+	n := 300
+	truth := make([]float64, n)
+	theta := make([]float64, n)
+	for i := range theta {
+		// a direction slowly turning through north, where the raw angles wrap from π to -π
+		truth[i] = math.Pi - 1 + 2*float64(i)/float64(n)
+		a := truth[i] + rng.NormFloat64()*0.2
+		theta[i] = math.Atan2(math.Sin(a), math.Cos(a))
+	}
+
+	z, err := WESmootherAngles(theta, 1000, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherAngles: %v", err)
+	}
+	for i := 10; i < n-10; i++ {
+		diff := math.Remainder(z[i]-truth[i], 2*math.Pi)
+		if math.Abs(diff) > 0.15 {
+			t.Errorf("index %d: got %f, want about %f", i, z[i], truth[i])
+		}
+	}
+
+	// smoothing the raw angles averages across the wrap and points the wrong way
+	naive, err := WESmoother(theta, 1000, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	if diff := math.Remainder(naive[n/2]-truth[n/2], 2*math.Pi); math.Abs(diff) < 1 {
+		t.Errorf("expected smoothing the raw angles to fail at the wrap, got %f for %f", naive[n/2], truth[n/2])
+	}
+
+	if _, err := WESmootherAngles(theta[:2], 1000, 2); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
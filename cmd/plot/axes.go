@@ -0,0 +1,62 @@
+package main
+
+import (
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+)
+
+// styleAxes formats and labels the axes of p as cfg asks, with the y label and a log scale on the y axis only if
+// values is set, as for plots of the series and its smooths rather than of residuals or derivatives. It is called
+// once the lines of p are added, since the tick format of a time axis depends on the range the lines span and a log
+// scale needs it to be positive.
+func styleAxes(cfg *config, p *plot.Plot, values bool) {
+	if cfg.timeLayout != "" {
+		p.X.Label.Text = "Time (UTC)"
+		p.X.Tick.Marker = plot.TimeTicks{Format: timeTickFormat(p.X.Max - p.X.Min)}
+	}
+	if cfg.style.xLabel != "" {
+		p.X.Label.Text = cfg.style.xLabel
+	}
+	if values && cfg.style.yLabel != "" {
+		p.Y.Label.Text = cfg.style.yLabel
+	}
+	if cfg.logX && p.X.Min > 0 {
+		p.X.Scale = plot.LogScale{}
+		p.X.Tick.Marker = plot.LogTicks{Prec: -1}
+	}
+	if values && cfg.logY && p.Y.Min > 0 {
+		p.Y.Scale = plot.LogScale{}
+		p.Y.Tick.Marker = plot.LogTicks{Prec: -1}
+	}
+}
+
+// logPoints leaves out the points of pts that the log axes of cfg cannot show: those whose x is not positive with
+// -logx and, if values is set as for styleAxes, those whose y is not positive with -logy.
+func logPoints(cfg *config, pts plotter.XYs, values bool) plotter.XYs {
+	logY := values && cfg.logY
+	if !cfg.logX && !logY {
+		return pts
+	}
+	kept := make(plotter.XYs, 0, len(pts))
+	for _, pt := range pts {
+		if (cfg.logX && !(pt.X > 0)) || (logY && !(pt.Y > 0)) {
+			continue
+		}
+		kept = append(kept, pt)
+	}
+	return kept
+}
+
+// timeTickFormat returns the layout of the tick labels of a time axis spanning the given seconds, showing the
+// date for spans of days and the time of day with the precision shorter spans need.
+func timeTickFormat(span float64) string {
+	switch {
+	case span >= 3*86400:
+		return "2006-01-02"
+	case span >= 3*3600:
+		return "01-02 15:04"
+	case span >= 60:
+		return "15:04:05"
+	}
+	return "15:04:05.000"
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gonum.org/v1/plot"
+)
+
+func TestStyleAxes(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.csv")
+	data := "time,v\n2024-05-01T00:00:00Z,1\n2024-05-01T06:00:00Z,3\n2024-05-02T00:00:00Z,2\n2024-05-02T12:00:00Z,5\n"
+	if err := os.WriteFile(input, []byte(data), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg, err := parseFlags([]string{"-x", "time", "-time", "rfc3339", "-lambdas", "10", "-outdir", dir, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	s, err := loadSeries(input, cfg)
+	if err != nil || len(s.X) != 4 || s.X[1]-s.X[0] != 6*3600 {
+		t.Fatalf("got %v and %v reading the timestamps", s, err)
+	}
+
+	p := plot.New()
+	p.X.Min, p.X.Max = s.X[0], s.X[3]
+	styleAxes(cfg, p, true)
+	ticks, ok := p.X.Tick.Marker.(plot.TimeTicks)
+	if !ok || ticks.Format != "01-02 15:04" || p.X.Label.Text != "Time (UTC)" {
+		t.Errorf("got marker %#v and label %q for a time axis of a day and a half", p.X.Tick.Marker, p.X.Label.Text)
+	}
+	if got := timeTickFormat(10); got != "15:04:05.000" {
+		t.Errorf("got format %q for ten seconds", got)
+	}
+
+	if _, err := parseFlags([]string{"-time", "unix", "a.csv"}, io.Discard); err == nil {
+		t.Errorf("expected an error for -time without -x")
+	}
+}
+
+func TestLogAxes(t *testing.T) {
+	cfg := &config{logX: true, logY: true}
+	pts := logPoints(cfg, makePoints(nil, []float64{5, -1, 10, 0.5}), true)
+	if len(pts) != 2 || pts[0].X != 2 || pts[1].X != 3 {
+		t.Errorf("got %v, want the points with positive index and value", pts)
+	}
+	if pts := logPoints(cfg, makePoints([]float64{1, 2}, []float64{-1, 1}), false); len(pts) != 2 {
+		t.Errorf("got %v, want residuals kept whatever their sign", pts)
+	}
+
+	p := plot.New()
+	p.X.Min, p.X.Max, p.Y.Min, p.Y.Max = 1, 100, 0.1, 10
+	styleAxes(cfg, p, true)
+	if _, ok := p.Y.Scale.(plot.LogScale); !ok {
+		t.Errorf("got y scale %T, want a log scale", p.Y.Scale)
+	}
+	r := plot.New()
+	r.X.Min, r.X.Max, r.Y.Min, r.Y.Max = 1, 100, -1, 1
+	styleAxes(cfg, r, false)
+	if _, ok := r.X.Scale.(plot.LogScale); !ok {
+		t.Errorf("got x scale %T, want a log scale", r.X.Scale)
+	}
+	if _, ok := r.Y.Scale.(plot.LogScale); ok {
+		t.Errorf("residuals got a log scale")
+	}
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "spectrum.txt")
+	if err := os.WriteFile(input, []byte("1000\n300\n120\n40\n22\n9\n5\n2\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	args := []string{"-logx", "-logy", "-residuals", "-derivative", "1", "-gif", "-lambdas", "1", "-outdir", dir, input}
+	cfg, err := parseFlags(args, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	if _, err := parseFlags([]string{"-logx", "-x", "t", "-time", "unix", "a.csv"}, io.Discard); err == nil {
+		t.Errorf("expected an error for -logx with -time")
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// patternChars are the characters that make an input a glob pattern.
+const patternChars = "*?["
+
+// expandInputs replaces every directory among inputs by the files below it and every glob pattern by the files
+// it matches, both in lexical order. It also returns the directory of every file found, relative to the
+// directory or to the fixed leading part of the pattern, so the outputs can mirror the input tree. Other inputs,
+// such as single files, URLs and standard input, are kept as they are.
+func expandInputs(inputs []string) ([]string, map[string]string, error) {
+	var files []string
+	subdirs := make(map[string]string)
+	for _, input := range inputs {
+		if input == stdinName || isURL(input) {
+			files = append(files, input)
+			continue
+		}
+		info, err := os.Stat(input)
+		var found []string
+		var root string
+		switch {
+		case err == nil && info.IsDir():
+			root = input
+			if found, err = walkFiles(root, nil); err != nil {
+				return nil, nil, err
+			}
+			if len(found) == 0 {
+				return nil, nil, fmt.Errorf("directory %s holds no files", input)
+			}
+		case err != nil && strings.ContainsAny(input, patternChars):
+			var segments []string
+			if root, segments, err = splitPattern(input); err != nil {
+				return nil, nil, err
+			}
+			if found, err = walkFiles(root, segments); err != nil {
+				return nil, nil, err
+			}
+			if len(found) == 0 {
+				return nil, nil, fmt.Errorf("no files match %s", input)
+			}
+		default:
+			files = append(files, input)
+			continue
+		}
+		for _, name := range found {
+			rel, err := filepath.Rel(root, filepath.Dir(name))
+			if err != nil {
+				return nil, nil, err
+			}
+			if rel != "." {
+				subdirs[name] = rel
+			}
+		}
+		files = append(files, found...)
+	}
+	return files, subdirs, nil
+}
+
+// splitPattern splits a glob pattern into the directory of its leading segments without pattern characters and
+// its remaining segments.
+func splitPattern(pattern string) (string, []string, error) {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	fixed := 0
+	for fixed < len(segments)-1 && !strings.ContainsAny(segments[fixed], patternChars) {
+		fixed++
+	}
+	for _, seg := range segments[fixed:] {
+		if _, err := path.Match(seg, ""); err != nil {
+			return "", nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	root := strings.Join(segments[:fixed], "/")
+	switch {
+	case fixed == 0:
+		root = "."
+	case root == "":
+		root = "/"
+	}
+	return filepath.FromSlash(root), segments[fixed:], nil
+}
+
+// walkFiles returns the regular files below root whose path relative to it matches the pattern segments, or
+// every one that is not hidden when segments is nil.
+func walkFiles(root string, segments []string) ([]string, error) {
+	deep := false
+	for _, seg := range segments {
+		deep = deep || seg == "**"
+	}
+	var files []string
+	err := filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == root {
+			return nil
+		}
+		if segments == nil && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return err
+		}
+		depth := strings.Count(filepath.ToSlash(rel), "/") + 1
+		if d.IsDir() && segments != nil && depth >= len(segments) && !deep {
+			// no file below can have as few segments as the pattern
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if segments == nil || matchSegments(segments, strings.Split(filepath.ToSlash(rel), "/")) {
+			files = append(files, name)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// matchSegments reports whether the path segments of name match the pattern segments, where the segment **
+// matches any number of path segments and every other one a single segment as path.Match does.
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && matchSegments(pattern[1:], name[1:])
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandInputs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"runs/a.dat", "runs/b.csv", "runs/day1/c.dat", "runs/day1/deep/d.dat", "runs/.hidden/e.dat", "runs/.f.dat"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("1\n2\n3\n4\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runs := filepath.Join(dir, "runs")
+	tests := []struct {
+		input string
+		files []string
+	}{
+		{runs, []string{"a.dat", "b.csv", "day1/c.dat", "day1/deep/d.dat"}},
+		{filepath.Join(runs, "*.dat"), []string{".f.dat", "a.dat"}},
+		{filepath.Join(runs, "**", "*.dat"), []string{".f.dat", ".hidden/e.dat", "a.dat", "day1/c.dat", "day1/deep/d.dat"}},
+		{filepath.Join(runs, "day*", "*.dat"), []string{"day1/c.dat"}},
+		{filepath.Join(runs, "a.dat"), []string{"a.dat"}},
+	}
+	for _, tt := range tests {
+		files, subdirs, err := expandInputs([]string{tt.input, stdinName})
+		if err != nil {
+			t.Fatalf("%s: Failed to expand: %v", tt.input, err)
+		}
+		var got []string
+		for _, f := range files[:len(files)-1] {
+			rel, _ := filepath.Rel(runs, f)
+			got = append(got, filepath.ToSlash(rel))
+			if want := filepath.Dir(rel); subdirs[f] != want && !(want == "." && subdirs[f] == "") {
+				t.Errorf("%s: %s is in %q, want %q", tt.input, f, subdirs[f], want)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.files) || files[len(files)-1] != stdinName {
+			t.Errorf("%s: got %v, want %v", tt.input, got, tt.files)
+		}
+	}
+
+	for _, input := range []string{filepath.Join(runs, "*.txt"), filepath.Join(runs, "[a.dat"), filepath.Join(dir, "missing", "*.dat")} {
+		if _, _, err := expandInputs([]string{input}); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
+
+func TestDoBatch(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/x.dat", "b/x.dat"} {
+		path := filepath.Join(dir, "in", filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("1\n3\n2\n5\n4\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(dir, "out")
+	cfg, err := parseFlags([]string{"-lambdas", "10", "-outdir", out, filepath.Join(dir, "in", "**", "*.dat")}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if len(cfg.inputs) != 2 {
+		t.Fatalf("got inputs %v", cfg.inputs)
+	}
+	for _, input := range cfg.inputs {
+		if err := do(input, cfg); err != nil {
+			t.Fatalf("Failed to plot: %v", err)
+		}
+	}
+	for _, name := range []string{"a/x.dat-lambda-10.png", "b/x.dat-combined.png"} {
+		if _, err := os.Stat(filepath.Join(out, filepath.FromSlash(name))); err != nil {
+			t.Errorf("missing mirrored output %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/grutz/go-whittaker-eilers/dataio"
+	"gonum.org/v1/plot"
+)
+
+// plotColumns plots the columns of a csv input, named basename, together with their smooths, one plot per lambda
+// written to outDir as <basename>-columns-lambda-<lambda>.<format>. With -auto-lambda every column has its own
+// lambda, so the single plot is written as <basename>-columns.<format> instead. It returns the names of the plots.
+func plotColumns(basename, outDir string, names []string, series []*dataio.Series, smooths [][]dataio.Smooth, cfg *config) ([]string, error) {
+	var plots []string
+	for k := range smooths[0] {
+		var lines []interface{}
+		lambdas := make([]string, len(series))
+		for i, s := range series {
+			lambdas[i] = formatLambda(smooths[i][k].Lambda)
+			lines = append(lines,
+				names[i], logPoints(cfg, makePoints(s.X, s.Y), true),
+				names[i]+" lambda "+lambdas[i], logPoints(cfg, makePoints(s.X, smooths[i][k].Z), true),
+			)
+		}
+
+		p := plot.New()
+		title := fmt.Sprintf("%s: Columns vs. %s Lambda", basename, lambdas[0])
+		name := fmt.Sprintf("%s-columns-lambda-%s.%s", basename, lambdas[0], cfg.format)
+		lambda := lambdas[0]
+		if cfg.autoLambda {
+			title = fmt.Sprintf("%s: Columns vs Clean", basename)
+			name = fmt.Sprintf("%s-columns.%s", basename, cfg.format)
+			lambda = strings.Join(lambdas, ", ")
+		}
+		p.Title.Text = plotTitle(&cfg.style, title, basename, lambda)
+		p.X.Label.Text = "X"
+		p.Y.Label.Text = "Y"
+		if err := addLines(p, &cfg.style, lines...); err != nil {
+			return nil, err
+		}
+		styleAxes(cfg, p, true)
+		name = filepath.Join(outDir, name)
+		if err := savePlots(name, cfg, p); err != nil {
+			return nil, err
+		}
+		plots = append(plots, name)
+	}
+	return plots, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDoColumns(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "channels.csv")
+	data := "t,a,label,b\n0,1,x,2\n1,3,y,1\n2,2,z,4\n3,5,x,3\n4,4,y,5\n5,6,z,4\n"
+	if err := os.WriteFile(input, []byte(data), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg, err := parseFlags([]string{"-columns", "all", "-x", "t", "-json", "-lambdas", "1,10", "-outdir", dir, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	for _, name := range []string{
+		"channels.csv-a-lambda-1.png", "channels.csv-a-combined.png", "channels.csv-a.json",
+		"channels.csv-b-lambda-10.png", "channels.csv-b-combined.png", "channels.csv-b.json",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("missing output %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "channels.csv-label-combined.png")); err == nil {
+		t.Errorf("plotted the column that is not numeric")
+	}
+
+	combined := filepath.Join(dir, "combined")
+	cfg, err = parseFlags([]string{"-columns", "b, a", "-combine-columns", "-lambdas", "1,10", "-outdir", combined, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := os.Mkdir(combined, 0o755); err != nil {
+		t.Fatalf("Failed to make the output directory: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	entries, err := os.ReadDir(combined)
+	if err != nil {
+		t.Fatalf("Failed to list the outputs: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if len(names) != 2 || names[0] != "channels.csv-columns-lambda-1.png" || names[1] != "channels.csv-columns-lambda-10.png" {
+		t.Errorf("got outputs %v, want one combined plot per lambda", names)
+	}
+
+	cfg.columns = []string{"label"}
+	if err := do(input, cfg); err == nil {
+		t.Errorf("expected an error for a column that is not numeric")
+	}
+	cfg.columns, cfg.inputFormat = []string{}, "lines"
+	if err := do(input, cfg); err == nil {
+		t.Errorf("expected an error for columns of an input that is not csv")
+	}
+
+	for _, args := range [][]string{
+		{"-columns", "a", "-y", "b", input},
+		{"-columns", "a", "-out", "-", input},
+		{"-combine-columns", input},
+	} {
+		if _, err := parseFlags(args, io.Discard); err == nil {
+			t.Errorf("expected an error for %q", args)
+		}
+	}
+}
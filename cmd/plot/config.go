@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configInputs is the setting of a config file listing its inputs, which has no flag.
+const configInputs = "inputs"
+
+// exclusiveFlags maps every flag to the one it excludes, so a config file does not set one of them when the
+// command line sets the other.
+var exclusiveFlags = map[string]string{"lambdas": "lambda-range", "lambda-range": "lambdas"}
+
+// loadConfigFile applies the settings of the YAML or TOML config file name to flags, except those of flags the
+// command line already set, and returns the inputs it lists.
+func loadConfigFile(name string, flags *flag.FlagSet) ([]string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var settings map[string]any
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &settings)
+	case ".toml":
+		err = toml.Unmarshal(data, &settings)
+	default:
+		return nil, fmt.Errorf("config file %s: unknown format %q, want .yaml, .yml or .toml", name, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", name, err)
+	}
+
+	explicit := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var inputs []string
+	for _, key := range keys {
+		values, err := configValues(settings[key])
+		if err != nil {
+			return nil, fmt.Errorf("config file %s: setting %q: %w", name, key, err)
+		}
+		if key == configInputs {
+			inputs = values
+			continue
+		}
+		f := flags.Lookup(key)
+		if f == nil || key == "config" {
+			return nil, fmt.Errorf("config file %s: unknown setting %q", name, key)
+		}
+		if explicit[key] || explicit[exclusiveFlags[key]] {
+			continue
+		}
+		// repeatable flags take every value on its own, the others a comma separated list
+		if _, ok := f.Value.(headerList); !ok {
+			values = []string{strings.Join(values, ",")}
+		}
+		for _, v := range values {
+			if err := flags.Set(key, v); err != nil {
+				return nil, fmt.Errorf("config file %s: setting %q: %w", name, key, err)
+			}
+		}
+	}
+	return inputs, nil
+}
+
+// configValues returns the value of a setting of a config file as the flag values it stands for: a scalar as
+// one, a list as one per element and a table as "key: value" per entry, like the headers of -header.
+func configValues(v any) ([]string, error) {
+	switch v := v.(type) {
+	case []any:
+		var values []string
+		for _, e := range v {
+			s, err := configScalar(e)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]string, len(keys))
+		for i, key := range keys {
+			s, err := configScalar(v[key])
+			if err != nil {
+				return nil, err
+			}
+			values[i] = key + ": " + s
+		}
+		return values, nil
+	}
+	s, err := configScalar(v)
+	if err != nil {
+		return nil, err
+	}
+	return []string{s}, nil
+}
+
+// configScalar returns a string, boolean or number of a config file as a flag value.
+func configScalar(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case nil:
+		return "", errors.New("no value")
+	}
+	return "", fmt.Errorf("unsupported value %v", v)
+}
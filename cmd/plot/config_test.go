@@ -0,0 +1,62 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFlagsConfig(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"run.yaml": "inputs: [a.csv, b.csv]\nlambdas: [10, 1e3]\norder: 3\ny: temperature\nweights: quality\n" +
+			"residuals: true\nheader:\n  Authorization: Bearer token\n",
+		"run.toml": "inputs = [\"a.csv\", \"b.csv\"]\nlambdas = [10, 1e3]\norder = 3\ny = \"temperature\"\n" +
+			"weights = \"quality\"\nresiduals = true\n[header]\nAuthorization = \"Bearer token\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		cfg, err := parseFlags([]string{"-config", path}, io.Discard)
+		if err != nil {
+			t.Fatalf("%s: Failed to parse flags: %v", name, err)
+		}
+		if len(cfg.inputs) != 2 || cfg.inputs[1] != "b.csv" || len(cfg.lambdas) != 2 || cfg.lambdas[1] != 1000 ||
+			cfg.order != 3 || cfg.yColumn != "temperature" || cfg.wColumn != "quality" || !cfg.residuals ||
+			cfg.headers.Get("Authorization") != "Bearer token" {
+			t.Errorf("%s: unexpected config %+v", name, cfg)
+		}
+
+		// the command line overrides the file, and its inputs replace those of the file
+		cfg, err = parseFlags([]string{"-config", path, "-order", "1", "-lambda-range", "1:100:3", "c.csv"}, io.Discard)
+		if err != nil {
+			t.Fatalf("%s: Failed to parse flags: %v", name, err)
+		}
+		if len(cfg.inputs) != 1 || cfg.order != 1 || len(cfg.lambdas) != 3 || cfg.lambdas[1] != 10 {
+			t.Errorf("%s: unexpected overridden config %+v", name, cfg)
+		}
+	}
+
+	failures := map[string]string{
+		"unknown.yaml": "lambda: 10\ninputs: [a.csv]\n",
+		"nested.yaml":  "inputs: [[a.csv]]\n",
+		"invalid.yaml": "order: two\ninputs: [a.csv]\n",
+		"broken.toml":  "order = \n",
+		"run.ini":      "order = 2\n",
+	}
+	for name, content := range failures {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		if _, err := parseFlags([]string{"-config", path}, io.Discard); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := parseFlags([]string{"-config", filepath.Join(dir, "missing.yaml")}, io.Discard); err == nil {
+		t.Errorf("expected an error for a missing config file")
+	}
+}
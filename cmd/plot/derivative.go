@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grutz/go-whittaker-eilers/dataio"
+	"gonum.org/v1/plot"
+)
+
+// derivative returns the derivative of the given order of the smooth z at the positions x, or at the indices of
+// z when x is nil. Every order takes three point differences for uneven spacing, central ones inside the series
+// and one-sided ones at its ends, which are exact for quadratics and match smoother.Derivative for even spacing.
+func derivative(x, z []float64, order int) ([]float64, error) {
+	if len(z) < 3 {
+		return nil, errors.New("a derivative needs at least 3 values")
+	}
+	at := func(i int) float64 {
+		if x == nil {
+			return float64(i)
+		}
+		return x[i]
+	}
+	for i := 1; i < len(z); i++ {
+		if !(at(i) > at(i-1)) {
+			return nil, fmt.Errorf("positions must increase for a derivative, position %d is %g after %g", i, at(i), at(i-1))
+		}
+	}
+
+	m := len(z)
+	for k := 0; k < order; k++ {
+		g := make([]float64, m)
+		for i := 1; i < m-1; i++ {
+			h0, h1 := at(i)-at(i-1), at(i+1)-at(i)
+			g[i] = (h0*h0*z[i+1] - h1*h1*z[i-1] + (h1*h1-h0*h0)*z[i]) / (h0 * h1 * (h0 + h1))
+		}
+		h0, h1 := at(1)-at(0), at(2)-at(1)
+		g[0] = -(2*h0+h1)/(h0*(h0+h1))*z[0] + (h0+h1)/(h0*h1)*z[1] - h0/(h1*(h0+h1))*z[2]
+		h0, h1 = at(m-2)-at(m-3), at(m-1)-at(m-2)
+		g[m-1] = (2*h1+h0)/(h1*(h0+h1))*z[m-1] - (h0+h1)/(h0*h1)*z[m-2] + h1/(h0*(h0+h1))*z[m-3]
+		z = g
+	}
+	return z, nil
+}
+
+// derivativePlot returns a plot of the derivative of the given order of the smooth z of s, styled as cfg asks.
+func derivativePlot(s *dataio.Series, z []float64, order int, cfg *config) (*plot.Plot, error) {
+	g, err := derivative(s.X, z, order)
+	if err != nil {
+		return nil, err
+	}
+	p := plot.New()
+	p.Title.Text = "First derivative"
+	if order == 2 {
+		p.Title.Text = "Second derivative"
+	}
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "dY/dX"
+	if order == 2 {
+		p.Y.Label.Text = "d²Y/dX²"
+	}
+	if err := addLines(p, &cfg.style, "Derivative", logPoints(cfg, makePoints(s.X, g), false)); err != nil {
+		return nil, err
+	}
+	styleAxes(cfg, p, false)
+	return p, nil
+}
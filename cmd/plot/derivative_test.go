@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	smoother "github.com/grutz/go-whittaker-eilers"
+)
+
+func TestDerivative(t *testing.T) {
+	// three point differences are exact for a quadratic, also for uneven spacing
+	x := []float64{0, 0.5, 2, 2.25, 4, 7}
+	z := make([]float64, len(x))
+	for i, v := range x {
+		z[i] = 3*v*v - 2*v + 1
+	}
+	first, err := derivative(x, z, 1)
+	if err != nil {
+		t.Fatalf("Failed to differentiate: %v", err)
+	}
+	second, err := derivative(x, z, 2)
+	if err != nil {
+		t.Fatalf("Failed to differentiate twice: %v", err)
+	}
+	for i, v := range x {
+		if math.Abs(first[i]-(6*v-2)) > 1e-9 {
+			t.Errorf("index %d: first derivative %f, want %f", i, first[i], 6*v-2)
+		}
+		if math.Abs(second[i]-6) > 1e-9 {
+			t.Errorf("index %d: second derivative %f, want 6", i, second[i])
+		}
+	}
+
+	// for even spacing it matches the derivative of the library
+	y := []float64{1, 3, 2, 5, 4, 6, 5, 8}
+	clean, err := smoother.WESmoother(y, 10, 2)
+	if err != nil {
+		t.Fatalf("Failed to smooth: %v", err)
+	}
+	want, err := smoother.DerivativeN(y, 10, 2, 2, 1)
+	if err != nil {
+		t.Fatalf("Failed to apply DerivativeN: %v", err)
+	}
+	got, err := derivative(nil, clean, 2)
+	if err != nil {
+		t.Fatalf("Failed to differentiate: %v", err)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Errorf("index %d: %f, want %f", i, got[i], want[i])
+		}
+	}
+
+	if _, err := derivative(nil, []float64{1, 2}, 1); err == nil {
+		t.Errorf("expected an error for a series too short")
+	}
+	if _, err := derivative([]float64{0, 1, 1}, []float64{1, 2, 3}, 1); err == nil {
+		t.Errorf("expected an error for repeated positions")
+	}
+}
+
+func TestDoDerivative(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.txt")
+	if err := os.WriteFile(input, []byte("1\n3\n2\n5\n4\n6\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg, err := parseFlags([]string{"-derivative", "2", "-residuals", "-format", "svg", "-lambdas", "10", "-outdir", dir, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "series.txt-lambda-10.svg")); err != nil {
+		t.Errorf("missing plot: %v", err)
+	}
+	if _, err := parseFlags([]string{"-derivative", "3", input}, io.Discard); err == nil {
+		t.Errorf("expected an error for a third derivative")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// isURL reports whether the input name is an http or https URL.
+func isURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// urlPath returns the path of the URL name, without its query, for telling the format and naming the outputs of
+// the input.
+func urlPath(name string) string {
+	u, err := url.Parse(name)
+	if err != nil {
+		return name
+	}
+	if p := path.Base(u.Path); p != "/" && p != "." {
+		return u.Path
+	}
+	return u.Host
+}
+
+// headerList is a flag collecting HTTP headers given as "Name: value", once per header.
+type headerList http.Header
+
+// String implements flag.Value.
+func (h headerList) String() string {
+	var fields []string
+	for name := range h {
+		fields = append(fields, name)
+	}
+	return strings.Join(fields, ", ")
+}
+
+// Set implements flag.Value.
+func (h headerList) Set(s string) error {
+	name, value, ok := strings.Cut(s, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return errors.New(`want a header as "Name: value"`)
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	return nil
+}
+
+// fetch opens the body of the resource at the URL name, sending the headers of cfg and failing if the exchange
+// takes longer than its timeout.
+func fetch(name string, cfg *config) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range cfg.headers {
+		req.Header[key] = values
+	}
+	client := &http.Client{Timeout: cfg.timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", req.URL.Redacted(), resp.Status)
+	}
+	if cfg.progress != nil {
+		return progressReader{resp.Body, newProgress(cfg.progress, req.URL.Redacted(), resp.ContentLength)}, nil
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/slow":
+			<-r.Context().Done()
+		case r.Header.Get("Authorization") != "Bearer secret":
+			http.Error(w, "no token", http.StatusUnauthorized)
+		default:
+			io.WriteString(w, "t,v\n0,1\n1,3\n")
+		}
+	}))
+	defer srv.Close()
+
+	cfg, err := parseFlags([]string{"-header", "Authorization: Bearer secret", "-timeout", "50ms", "-x", "t", srv.URL + "/data.csv?rev=2"}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	name := cfg.inputs[0]
+	s, err := loadSeries(name, cfg)
+	if err != nil {
+		t.Fatalf("Failed to fetch: %v", err)
+	}
+	if len(s.X) != 2 || s.Y[1] != 3 {
+		t.Errorf("got x %v and y %v", s.X, s.Y)
+	}
+	if got := inputBase(name); got != "data.csv" {
+		t.Errorf("got outputs named after %q, want data.csv", got)
+	}
+
+	if _, err := loadSeries(srv.URL+"/data.csv", &config{inputFormat: "auto"}); err == nil {
+		t.Errorf("expected an error without the authorization header")
+	}
+	start := time.Now()
+	if _, err := loadSeries(srv.URL+"/slow", cfg); err == nil {
+		t.Errorf("expected an error for a request exceeding the timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the request took %v despite the timeout", elapsed)
+	}
+	if _, err := parseFlags([]string{"-header", "no colon", "a.dat"}, io.Discard); err == nil {
+		t.Errorf("expected an error for a header without a colon")
+	}
+	if got := inputBase("https://example.com/"); got != "example.com" {
+		t.Errorf("got %q for a URL without a path", got)
+	}
+}
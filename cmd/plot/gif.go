@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"math"
+	"os"
+
+	"github.com/grutz/go-whittaker-eilers/dataio"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	vgdraw "gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// Size, resolution and delay in hundredths of a second of the frames of a sweep GIF.
+const (
+	gifWidth  = 8 * vg.Inch
+	gifHeight = 4 * vg.Inch
+	gifDPI    = 96
+	gifDelay  = 50
+)
+
+// writeSweepGIF writes an animated GIF to the named file that shows the series s against one of its smooths per
+// frame, in the order of smooths, so the effect of lambda can be watched. All frames share the axes, which span
+// the series and every smooth and are styled as cfg asks.
+func writeSweepGIF(name, basename string, s *dataio.Series, smooths []dataio.Smooth, cfg *config) error {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, values := range append([][]float64{s.Y}, smoothValues(smooths)...) {
+		for _, v := range values {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) && (!cfg.logY || v > 0) {
+				lo, hi = math.Min(lo, v), math.Max(hi, v)
+			}
+		}
+	}
+
+	orig := logPoints(cfg, makePoints(s.X, s.Y), true)
+	anim := &gif.GIF{LoopCount: 0}
+	for _, sm := range smooths {
+		lambda := formatLambda(sm.Lambda)
+		p := plot.New()
+		p.Title.Text = plotTitle(&cfg.style, fmt.Sprintf("%s: Lambda %s", basename, lambda), basename, lambda)
+		p.X.Label.Text = "X"
+		p.Y.Label.Text = "Y"
+		line := logPoints(cfg, makePoints(s.X, sm.Z), true)
+		if err := addLines(p, &cfg.style, "Lambda "+lambda, line, basename, orig); err != nil {
+			return err
+		}
+		if lo <= hi {
+			p.Y.Min, p.Y.Max = lo, hi
+		}
+		styleAxes(cfg, p, true)
+
+		c := vgimg.NewWith(vgimg.UseWH(gifWidth, gifHeight), vgimg.UseDPI(gifDPI))
+		p.Draw(vgdraw.New(c))
+		img := c.Image()
+		frame := image.NewPaletted(img.Bounds(), palette.Plan9)
+		draw.Draw(frame, frame.Rect, img, img.Bounds().Min, draw.Src)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, gifDelay)
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(f, anim); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// smoothValues returns the values of every smooth.
+func smoothValues(smooths []dataio.Smooth) [][]float64 {
+	values := make([][]float64, len(smooths))
+	for i, sm := range smooths {
+		values[i] = sm.Z
+	}
+	return values
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"image/gif"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDoGIF(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.txt")
+	if err := os.WriteFile(input, []byte("1\n3\n2\n5\n4\n6\n5\n7\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg, err := parseFlags([]string{"-gif", "-lambda-range", "0.1:1e3:5", "-outdir", dir, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "series.txt-sweep.gif"))
+	if err != nil {
+		t.Fatalf("missing animation: %v", err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatalf("Failed to decode the animation: %v", err)
+	}
+	if len(anim.Image) != 5 || len(anim.Delay) != 5 {
+		t.Errorf("got %d frames, want one per lambda", len(anim.Image))
+	}
+	if b := anim.Image[0].Bounds(); b.Dx() != 8*gifDPI || b.Dy() != 4*gifDPI {
+		t.Errorf("frame is %v, want %d x %d", b, 8*gifDPI, 4*gifDPI)
+	}
+}
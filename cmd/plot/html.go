@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+
+	"github.com/grutz/go-whittaker-eilers/dataio"
+)
+
+// pageTemplate is the interactive page of a series: a canvas drawing the series and the smooth chosen with a
+// lambda slider, zoomed with the mouse wheel and panned by dragging, with the data inlined so the page needs no
+// network access.
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+canvas { width: 100%; height: 70vh; border: 1px solid #ccc; cursor: grab; }
+#controls { margin: 0.5em 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div id="controls">
+<label>Lambda <input id="lambda" type="range" min="0" max="{{.Last}}" step="1" value="0"></label>
+<span id="value"></span>
+<button id="reset">Reset zoom</button>
+</div>
+<canvas id="plot"></canvas>
+<p>Scroll to zoom, drag to pan.</p>
+<script>
+const data = {{.Data}};
+const canvas = document.getElementById("plot");
+const slider = document.getElementById("lambda");
+const margin = {left: 70, right: 20, top: 20, bottom: 40};
+const x = data.x || data.y.map((_, i) => i);
+const finite = v => v !== null && isFinite(v);
+
+let yMin = Infinity, yMax = -Infinity;
+for (const values of [data.y, ...data.smooths.map(s => s.z)]) {
+	for (const v of values) {
+		if (finite(v)) {
+			yMin = Math.min(yMin, v);
+			yMax = Math.max(yMax, v);
+		}
+	}
+}
+if (!(yMin < yMax)) {
+	yMin -= 1;
+	yMax += 1;
+}
+const full = x.length > 1 ? [x[0], x[x.length - 1]] : [x[0] - 1, x[0] + 1];
+let view = full.slice();
+
+function ticks(lo, hi, n) {
+	const rough = (hi - lo) / n;
+	const mag = Math.pow(10, Math.floor(Math.log10(rough)));
+	const step = [1, 2, 5, 10].map(m => m * mag).find(s => s >= rough);
+	const out = [];
+	for (let t = Math.ceil(lo / step) * step; t <= hi + step * 1e-9; t += step) {
+		out.push(+t.toPrecision(12));
+	}
+	return out;
+}
+
+function draw() {
+	const dpr = window.devicePixelRatio || 1;
+	const w = canvas.clientWidth, h = canvas.clientHeight;
+	canvas.width = w * dpr;
+	canvas.height = h * dpr;
+	const ctx = canvas.getContext("2d");
+	ctx.setTransform(dpr, 0, 0, dpr, 0, 0);
+	ctx.clearRect(0, 0, w, h);
+	const pw = w - margin.left - margin.right, ph = h - margin.top - margin.bottom;
+	const sx = v => margin.left + (v - view[0]) / (view[1] - view[0]) * pw;
+	const sy = v => margin.top + (yMax - v) / (yMax - yMin) * ph;
+
+	ctx.strokeStyle = ctx.fillStyle = "#000";
+	ctx.lineWidth = 1;
+	ctx.font = "12px sans-serif";
+	ctx.strokeRect(margin.left, margin.top, pw, ph);
+	ctx.textAlign = "center";
+	ctx.textBaseline = "top";
+	for (const t of ticks(view[0], view[1], 8)) {
+		ctx.beginPath();
+		ctx.moveTo(sx(t), margin.top + ph);
+		ctx.lineTo(sx(t), margin.top + ph + 5);
+		ctx.stroke();
+		ctx.fillText(String(t), sx(t), margin.top + ph + 7);
+	}
+	ctx.textAlign = "right";
+	ctx.textBaseline = "middle";
+	for (const t of ticks(yMin, yMax, 6)) {
+		ctx.beginPath();
+		ctx.moveTo(margin.left - 5, sy(t));
+		ctx.lineTo(margin.left, sy(t));
+		ctx.stroke();
+		ctx.fillText(String(t), margin.left - 7, sy(t));
+	}
+
+	function line(values, color, width) {
+		ctx.strokeStyle = color;
+		ctx.lineWidth = width;
+		ctx.beginPath();
+		let drawing = false;
+		for (let i = 0; i < values.length; i++) {
+			if (!finite(values[i])) {
+				drawing = false;
+				continue;
+			}
+			if (drawing) {
+				ctx.lineTo(sx(x[i]), sy(values[i]));
+			} else {
+				ctx.moveTo(sx(x[i]), sy(values[i]));
+			}
+			drawing = true;
+		}
+		ctx.stroke();
+	}
+	ctx.save();
+	ctx.beginPath();
+	ctx.rect(margin.left, margin.top, pw, ph);
+	ctx.clip();
+	line(data.y, "#999", 1);
+	if (data.smooths.length > 0) {
+		line(data.smooths[slider.value].z, "#c00", 2);
+	}
+	ctx.restore();
+}
+
+function showLambda() {
+	document.getElementById("value").textContent = data.smooths.length > 0 ? "λ = " + data.smooths[slider.value].lambda : "";
+}
+
+canvas.addEventListener("wheel", e => {
+	e.preventDefault();
+	const rect = canvas.getBoundingClientRect();
+	const f = Math.min(1, Math.max(0, (e.clientX - rect.left - margin.left) / (rect.width - margin.left - margin.right)));
+	const at = view[0] + f * (view[1] - view[0]);
+	const scale = Math.exp(e.deltaY * 0.001);
+	view = [at - (at - view[0]) * scale, at + (view[1] - at) * scale];
+	draw();
+}, {passive: false});
+let drag = null;
+canvas.addEventListener("mousedown", e => {
+	drag = {x: e.clientX, view: view.slice()};
+	canvas.style.cursor = "grabbing";
+});
+window.addEventListener("mousemove", e => {
+	if (drag) {
+		const dx = (e.clientX - drag.x) / (canvas.clientWidth - margin.left - margin.right) * (drag.view[1] - drag.view[0]);
+		view = [drag.view[0] - dx, drag.view[1] - dx];
+		draw();
+	}
+});
+window.addEventListener("mouseup", () => {
+	drag = null;
+	canvas.style.cursor = "";
+});
+document.getElementById("reset").addEventListener("click", () => {
+	view = full.slice();
+	draw();
+});
+slider.addEventListener("input", () => {
+	showLambda();
+	draw();
+});
+window.addEventListener("resize", draw);
+showLambda();
+draw();
+</script>
+</body>
+</html>
+`))
+
+// writeHTML writes the interactive page of the series s and its smooths to the named file.
+func writeHTML(name, title string, s *dataio.Series, smooths []dataio.Smooth) error {
+	var data bytes.Buffer
+	if err := dataio.WriteJSON(&data, s, smooths); err != nil {
+		return err
+	}
+	page := struct {
+		Title string
+		Last  int
+		// Data holds JSON of numbers and nulls only, which is safe to inline in a script
+		Data template.JS
+	}{title, max(0, len(smooths)-1), template.JS(data.String())}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := pageTemplate.Execute(f, page); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
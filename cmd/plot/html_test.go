@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoHTML(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.txt")
+	if err := os.WriteFile(input, []byte("1\n3\n2\n5\n4\n6\n5\n7\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg, err := parseFlags([]string{"-html", "-lambdas", "1,100", "-outdir", dir, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "series.txt.html"))
+	if err != nil {
+		t.Fatalf("missing page: %v", err)
+	}
+	for _, want := range []string{
+		"<title>series.txt</title>",
+		`max="1"`,
+		`const data = {"y":[1,3,2,5,4,6,5,7],"smooths":[{"lambda":1,`,
+		`{"lambda":100,`,
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+	if strings.Contains(string(page), "<script src") {
+		t.Error("page loads external scripts")
+	}
+}
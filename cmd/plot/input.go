@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/grutz/go-whittaker-eilers/dataio"
+	"github.com/klauspost/compress/zstd"
+)
+
+// stdinName is the input name that reads standard input.
+const stdinName = "-"
+
+// stdin is the reader of the input named stdinName, and stdout the writer of the output named stdinName, both
+// replaced in tests.
+var (
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
+)
+
+// Magic numbers at the start of compressed inputs.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// openInput opens the named input, standard input for stdinName and the resource of an http or https URL fetched
+// as cfg sets up, decompressing it if it is gzip or zstd compressed. The progress of reading it is written to
+// cfg.progress if it is set.
+func openInput(name string, cfg *config) (io.ReadCloser, error) {
+	if name == stdinName {
+		rc := io.NopCloser(stdin)
+		if cfg.progress != nil {
+			rc = progressReader{rc, newProgress(cfg.progress, "stdin", -1)}
+		}
+		return decompress(rc)
+	}
+	if isURL(name) {
+		body, err := fetch(name, cfg)
+		if err != nil {
+			return nil, err
+		}
+		r, err := decompress(body)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		return r, nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	var rc io.ReadCloser = f
+	if cfg.progress != nil {
+		size := int64(-1)
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			size = info.Size()
+		}
+		rc = progressFile{f, newProgress(cfg.progress, name, size)}
+	}
+	r, err := decompress(rc)
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return r, nil
+}
+
+// readCloser reads from a decompressor or buffer and closes it along with the input below.
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+// Close implements io.Closer.
+func (r readCloser) Close() error {
+	return r.close()
+}
+
+// decompress returns a reader of the decompressed contents of rc if it starts with the magic number of gzip or
+// zstd, and of its contents otherwise. Closing the reader closes rc. A file that is not compressed is returned
+// itself, so formats such as parquet keep its random access.
+func decompress(rc io.ReadCloser) (io.ReadCloser, error) {
+	var src io.Reader
+	var header []byte
+	if ra, ok := rc.(io.ReaderAt); ok {
+		buf := make([]byte, len(zstdMagic))
+		n, err := ra.ReadAt(buf, 0)
+		if err == nil || err == io.EOF {
+			src, header = rc, buf[:n]
+		}
+	}
+	if src == nil {
+		// standard input and pipes are peeked at through a buffer
+		br := bufio.NewReader(rc)
+		header, _ = br.Peek(len(zstdMagic))
+		src = br
+	}
+
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		gz, err := gzip.NewReader(src)
+		if err != nil {
+			return nil, err
+		}
+		return readCloser{gz, func() error {
+			gz.Close()
+			return rc.Close()
+		}}, nil
+	case bytes.HasPrefix(header, zstdMagic):
+		zr, err := zstd.NewReader(src)
+		if err != nil {
+			return nil, err
+		}
+		return readCloser{zr, func() error {
+			zr.Close()
+			return rc.Close()
+		}}, nil
+	case src == io.Reader(rc):
+		return rc, nil
+	}
+	return readCloser{src, rc.Close}, nil
+}
+
+// formatExt returns the lower case extension of the named input that tells its format, the one before .gz or
+// .zst for compressed files. The extension of a URL is that of its path.
+func formatExt(name string) string {
+	if isURL(name) {
+		name = urlPath(name)
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".gz" || ext == ".zst" {
+		ext = strings.ToLower(filepath.Ext(strings.TrimSuffix(name, filepath.Ext(name))))
+	}
+	return ext
+}
+
+// inputBase returns the name the outputs of the named input are derived from, the last element of its path for
+// a URL.
+func inputBase(name string) string {
+	if name == stdinName {
+		return "stdin"
+	}
+	if isURL(name) {
+		return path.Base(urlPath(name))
+	}
+	return filepath.Base(name)
+}
+
+// inputFormat returns the format of the named input: the format of cfg unless it is auto, and otherwise csv for
+// files ending in .csv or .tsv, json for files ending in .json, parquet for files ending in .parquet, arrow for
+// files ending in .arrows, xlsx for files ending in .xlsx and lines for any other, ignoring a trailing .gz or
+// .zst.
+func inputFormat(name string, cfg *config) string {
+	if cfg.inputFormat != "auto" {
+		return cfg.inputFormat
+	}
+	switch formatExt(name) {
+	case ".csv", ".tsv":
+		return "csv"
+	case ".json":
+		return "json"
+	case ".parquet":
+		return "parquet"
+	case ".arrows":
+		return "arrow"
+	case ".xlsx":
+		return "xlsx"
+	}
+	return "lines"
+}
+
+// parseDelimiter parses the delimiter of csv inputs into the options selecting it: tab, whitespace for runs of
+// spaces and tabs, or a single character. An empty delimiter keeps the default of the file name.
+func parseDelimiter(s string) (dataio.CSVOptions, error) {
+	switch s {
+	case "":
+		return dataio.CSVOptions{}, nil
+	case "tab", `\t`:
+		return dataio.CSVOptions{Comma: '\t'}, nil
+	case "whitespace":
+		return dataio.CSVOptions{Whitespace: true}, nil
+	}
+	if r := []rune(s); len(r) == 1 {
+		return dataio.CSVOptions{Comma: r[0]}, nil
+	}
+	return dataio.CSVOptions{}, fmt.Errorf("invalid delimiter %q, want a single character, tab or whitespace", s)
+}
+
+// csvOptions returns the options reading the named csv input: the delimiter of cfg, tab for files ending in .tsv
+// if cfg has none, and the columns of cfg.
+func csvOptions(name string, cfg *config) dataio.CSVOptions {
+	opts := cfg.delimiter
+	if opts.Comma == 0 && !opts.Whitespace && formatExt(name) == ".tsv" {
+		opts.Comma = '\t'
+	}
+	opts.X, opts.Y, opts.W, opts.XTime = cfg.xColumn, cfg.yColumn, cfg.wColumn, cfg.timeLayout
+	return opts
+}
+
+// loadSeries reads the series of the named input in the input format of cfg.
+func loadSeries(name string, cfg *config) (*dataio.Series, error) {
+	r, err := openInput(name, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	switch format := inputFormat(name, cfg); format {
+	case "lines":
+		y, err := readFloats(r)
+		if err != nil {
+			return nil, err
+		}
+		return &dataio.Series{Y: y}, nil
+	case "csv":
+		return dataio.ReadCSV(r, csvOptions(name, cfg))
+	case "json":
+		return dataio.ReadJSON(r)
+	case "parquet":
+		ras, ok := r.(dataio.ReaderAtSeeker)
+		if !ok {
+			// parquet is read from its footer, so a stream is buffered first
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			ras = bytes.NewReader(data)
+		}
+		return dataio.ReadParquet(ras, dataio.ParquetOptions{X: cfg.xColumn, Y: cfg.yColumn, W: cfg.wColumn})
+	case "arrow":
+		return dataio.ReadArrow(r, dataio.ArrowOptions{X: cfg.xColumn, Y: cfg.yColumn, W: cfg.wColumn})
+	case "xlsx":
+		return dataio.ReadXLSX(r, dataio.XLSXOptions{Sheet: cfg.sheet, X: cfg.xColumn, Y: cfg.yColumn, W: cfg.wColumn, XTime: cfg.timeLayout})
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+}
+
+// readFloats reads one value per line from r, skipping lines that are not numbers.
+func readFloats(r io.Reader) ([]float64, error) {
+	var numbers []float64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		number, err := strconv.ParseFloat(strings.TrimSpace(line), 64)
+		if err != nil {
+			// skip non-float lines
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return numbers, nil
+}
+
+// loadColumns reads a series of each value column of cfg.columns of the named csv input, or of every numeric column
+// when it is empty, returning the names of the columns with them.
+func loadColumns(name string, cfg *config) ([]string, []*dataio.Series, error) {
+	if format := inputFormat(name, cfg); format != "csv" {
+		return nil, nil, fmt.Errorf("%s: -columns reads csv inputs, not %s", name, format)
+	}
+	r, err := openInput(name, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+	var columns []string
+	if len(cfg.columns) > 0 {
+		columns = cfg.columns
+	}
+	return dataio.ReadCSVColumns(r, csvOptions(name, cfg), columns)
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/grutz/go-whittaker-eilers/dataio"
+	"github.com/klauspost/compress/zstd"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestLoadFloats(t *testing.T) {
+	defer func() { stdin = os.Stdin }()
+	stdin = strings.NewReader("value\n1.5\n\n-2\nn/a\n3e2\n")
+	cfg := &config{inputFormat: "auto"}
+	got, err := loadSeries(stdinName, cfg)
+	if err != nil {
+		t.Fatalf("Failed to read standard input: %v", err)
+	}
+	if y := got.Y; got.X != nil || len(y) != 3 || y[0] != 1.5 || y[1] != -2 || y[2] != 300 {
+		t.Errorf("got %v and %v, want no positions and [1.5 -2 300]", got.X, y)
+	}
+	if inputBase(stdinName) != "stdin" || inputBase(filepath.Join("a", "b.dat")) != "b.dat" {
+		t.Errorf("unexpected input names %q and %q", inputBase(stdinName), inputBase("a/b.dat"))
+	}
+	if _, err := loadSeries(filepath.Join(t.TempDir(), "missing"), cfg); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestLoadSeriesCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.CSV")
+	if err := os.WriteFile(path, []byte("t,signal\n0,1\n2,4\n3,9\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg := &config{inputFormat: "auto", xColumn: "t", yColumn: "signal"}
+	s, err := loadSeries(path, cfg)
+	if err != nil {
+		t.Fatalf("Failed to read csv: %v", err)
+	}
+	if len(s.X) != 3 || s.X[1] != 2 || s.Y[2] != 9 {
+		t.Errorf("got %v and %v", s.X, s.Y)
+	}
+
+	defer func() { stdin = os.Stdin }()
+	stdin = strings.NewReader("a,b\n1,2\n")
+	cfg = &config{inputFormat: "csv", yColumn: "b"}
+	if s, err := loadSeries(stdinName, cfg); err != nil || len(s.Y) != 1 || s.Y[0] != 2 {
+		t.Errorf("got %v and %v for csv on standard input", s, err)
+	}
+	if _, err := loadSeries(path, &config{inputFormat: "xml"}); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestLoadSeriesDelimiter(t *testing.T) {
+	dir := t.TempDir()
+	tsv := filepath.Join(dir, "trace.tsv")
+	if err := os.WriteFile(tsv, []byte("t\tv\n0\t1\n1\t4\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg := &config{inputFormat: "auto", xColumn: "t", yColumn: "v"}
+	if s, err := loadSeries(tsv, cfg); err != nil || len(s.X) != 2 || s.Y[1] != 4 {
+		t.Errorf("got %v and %v for a tsv file", s, err)
+	}
+
+	aligned := filepath.Join(dir, "export.csv")
+	if err := os.WriteFile(aligned, []byte("t    v\n0    1.5\n10   2\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg, err := parseFlags([]string{"-delimiter", "whitespace", "-x", "t", aligned}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if s, err := loadSeries(aligned, cfg); err != nil || len(s.X) != 2 || s.X[1] != 10 || s.Y[0] != 1.5 {
+		t.Errorf("got %v and %v for a whitespace separated file", s, err)
+	}
+
+	for _, tt := range []struct {
+		delimiter string
+		want      dataio.CSVOptions
+	}{
+		{"", dataio.CSVOptions{}},
+		{";", dataio.CSVOptions{Comma: ';'}},
+		{"tab", dataio.CSVOptions{Comma: '\t'}},
+		{`\t`, dataio.CSVOptions{Comma: '\t'}},
+		{"whitespace", dataio.CSVOptions{Whitespace: true}},
+	} {
+		if got, err := parseDelimiter(tt.delimiter); err != nil || got != tt.want {
+			t.Errorf("parseDelimiter(%q) = %+v, %v, want %+v", tt.delimiter, got, err, tt.want)
+		}
+	}
+	if _, err := parseFlags([]string{"-delimiter", ";;", "a.csv"}, io.Discard); err == nil {
+		t.Errorf("expected an error for a delimiter of two characters")
+	}
+}
+
+func TestLoadSeriesParquet(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "time", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "flux", Type: arrow.PrimitiveTypes.Float32},
+	}, nil)
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	b.Field(0).(*array.Float64Builder).AppendValues([]float64{0, 0.5, 2}, nil)
+	b.Field(1).(*array.Float32Builder).AppendValues([]float32{3, 4, 5}, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+	tbl := array.NewTableFromRecords(schema, []arrow.Record{rec})
+	defer tbl.Release()
+	var buf bytes.Buffer
+	if err := pqarrow.WriteTable(tbl, &buf, 1024, nil, pqarrow.DefaultWriterProps()); err != nil {
+		t.Fatalf("Failed to write parquet: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "export.parquet")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := &config{inputFormat: "auto", xColumn: "time", yColumn: "flux"}
+	if s, err := loadSeries(path, cfg); err != nil || len(s.X) != 3 || s.X[1] != 0.5 || s.Y[2] != 5 {
+		t.Errorf("got %v and %v for a parquet file", s, err)
+	}
+
+	defer func() { stdin = os.Stdin }()
+	stdin = bytes.NewReader(buf.Bytes())
+	cfg = &config{inputFormat: "parquet", yColumn: "flux"}
+	if s, err := loadSeries(stdinName, cfg); err != nil || s.X != nil || len(s.Y) != 3 || s.Y[0] != 3 {
+		t.Errorf("got %v and %v for parquet on standard input", s, err)
+	}
+}
+
+func TestLoadSeriesXLSX(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	if _, err := f.NewSheet("Run 2"); err != nil {
+		t.Fatal(err)
+	}
+	for i, row := range [][]interface{}{{"t", "counts"}, {0, 10}, {1, 12}, {3, 11}} {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Run 2", cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "trace.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatalf("Failed to write the workbook: %v", err)
+	}
+
+	cfg, err := parseFlags([]string{"-sheet", "Run 2", "-x", "A", "-y", "counts", path}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if s, err := loadSeries(path, cfg); err != nil || len(s.X) != 3 || s.X[2] != 3 || s.Y[1] != 12 {
+		t.Errorf("got %v and %v for an xlsx file", s, err)
+	}
+}
+
+func TestOpenInputCompressed(t *testing.T) {
+	dir := t.TempDir()
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("t,v\n0,1\n1,2\n"))
+	zw.Close()
+	path := filepath.Join(dir, "trace.CSV.gz")
+	if err := os.WriteFile(path, gz.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg := &config{inputFormat: "auto", xColumn: "t"}
+	if s, err := loadSeries(path, cfg); err != nil || len(s.X) != 2 || s.Y[1] != 2 {
+		t.Errorf("got %v and %v for a gzip compressed csv file", s, err)
+	}
+
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed := enc.EncodeAll([]byte("1\n2\n3\n"), nil)
+	enc.Close()
+	defer func() { stdin = os.Stdin }()
+	stdin = bytes.NewReader(compressed)
+	if s, err := loadSeries(stdinName, &config{inputFormat: "auto"}); err != nil || len(s.Y) != 3 || s.Y[2] != 3 {
+		t.Errorf("got %v and %v for zstd compressed standard input", s, err)
+	}
+
+	plain := filepath.Join(dir, "plain.txt")
+	if err := os.WriteFile(plain, []byte("1\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	r, err := openInput(plain, &config{})
+	if err != nil {
+		t.Fatalf("Failed to open input: %v", err)
+	}
+	defer r.Close()
+	if _, ok := r.(*os.File); !ok {
+		t.Errorf("an uncompressed file was wrapped in %T", r)
+	}
+
+	broken := filepath.Join(dir, "broken.gz")
+	if err := os.WriteFile(broken, gzipMagic, 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	if _, err := openInput(broken, &config{}); err == nil {
+		t.Errorf("expected an error for a truncated gzip header")
+	}
+
+	for name, want := range map[string]string{"a.csv.gz": ".csv", "b.TSV.zst": ".tsv", "c.gz": "", "d.json": ".json"} {
+		if got := formatExt(name); got != want {
+			t.Errorf("formatExt(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
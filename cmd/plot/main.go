@@ -1,115 +1,815 @@
+// Command plot smooths data series with the Whittaker-Eilers smoother and plots each of them against its smooth
+// for every lambda, plus a combined plot of all the smooths.
+//
+// Usage:
+//
+//	plot [-lambdas 5,10,50,100,500] [-order 2] [-outdir dir] [flags] file ...
+//
+// The inputs are read in the format given by -input-format:
+//
+//   - lines holds one value per line; lines that are not numbers are skipped.
+//   - csv has a header row, its fields separated by -delimiter: a character such as ;, tab, or whitespace for
+//     runs of spaces and tabs.
+//   - json holds an array of values or an object {"x": [...], "y": [...], "w": [...]}, null standing for a
+//     missing value.
+//   - parquet is a Parquet file with numeric columns.
+//   - arrow is an Arrow IPC stream with numeric columns.
+//   - xlsx is an Excel workbook whose sheet, the first one unless -sheet names another, has a header row.
+//
+// The default format auto picks csv for files ending in .csv, tab separated csv for .tsv, json for .json, parquet
+// for .parquet, arrow for .arrows, xlsx for .xlsx and lines for any other. For csv, parquet, arrow and xlsx
+// inputs -x and -y select the columns of the sample positions and of the values by name or zero-based index, or
+// for xlsx by column letter; a series with positions is smoothed over them, which may be unevenly spaced.
+// -weights selects a column of weights of the values the same way, such as zero for values to ignore; weights
+// need evenly spaced values, so they do not combine with -x.
+//
+// -columns smooths several value columns of csv inputs, each on its own, instead of the single one of -y: a
+// comma separated list of columns such as a,b,3, or all for every column other than -x and -weights whose fields
+// are all numbers. The outputs of every column are named <file>-<column> in place of <file>, and with
+// -combine-columns the columns and their smooths are plotted together instead, to
+// <file>-columns-lambda-<lambda>.<format>, or <file>-columns.<format> with -auto-lambda.
+//
+// -time reads the positions of -x as timestamps, given as rfc3339 such as 2024-05-01T12:00:00Z, unix or
+// unixmilli for seconds or milliseconds since the Unix epoch, or a Go time layout such as "2006-01-02 15:04",
+// and labels the x axes of the plots with dates and times in UTC. Timestamps are smoothed as seconds since the
+// epoch, which sets the units of lambda, and xlsx cells holding dates are read as such. Timestamp and date
+// columns of parquet and arrow inputs are always read as seconds, -time only adds the time axes for them.
+//
+// -lambdas lists the lambdas to plot, such as 1,10,1e3, and -lambda-range lo:hi:count plots count lambdas
+// spaced evenly on a log scale from lo to hi instead, such as 1:1e4:5 for 1, 10, 100, 1000 and 10000.
+//
+// -auto-lambda replaces the lambdas by the one minimizing a criterion, cv for leave-one-out cross-validation, gcv
+// for generalized cross-validation or vcurve for the V-curve, searched between 1e-2 and 1e8 for every input and
+// printed. It needs evenly spaced, unweighted values, so it does not combine with -x or -weights.
+//
+// -config reads settings from a YAML or TOML file whose keys are the names of the flags, with lists for
+// -lambdas, a table of names and values for -header, and the inputs under inputs, used when the command line
+// names none. Flags given on the command line override the file, so a batch run is reproduced with a file like
+//
+//	inputs: [day1.csv, day2.csv]
+//	lambdas: [10, 100, 1000]
+//	y: temperature
+//	weights: quality
+//	outdir: plots
+//	format: svg
+//
+// Inputs compressed with gzip or zstd are decompressed on the fly, and the auto format looks at the extension
+// before a trailing .gz or .zst, so data.csv.gz reads as csv.
+//
+// A file named - is read from standard input, so the tool composes with pipelines such as cat data.txt | plot -,
+// and http and https URLs are fetched, as in plot -header "Authorization: Bearer $TOKEN" https://host/data.csv;
+// -header adds a header to the requests and may be repeated, -timeout limits every fetch.
+//
+// An input that is a directory stands for every file below it that is not hidden, and one holding *, ? or [ for
+// every file matching it as a glob pattern, where ** matches any number of directories, as in
+// plot 'runs/**/*.dat' quoted so the shell leaves the pattern to the tool. The outputs of these files mirror the
+// input tree: they are written to the directories below outdir that the files are in below the directory, or
+// below the leading directories of the pattern without pattern characters.
+//
+// The plots are written to outdir as <file>-lambda-<lambda>.<format> and <file>-combined.<format>, where the file
+// of standard input is named stdin and that of a URL after the last element of its path. -format selects png, the
+// default, jpg or tiff images or svg, pdf or eps vector figures. -logx and -logy put the positions and the values
+// on log scales, as suits spectra and power laws, leaving out the points that are not positive there, such as the
+// first index of a series without positions; residuals and derivatives keep a linear y axis. With -residuals every
+// plot of a lambda gets a panel below it showing the residuals, original minus smooth, where structure left in the
+// residuals shows undersmoothing and noise left in the smooth oversmoothing. -derivative 1 or 2 adds a panel of the
+// first or second derivative of the smooth, to inspect peaks and inflection points. -gif also writes
+// <file>-sweep.gif, an animation with one frame per lambda in the order given, which suits many lambdas of
+// -lambda-range, and -html writes <file>.html, a self-contained page that zooms and pans the series with a lambda
+// slider over the smooths. With -json the series and its smooths are also written to <file>.json as {"x": [...],
+// "y": [...], "smooths": [{"lambda": ..., "order": ..., "z": [...]}, ...]}, and with -arrow to <file>.arrows as an
+// Arrow IPC stream with the columns x, y and smooth_<lambda>. -out writes the numbers of a single input to a csv
+// file, or to standard output for -, with the columns index or x, original, smoothed and residual, the last two
+// suffixed with _<lambda> when there are several lambdas.
+//
+// -summary writes <file>.summary.json for batch pipelines: the input, the number of values n, the order, for every
+// lambda the root mean square of the residuals, the roughness of the smooth and, for evenly spaced unweighted
+// series, its effective degrees of freedom, and the files written for the input, as in
+//
+//	{"input": "day1.csv", "n": 1440, "order": 2,
+//	 "smooths": [{"lambda": 100, "rmse": 0.41, "roughness": 0.0032, "effective_df": 61.7}],
+//	 "outputs": ["plots/day1.csv-lambda-100.png", "plots/day1.csv-combined.png"]}
+//
+// With -columns every column gets its own summary, which names the column.
+//
+// Every run also writes manifest.json to outdir, recording for every file written below it the input, the
+// lambdas and the order it was made with, so we diff can compare two runs file by file.
+//
+// -progress prints status lines to standard error while the inputs are read, every second with the bytes read so
+// far and the share of the size for files and responses that give it, and before every smooth, so reading and
+// smoothing files of millions of values shows the tool is at work.
+//
+// -watch keeps the tool running after the first smooths and plots every input again whenever it changes, as
+// when an instrument appends to a live log, until it is interrupted. The files are polled for their size and
+// modification time, the errors of a run are printed without stopping the watch, and the files a directory or
+// pattern stands for are those found at the start.
+//
+// -width and -height set the size of the plots in inches, 20 by 10 by default, to which every residual or
+// derivative panel adds half the height, and -dpi the resolution of png, jpg and tiff images. -line-width sets the
+// width of the lines in points and -colors their colors in turn, as SVG color names such as steelblue or #rrggbb
+// codes. -title replaces the titles of the plots of the series, {input} standing for the name of the input and
+// {lambda} for the lambda of the plot or all of them in the combined plot, and -xlabel and -ylabel replace the
+// labels of the axes.
 package main
 
 import (
-	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	smoother "github.com/grutz/go-whittaker-eilers"
+	"github.com/grutz/go-whittaker-eilers/dataio"
+	"github.com/grutz/go-whittaker-eilers/internal/runmanifest"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
-	"gonum.org/v1/plot/plotutil"
 	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
 )
 
-func floatsFromFile(filename string) ([]float64, error) {
-	file, err := os.Open(filename)
-	if err != nil {
+// config holds the settings of a run of the tool.
+type config struct {
+	lambdas []float64
+	order   int
+	outDir  string
+	inputs  []string
+	// subdirs holds the directory of every input found in a directory or by a glob pattern relative to it, which
+	// its outputs are written to below outDir.
+	subdirs map[string]string
+	// criterion selects the lambda of every input if autoLambda is set, instead of lambdas.
+	criterion  smoother.Criterion
+	autoLambda bool
+	// format is the image format of the plots and the extension of their files.
+	format string
+	// residuals adds a panel of the residuals to the plot of every lambda.
+	residuals bool
+	// gif writes an animation of the smooths of every input, one frame per lambda, and html an interactive page.
+	gif, html bool
+	// derivative is the order of the derivative of the smooth added as a panel to the plot of every lambda, zero
+	// for none.
+	derivative int
+
+	// inputFormat is the format of the inputs, auto to pick it by file name, and xColumn, yColumn and wColumn
+	// select the columns of the positions, values and weights of csv, parquet, arrow and xlsx inputs.
+	inputFormat               string
+	xColumn, yColumn, wColumn string
+	// timeLayout is the layout of dataio.ParseTime the positions are read with as timestamps, empty for numbers.
+	timeLayout string
+	// columns selects the value columns of csv inputs that are smoothed each on its own, empty but not nil for all
+	// numeric ones and nil to smooth the single column of yColumn. combineColumns plots them together instead.
+	columns        []string
+	combineColumns bool
+	// logX and logY plot the positions and the values on log scales.
+	logX, logY bool
+	// style is the appearance of the plots.
+	style style
+	// headers are sent with the requests of URL inputs, which fail if they take longer than timeout.
+	headers http.Header
+	timeout time.Duration
+	// sheet is the sheet of xlsx inputs, empty for the first one.
+	sheet string
+	// delimiter holds the delimiter of csv inputs, zero for the default of their file name.
+	delimiter dataio.CSVOptions
+	// out is the csv file the smooths of the single input are written to, stdinName for standard output and
+	// empty for none.
+	out string
+	// progress receives status lines of reading and smoothing every input, nil for none.
+	progress io.Writer
+	// summary writes a JSON summary of the smooths and outputs of every input.
+	summary bool
+	// manifest records the outputs of the run, written to outDir as runmanifest.FileName once the inputs are done.
+	// It is nil in tests that do not need it.
+	manifest *runmanifest.Manifest
+	// watch smooths and plots the inputs again every time they change, until the tool is interrupted.
+	watch bool
+	// writeJSON and writeArrow also write the smooths of every input as JSON and as an Arrow IPC stream.
+	writeJSON, writeArrow bool
+}
+
+// parseFlags parses the command line arguments, without the program name, into a config.
+func parseFlags(args []string, stderr io.Writer) (*config, error) {
+	flags := flag.NewFlagSet("plot", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: plot [flags] file ...")
+		flags.PrintDefaults()
+	}
+	lambdas := flags.String("lambdas", "5,10,50,100,500", "comma separated smoothing parameters to plot")
+	lambdaRange := flags.String("lambda-range", "", "log spaced lambdas to plot as lo:hi:count, instead of -lambdas")
+	autoLambda := flags.String("auto-lambda", "", "choose lambda by cv, gcv or vcurve instead of using -lambdas")
+	order := flags.Int("order", 2, "order of the differences of the penalty")
+	outDir := flags.String("outdir", ".", "directory to write the plots into")
+	format := flags.String("format", "png", "image format of the plots: png, jpg, tiff, svg, pdf or eps")
+	inputFormat := flags.String("input-format", "auto", "format of the inputs: auto, lines, csv, json, parquet, arrow or xlsx")
+	delimiter := flags.String("delimiter", "", "field delimiter of csv inputs: a character, tab or whitespace (default , or tab for .tsv)")
+	sheet := flags.String("sheet", "", "sheet of xlsx inputs (default the first one)")
+	xColumn := flags.String("x", "", "column of the sample positions, by name or index (default none)")
+	yColumn := flags.String("y", "", "column of the values, by name or index (default the first one that is not x)")
+	timeLayout := flags.String("time", "", "read -x as timestamps: rfc3339, unix, unixmilli or a Go time layout")
+	columns := flags.String("columns", "", "comma separated value columns of csv inputs to smooth each, or all for every numeric one")
+	combineColumns := flags.Bool("combine-columns", false, "plot the columns of -columns together, one plot per lambda")
+	wColumn := flags.String("weights", "", "column of the weights of the values, by name or index (default none)")
+	writeJSON := flags.Bool("json", false, "also write the series and its smooths to <file>.json")
+	out := flags.String("out", "", "csv file to write the series and its smooths to, - for standard output")
+	residuals := flags.Bool("residuals", false, "add a panel of the residuals to the plot of every lambda")
+	derivative := flags.Int("derivative", 0, "add a panel of the first or second derivative of the smooth to the plot of every lambda")
+	writeGIF := flags.Bool("gif", false, "also write an animation of the smooths over the lambdas to <file>-sweep.gif")
+	writeHTML := flags.Bool("html", false, "also write an interactive page of the smooths to <file>.html")
+	logX := flags.Bool("logx", false, "plot the positions on a log scale, leaving out those that are not positive")
+	logY := flags.Bool("logy", false, "plot the values and smooths on a log scale, leaving out those that are not positive")
+	width := flags.Float64("width", float64(defaultWidth/vg.Inch), "width of the plots in inches")
+	height := flags.Float64("height", float64(defaultHeight/vg.Inch), "height of the plots in inches, of their first panel when they have several")
+	dpi := flags.Int("dpi", defaultDPI, "resolution of png, jpg and tiff plots in dots per inch")
+	lineWidth := flags.Float64("line-width", 1, "width of the lines in points")
+	colors := flags.String("colors", "", "comma separated colors of the lines in turn, as names or #rrggbb")
+	title := flags.String("title", "", "title of the plots, in which {input} and {lambda} stand for the input and its lambdas")
+	xLabel := flags.String("xlabel", "", "label of the x axes (default X)")
+	yLabel := flags.String("ylabel", "", "label of the y axes of the values (default Y)")
+	writeArrow := flags.Bool("arrow", false, "also write the series and its smooths to <file>.arrows")
+	headers := make(http.Header)
+	flags.Var(headerList(headers), "header", "HTTP header \"Name: value\" of requests for URL inputs, may be repeated")
+	timeout := flags.Duration("timeout", 30*time.Second, "time limit of requests for URL inputs")
+	summarize := flags.Bool("summary", false, "also write a summary of the smooths and outputs to <file>.summary.json")
+	showProgress := flags.Bool("progress", false, "print status lines while reading and smoothing the inputs")
+	watchInputs := flags.Bool("watch", false, "smooth and plot the inputs again every time they change, until interrupted")
+	configFile := flags.String("config", "", "YAML or TOML file of settings named like the flags and of inputs")
+	if err := flags.Parse(args); err != nil {
 		return nil, err
 	}
-	defer file.Close()
-
-	var numbers []float64
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		number, err := strconv.ParseFloat(strings.TrimSpace(line), 64)
+	inputs := flags.Args()
+	if *configFile != "" {
+		fileInputs, err := loadConfigFile(*configFile, flags)
 		if err != nil {
-			// skip non-float lines
-			continue
+			return nil, err
+		}
+		if len(inputs) == 0 {
+			inputs = fileInputs
 		}
-		numbers = append(numbers, number)
+	}
+	if len(inputs) == 0 {
+		flags.Usage()
+		return nil, errors.New("no input files given")
+	}
+	inputs, subdirs, err := expandInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+	stdinInputs := 0
+	for _, input := range inputs {
+		if input == stdinName {
+			stdinInputs++
+		}
+	}
+	if stdinInputs > 1 {
+		return nil, errors.New("standard input can only be read once")
+	}
+	if *watchInputs {
+		for _, input := range inputs {
+			if input == stdinName || isURL(input) {
+				return nil, fmt.Errorf("-watch watches files, not %s", input)
+			}
+		}
+	}
+	if !plotFormats[*format] {
+		return nil, fmt.Errorf("unknown plot format %q", *format)
+	}
+	if *autoLambda != "" && *xColumn != "" {
+		return nil, errors.New("-auto-lambda needs evenly spaced values and does not combine with -x")
+	}
+	if *autoLambda != "" && *wColumn != "" {
+		return nil, errors.New("-auto-lambda needs unweighted values and does not combine with -weights")
+	}
+	if *timeLayout != "" && *xColumn == "" {
+		return nil, errors.New("-time needs the column of the timestamps given by -x")
+	}
+	if *timeLayout != "" && *logX {
+		return nil, errors.New("-logx does not combine with the time axis of -time")
+	}
+	if !(*width > 0) || !(*height > 0) || *dpi < 1 || !(*lineWidth > 0) {
+		return nil, errors.New("the size, resolution and line width of the plots must be positive")
+	}
+	if *xColumn != "" && *wColumn != "" {
+		return nil, errors.New("-weights needs evenly spaced values and does not combine with -x")
+	}
+	if *derivative < 0 || *derivative > 2 {
+		return nil, fmt.Errorf("derivative order %d, want 1 or 2", *derivative)
+	}
+	if *columns != "" && *yColumn != "" {
+		return nil, errors.New("-columns and -y are exclusive")
+	}
+	if *columns != "" && *out != "" {
+		return nil, errors.New("-out takes a single series and does not combine with -columns")
+	}
+	if *combineColumns && *columns == "" {
+		return nil, errors.New("-combine-columns needs the columns given by -columns")
+	}
+	if *out != "" && len(inputs) > 1 {
+		return nil, errors.New("-out takes a single input")
 	}
 
-	if err := scanner.Err(); err != nil {
+	cfg := &config{
+		order:          *order,
+		outDir:         *outDir,
+		format:         *format,
+		residuals:      *residuals,
+		derivative:     *derivative,
+		gif:            *writeGIF,
+		html:           *writeHTML,
+		inputs:         inputs,
+		subdirs:        subdirs,
+		inputFormat:    *inputFormat,
+		xColumn:        *xColumn,
+		yColumn:        *yColumn,
+		wColumn:        *wColumn,
+		timeLayout:     *timeLayout,
+		columns:        parseColumns(*columns),
+		combineColumns: *combineColumns,
+		logX:           *logX,
+		logY:           *logY,
+		sheet:          *sheet,
+		headers:        headers,
+		timeout:        *timeout,
+		writeJSON:      *writeJSON,
+		writeArrow:     *writeArrow,
+		out:            *out,
+		watch:          *watchInputs,
+		summary:        *summarize,
+		style: style{
+			width:     vg.Length(*width) * vg.Inch,
+			height:    vg.Length(*height) * vg.Inch,
+			dpi:       *dpi,
+			lineWidth: vg.Points(*lineWidth),
+			title:     *title,
+			xLabel:    *xLabel,
+			yLabel:    *yLabel,
+		},
+	}
+	if *showProgress {
+		cfg.progress = stderr
+	}
+	cfg.manifest = &runmanifest.Manifest{Tool: "plot"}
+	if cfg.style.colors, err = parseColors(*colors); err != nil {
+		return nil, err
+	}
+	if cfg.lambdas, err = parseLambdas(*lambdas); err != nil {
 		return nil, err
 	}
-	return numbers, nil
+	if *lambdaRange != "" {
+		explicit := false
+		flags.Visit(func(f *flag.Flag) { explicit = explicit || f.Name == "lambdas" })
+		if explicit {
+			return nil, errors.New("-lambdas and -lambda-range are exclusive")
+		}
+		if cfg.lambdas, err = parseLambdaRange(*lambdaRange); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.delimiter, err = parseDelimiter(*delimiter); err != nil {
+		return nil, err
+	}
+	if *autoLambda != "" {
+		cfg.autoLambda = true
+		if cfg.criterion, err = parseCriterion(*autoLambda); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// parseColumns parses the comma separated columns of -columns, returning nil for none and an empty list for all.
+func parseColumns(s string) []string {
+	switch s {
+	case "":
+		return nil
+	case "all":
+		return []string{}
+	}
+	var columns []string
+	for _, field := range strings.Split(s, ",") {
+		columns = append(columns, strings.TrimSpace(field))
+	}
+	return columns
+}
+
+// parseLambdaRange parses lo:hi:count into count lambdas spaced evenly on a log scale from lo to hi.
+func parseLambdaRange(s string) ([]float64, error) {
+	fields := strings.Split(s, ":")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid lambda range %q, want lo:hi:count", s)
+	}
+	lo, errLo := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+	hi, errHi := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+	count, errCount := strconv.Atoi(strings.TrimSpace(fields[2]))
+	if errLo != nil || errHi != nil || errCount != nil {
+		return nil, fmt.Errorf("invalid lambda range %q, want lo:hi:count", s)
+	}
+	if !(lo > 0) || !(hi >= lo) || math.IsInf(hi, 1) || count < 1 || (count == 1 && hi != lo) {
+		return nil, fmt.Errorf("invalid lambda range %q, want 0 < lo <= hi and a count of at least 2 unless lo is hi", s)
+	}
+	lambdas := make([]float64, count)
+	lambdas[0], lambdas[count-1] = lo, hi
+	for k := 1; k < count-1; k++ {
+		// rounded so the lambdas name their plots legibly
+		v := math.Pow(10, math.Log10(lo)+(math.Log10(hi)-math.Log10(lo))*float64(k)/float64(count-1))
+		lambdas[k], _ = strconv.ParseFloat(strconv.FormatFloat(v, 'g', 6, 64), 64)
+	}
+	return lambdas, nil
+}
+
+// autoLambdaRange is the range of lambdas -auto-lambda searches.
+var autoLambdaRange = [2]float64{1e-2, 1e8}
+
+// parseCriterion parses the name of a criterion of automatic lambda selection.
+func parseCriterion(s string) (smoother.Criterion, error) {
+	switch s {
+	case "cv":
+		return smoother.CV, nil
+	case "gcv":
+		return smoother.GCV, nil
+	case "vcurve":
+		return smoother.VCurve, nil
+	}
+	return 0, fmt.Errorf("unknown lambda criterion %q, want cv, gcv or vcurve", s)
+}
+
+// plotFormats are the image formats plots can be saved in, by the file extension plot.Save picks the format by.
+var plotFormats = map[string]bool{
+	"png": true, "jpg": true, "jpeg": true, "tif": true, "tiff": true,
+	"svg": true, "pdf": true, "eps": true,
 }
 
-func makePoints(y []float64) plotter.XYs {
+// parseLambdas parses a comma separated list of smoothing parameters.
+func parseLambdas(s string) ([]float64, error) {
+	var lambdas []float64
+	for _, field := range strings.Split(s, ",") {
+		lambda, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lambda %q", field)
+		}
+		lambdas = append(lambdas, lambda)
+	}
+	return lambdas, nil
+}
+
+// makePoints returns the points of the values y at the positions x, or at their indices when x is nil.
+func makePoints(x, y []float64) plotter.XYs {
 	pts := make(plotter.XYs, len(y))
 	for i := range pts {
 		pts[i].X = float64(i)
+		if x != nil {
+			pts[i].X = x[i]
+		}
 		pts[i].Y = y[i]
 	}
 	return pts
 }
 
-func do(filename string) {
-	data, err := floatsFromFile(filename)
+// smoothSeries smooths s with lambda and order d, over its positions or with its weights when it has them.
+func smoothSeries(s *dataio.Series, lambda float64, d int) ([]float64, error) {
+	if s.W != nil {
+		if s.X != nil {
+			return nil, errors.New("weights need evenly spaced values, the series has positions")
+		}
+		sm, err := smoother.New(smoother.WithLambda(lambda), smoother.WithOrder(d), smoother.WithWeights(s.W))
+		if err != nil {
+			return nil, err
+		}
+		return sm.Smooth(s.Y)
+	}
+	if s.X != nil {
+		return smoother.WESmootherGaps(s.X, s.Y, lambda, d, math.Inf(1))
+	}
+	return smoother.WESmoother(s.Y, lambda, d)
+}
+
+// formatLambda formats lambda for titles and file names, without trailing zeros.
+func formatLambda(lambda float64) string {
+	return strconv.FormatFloat(lambda, 'g', -1, 64)
+}
+
+// do smooths the series in filename, or every column of cfg.columns of it, with every lambda of cfg and writes its
+// plots and outputs, recording them in the run manifest of cfg.
+func do(filename string, cfg *config) error {
+	basename := inputBase(filename)
+	outDir := cfg.outDir
+	if sub := cfg.subdirs[filename]; sub != "" {
+		outDir = filepath.Join(outDir, sub)
+		if err := os.MkdirAll(outDir, 0o755); err != nil {
+			return err
+		}
+	}
+	status := stdout
+	if cfg.out == stdinName {
+		status = os.Stderr
+	}
+	if cfg.columns == nil {
+		data, err := loadSeries(filename, cfg)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(status, "Working on %s\n", filepath.Join(cfg.subdirs[filename], basename))
+		smooths, outputs, err := smoothInput(data, basename, outDir, status, cfg, true)
+		if err != nil {
+			return err
+		}
+		if cfg.summary {
+			name := filepath.Join(outDir, basename+".summary.json")
+			if err := writeSummary(name, filename, "", data, smooths, outputs, cfg); err != nil {
+				return err
+			}
+			outputs = append(outputs, name)
+		}
+		recordOutputs(cfg, filename, manifestEntries(cfg, filename, smooths, outputs))
+		return nil
+	}
+
+	names, series, err := loadColumns(filename, cfg)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	smooths := make([][]dataio.Smooth, len(series))
+	outputs := make([][]string, len(series))
+	for i, data := range series {
+		name := basename + "-" + names[i]
+		fmt.Fprintf(status, "Working on %s\n", filepath.Join(cfg.subdirs[filename], name))
+		if smooths[i], outputs[i], err = smoothInput(data, name, outDir, status, cfg, !cfg.combineColumns); err != nil {
+			return err
+		}
+	}
+	var combined []string
+	if cfg.combineColumns {
+		if combined, err = plotColumns(basename, outDir, names, series, smooths, cfg); err != nil {
+			return err
+		}
+	}
+	var entries []runmanifest.Entry
+	for i, data := range series {
+		if cfg.summary {
+			name := filepath.Join(outDir, basename+"-"+names[i]+".summary.json")
+			if err := writeSummary(name, filename, names[i], data, smooths[i], append(outputs[i], combined...), cfg); err != nil {
+				return err
+			}
+			outputs[i] = append(outputs[i], name)
+		}
+		entries = append(entries, manifestEntries(cfg, filename, smooths[i], outputs[i])...)
+	}
+	if len(series) > 0 {
+		// the plots of the columns hold every column, so they are recorded with the lambdas of the first
+		entries = append(entries, manifestEntries(cfg, filename, smooths[0], combined)...)
+	}
+	recordOutputs(cfg, filename, entries)
+	return nil
+}
+
+// smoothInput smooths the series data, named basename, with every lambda of cfg, or the lambda chosen for it, and
+// writes its outputs to outDir, its plots only if plots is set. Progress is reported to status. It returns the
+// smooths and the names of the files written.
+func smoothInput(data *dataio.Series, basename, outDir string, status io.Writer, cfg *config, plots bool) ([]dataio.Smooth, []string, error) {
+	orig := logPoints(cfg, makePoints(data.X, data.Y), true)
+	lambdas := cfg.lambdas
+	if cfg.autoLambda {
+		if data.X != nil {
+			return nil, nil, fmt.Errorf("%s: automatic lambda needs evenly spaced values", basename)
+		}
+		if cfg.progress != nil {
+			fmt.Fprintf(cfg.progress, "Choosing lambda for %s: %d values\n", basename, len(data.Y))
+		}
+		search, err := smoother.OptimizeLambda(data.Y, cfg.order, autoLambdaRange[0], autoLambdaRange[1], cfg.criterion)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", basename, err)
+		}
+		// the chosen lambda names the plots, so it is rounded to what the file names show
+		lambda, _ := strconv.ParseFloat(strconv.FormatFloat(search.Lambda, 'g', 4, 64), 64)
+		fmt.Fprintf(status, "Chose lambda %s for %s\n", formatLambda(lambda), basename)
+		lambdas = []float64{lambda}
 	}
-	basename := filepath.Base(filename)
-	fmt.Printf("Working on %s\n", basename)
 
-	// Do 5 iterations of smoothing
-	var cleans [][]float64
-	for _, lambda := range []float64{5, 10, 50, 100, 500} {
-		clean, err := smoother.WESmoother(data, lambda, 2)
+	// Plot every smooth on its own, and collect the lines of the combined plot
+	var combined []interface{}
+	var smooths []dataio.Smooth
+	var outputs []string
+	for k, lambda := range lambdas {
+		if cfg.progress != nil {
+			fmt.Fprintf(cfg.progress, "Smoothing %s: %d values with lambda %s (%d of %d)\n",
+				basename, len(data.Y), formatLambda(lambda), k+1, len(lambdas))
+		}
+		clean, err := smoothSeries(data, lambda, cfg.order)
 		if err != nil {
-			panic(err)
+			return nil, nil, fmt.Errorf("%s, lambda %s: %w", basename, formatLambda(lambda), err)
 		}
-		cleans = append(cleans, clean)
+		smooths = append(smooths, dataio.Smooth{Lambda: lambda, Order: cfg.order, Z: clean})
+		if !plots {
+			continue
+		}
+		line := logPoints(cfg, makePoints(data.X, clean), true)
+		combined = append(combined, "Clean "+formatLambda(lambda), line)
 		p := plot.New()
-		p.Title.Text = fmt.Sprintf("%s: Orig vs. %f Lambda", basename, lambda)
+		title := fmt.Sprintf("%s: Orig vs. %s Lambda", basename, formatLambda(lambda))
+		p.Title.Text = plotTitle(&cfg.style, title, basename, formatLambda(lambda))
 		p.X.Label.Text = "X"
 		p.Y.Label.Text = "Y"
-		err = plotutil.AddLines(
-			p,
-			fmt.Sprintf("Lambda %f", lambda), makePoints(clean),
-			basename, makePoints(data),
+		err = addLines(
+			p, &cfg.style,
+			"Lambda "+formatLambda(lambda), line,
+			basename, orig,
 		)
 		if err != nil {
-			panic(err)
+			return nil, nil, err
 		}
+		styleAxes(cfg, p, true)
 
-		err = p.Save(20*vg.Inch, 10*vg.Inch, fmt.Sprintf("%s-lambda-%d.png", basename, int(lambda)))
-		if err != nil {
-			panic(err)
+		panels := []*plot.Plot{p}
+		if cfg.residuals {
+			r, err := residualPlot(data, clean, cfg)
+			if err != nil {
+				return nil, nil, err
+			}
+			panels = append(panels, r)
+		}
+		if cfg.derivative > 0 {
+			dp, err := derivativePlot(data, clean, cfg.derivative, cfg)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", basename, err)
+			}
+			panels = append(panels, dp)
 		}
+
+		name := filepath.Join(outDir, fmt.Sprintf("%s-lambda-%s.%s", basename, formatLambda(lambda), cfg.format))
+		if err := savePlots(name, cfg, panels...); err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, name)
 	}
 
 	// Make the combined plot file
+	if plots {
+		p := plot.New()
+		names := make([]string, len(lambdas))
+		for i, lambda := range lambdas {
+			names[i] = formatLambda(lambda)
+		}
+		p.Title.Text = plotTitle(&cfg.style, fmt.Sprintf("%s: Orig vs Clean", basename), basename, strings.Join(names, ", "))
+		p.X.Label.Text = "X"
+		p.Y.Label.Text = "Y"
+		if err := addLines(p, &cfg.style, append(combined, basename, orig)...); err != nil {
+			return nil, nil, err
+		}
+		styleAxes(cfg, p, true)
+		name := filepath.Join(outDir, basename+"-combined."+cfg.format)
+		if err := savePlots(name, cfg, p); err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, name)
+	}
+	if cfg.gif {
+		name := filepath.Join(outDir, basename+"-sweep.gif")
+		if err := writeSweepGIF(name, basename, data, smooths, cfg); err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, name)
+	}
+	if cfg.html {
+		name := filepath.Join(outDir, basename+".html")
+		if err := writeHTML(name, basename, data, smooths); err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, name)
+	}
+	if cfg.writeJSON {
+		name := filepath.Join(outDir, basename+".json")
+		if err := writeSmooths(name, dataio.WriteJSON, data, smooths); err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, name)
+	}
+	if cfg.writeArrow {
+		name := filepath.Join(outDir, basename+".arrows")
+		if err := writeSmooths(name, dataio.WriteArrow, data, smooths); err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, name)
+	}
+	if cfg.out != "" {
+		if err := writeSmooths(cfg.out, dataio.WriteCSV, data, smooths); err != nil {
+			return nil, nil, err
+		}
+		if cfg.out != stdinName {
+			outputs = append(outputs, cfg.out)
+		}
+	}
+	return smooths, outputs, nil
+}
+
+// residualPlot returns a plot of the residuals of the smooth z of s around a zero line, styled as cfg asks.
+func residualPlot(s *dataio.Series, z []float64, cfg *config) (*plot.Plot, error) {
+	residuals := make([]float64, len(z))
+	for i := range z {
+		residuals[i] = s.Y[i] - z[i]
+	}
 	p := plot.New()
-	p.Title.Text = fmt.Sprintf("%s: Orig vs Clean", basename)
+	p.Title.Text = "Residuals"
 	p.X.Label.Text = "X"
-	p.Y.Label.Text = "Y"
-	err = plotutil.AddLines(
-		p,
-		"Clean 5", makePoints(cleans[0]),
-		"Clean 10", makePoints(cleans[1]),
-		"Clean 50", makePoints(cleans[2]),
-		"Clean 100", makePoints(cleans[3]),
-		"Clean 500", makePoints(cleans[4]),
-		basename, makePoints(data),
-	)
+	p.Y.Label.Text = "Orig - Clean"
+	zero := plotter.NewFunction(func(float64) float64 { return 0 })
+	zero.Color = color.Gray{Y: 128}
+	p.Add(zero)
+	if err := addLines(p, &cfg.style, "Residual", logPoints(cfg, makePoints(s.X, residuals), false)); err != nil {
+		return nil, err
+	}
+	styleAxes(cfg, p, false)
+	return p, nil
+}
+
+// savePlots writes the plots to the named file in the format and size of cfg, stacked top to bottom with aligned
+// axes when there are several. Every panel below the first adds half the height of the first.
+func savePlots(name string, cfg *config, plots ...*plot.Plot) error {
+	w, h, dpi := cfg.style.size()
+	c, err := newCanvas(w, h+vg.Length(len(plots)-1)*h/2, cfg.format, dpi)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	rows := make([][]*plot.Plot, len(plots))
+	for i, p := range plots {
+		rows[i] = []*plot.Plot{p}
+	}
+	canvases := plot.Align(rows, draw.Tiles{Rows: len(plots), Cols: 1}, draw.New(c))
+	for i, p := range plots {
+		p.Draw(canvases[i][0])
 	}
 
-	err = p.Save(20*vg.Inch, 10*vg.Inch, fmt.Sprintf("%s-combined.png", basename))
+	f, err := os.Create(name)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if _, err := c.WriteTo(f); err != nil {
+		f.Close()
+		return err
 	}
+	return f.Close()
+}
 
+// writeSmooths writes the series s and its smooths to the named file with write, to standard output for
+// stdinName.
+func writeSmooths(name string, write func(io.Writer, *dataio.Series, []dataio.Smooth) error, s *dataio.Series, smooths []dataio.Smooth) error {
+	if name == stdinName {
+		return write(stdout, s, smooths)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f, s, smooths); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
 func main() {
-	do("../../docs/nmr.dat")
-	do("../../docs/wood.txt")
+	os.Exit(run(context.Background(), os.Args[1:], os.Stderr))
+}
+
+// run runs the tool with the command line args, writing errors to stderr, and returns its exit code: 0 on
+// success, 1 when an input fails and 2 for bad flags. With -watch it runs until ctx is done or the tool is
+// interrupted.
+func run(ctx context.Context, args []string, stderr io.Writer) int {
+	cfg, err := parseFlags(args, stderr)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(stderr, err)
+		}
+		return 2
+	}
+	if err := os.MkdirAll(cfg.outDir, 0o755); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if cfg.watch {
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		watch(ctx, cfg, stderr)
+		return 0
+	}
+	for _, input := range cfg.inputs {
+		if err := do(input, cfg); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	if err := writeManifest(cfg); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
 }
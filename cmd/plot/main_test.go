@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseFlags(t *testing.T) {
+	cfg, err := parseFlags([]string{"-lambdas", "1, 1e3,0.5", "-order", "3", "-outdir", "out", "a.dat", "b.dat"}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if len(cfg.lambdas) != 3 || cfg.lambdas[1] != 1000 || cfg.order != 3 || cfg.outDir != "out" || len(cfg.inputs) != 2 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	cfg, err = parseFlags([]string{"a.dat"}, io.Discard)
+	if err != nil || len(cfg.lambdas) != 5 || cfg.order != 2 || cfg.outDir != "." {
+		t.Errorf("unexpected defaults %+v: %v", cfg, err)
+	}
+	if _, err := parseFlags(nil, io.Discard); err == nil {
+		t.Errorf("expected an error without input files")
+	}
+	if _, err := parseFlags([]string{"-lambdas", "1,x", "a.dat"}, io.Discard); err == nil {
+		t.Errorf("expected an error for an invalid lambda")
+	}
+}
+
+func TestDo(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.txt")
+	var lines []string
+	for i := 0; i < 50; i++ {
+		lines = append(lines, strings.Repeat("1", 1+i%3))
+	}
+	if err := os.WriteFile(input, []byte("header\n"+strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := &config{lambdas: []float64{2.5, 100}, order: 2, outDir: dir, format: "png", inputFormat: "auto"}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	for _, name := range []string{"series.txt-lambda-2.5.png", "series.txt-lambda-100.png", "series.txt-combined.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("missing plot %s: %v", name, err)
+		}
+	}
+
+	cfg.order = 60
+	if err := do(input, cfg); err == nil {
+		t.Errorf("expected an error for an order longer than the series")
+	}
+}
+
+func TestParseFlagsStdin(t *testing.T) {
+	if _, err := parseFlags([]string{"-", "a.dat"}, io.Discard); err != nil {
+		t.Errorf("got %v for reading standard input once", err)
+	}
+	if _, err := parseFlags([]string{"-", "-"}, io.Discard); err == nil {
+		t.Errorf("expected an error for reading standard input twice")
+	}
+}
+
+func TestDoJSON(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.json")
+	if err := os.WriteFile(input, []byte(`{"x": [0, 1, 2, 4, 5, 6], "y": [1, 3, 2, 5, 4, 6]}`), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg, err := parseFlags([]string{"-json", "-lambdas", "10", "-outdir", dir, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "series.json.json"))
+	if err != nil {
+		t.Fatalf("missing json output: %v", err)
+	}
+	defer f.Close()
+	var out struct {
+		X, Y    []float64
+		Smooths []struct {
+			Lambda float64
+			Order  int
+			Z      []float64
+		}
+	}
+	if err := json.NewDecoder(f).Decode(&out); err != nil {
+		t.Fatalf("Failed to decode the output: %v", err)
+	}
+	if len(out.X) != 6 || out.X[3] != 4 || len(out.Smooths) != 1 || out.Smooths[0].Lambda != 10 ||
+		out.Smooths[0].Order != 2 || len(out.Smooths[0].Z) != 6 {
+		t.Errorf("unexpected output %+v", out)
+	}
+}
+
+func TestDoArrow(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.json")
+	if err := os.WriteFile(input, []byte(`[1, 3, 2, 5, 4, 6, 5, 7]`), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg, err := parseFlags([]string{"-arrow", "-lambdas", "10,100", "-outdir", dir, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+
+	// the output is an input of the tool again
+	output := filepath.Join(dir, "series.json.arrows")
+	cfg = &config{inputFormat: "auto", yColumn: "smooth_100"}
+	s, err := loadSeries(output, cfg)
+	if err != nil {
+		t.Fatalf("Failed to read the arrow output: %v", err)
+	}
+	if s.X != nil || len(s.Y) != 8 {
+		t.Errorf("got x %v and y %v", s.X, s.Y)
+	}
+}
+
+func TestDoOut(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.txt")
+	if err := os.WriteFile(input, []byte("1\n3\n2\n5\n4\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	out := filepath.Join(dir, "smoothed.csv")
+	cfg, err := parseFlags([]string{"-lambdas", "10", "-outdir", dir, "-out", out, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("missing csv output: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 6 || lines[0] != "index,original,smoothed,residual" || !strings.HasPrefix(lines[2], "1,3,") {
+		t.Errorf("unexpected output\n%s", data)
+	}
+
+	defer func() { stdout = os.Stdout }()
+	var buf strings.Builder
+	stdout = &buf
+	cfg.out, cfg.lambdas = stdinName, []float64{1, 100}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "index,original,smoothed_1,residual_1,smoothed_100,residual_100\n") {
+		t.Errorf("unexpected standard output\n%s", buf.String())
+	}
+
+	if _, err := parseFlags([]string{"-out", out, "a.dat", "b.dat"}, io.Discard); err == nil {
+		t.Errorf("expected an error for -out with two inputs")
+	}
+}
+
+func TestDoWeights(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.csv")
+	if err := os.WriteFile(input, []byte("v,w\n1,1\n3,1\n100,0\n5,1\n4,1\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	out := filepath.Join(dir, "smoothed.csv")
+	cfg, err := parseFlags([]string{"-y", "v", "-weights", "w", "-order", "1", "-lambdas", "1e-6", "-outdir", dir, "-out", out, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("missing csv output: %v", err)
+	}
+	// a value of zero weight is interpolated instead of fitted
+	fields := strings.Split(strings.Split(string(data), "\n")[3], ",")
+	if z, err := strconv.ParseFloat(fields[2], 64); err != nil || math.Abs(z-4) > 0.01 {
+		t.Errorf("got smooth %s for the value of zero weight, want about 4", fields[2])
+	}
+
+	for _, args := range [][]string{
+		{"-weights", "w", "-x", "t", "a.csv"},
+		{"-weights", "w", "-auto-lambda", "gcv", "a.csv"},
+	} {
+		if _, err := parseFlags(args, io.Discard); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+func TestDoFormat(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.txt")
+	if err := os.WriteFile(input, []byte("1\n3\n2\n5\n4\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	for format, magic := range map[string]string{"svg": "<svg", "pdf": "%PDF", "eps": "EPSF"} {
+		cfg, err := parseFlags([]string{"-format", format, "-lambdas", "10", "-outdir", dir, input}, io.Discard)
+		if err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		if err := do(input, cfg); err != nil {
+			t.Fatalf("%s: Failed to plot: %v", format, err)
+		}
+		for _, name := range []string{"series.txt-lambda-10." + format, "series.txt-combined." + format} {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Errorf("missing plot %s: %v", name, err)
+				continue
+			}
+			if head := string(data[:min(len(data), 256)]); !strings.Contains(head, magic) {
+				t.Errorf("%s lacks %q in its header", name, magic)
+			}
+		}
+	}
+	if _, err := parseFlags([]string{"-format", "bmp", input}, io.Discard); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestDoAutoLambda(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.txt")
+	var lines []string
+	for i := 0; i < 200; i++ {
+		lines = append(lines, strconv.FormatFloat(math.Sin(float64(i)/20)+0.2*math.Sin(float64(i)*2.7), 'g', -1, 64))
+	}
+	if err := os.WriteFile(input, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	defer func() { stdout = os.Stdout }()
+	for _, crit := range []string{"cv", "gcv", "vcurve"} {
+		var buf strings.Builder
+		stdout = &buf
+		out := filepath.Join(dir, crit+".csv")
+		cfg, err := parseFlags([]string{"-auto-lambda", crit, "-outdir", dir, "-out", out, input}, io.Discard)
+		if err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		if err := do(input, cfg); err != nil {
+			t.Fatalf("%s: Failed to plot: %v", crit, err)
+		}
+		var lambda string
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if rest, ok := strings.CutPrefix(line, "Chose lambda "); ok {
+				lambda, _, _ = strings.Cut(rest, " ")
+			}
+		}
+		if lambda == "" {
+			t.Fatalf("%s: the chosen lambda was not printed:\n%s", crit, buf.String())
+		}
+		if _, err := os.Stat(filepath.Join(dir, "series.txt-lambda-"+lambda+".png")); err != nil {
+			t.Errorf("%s: missing plot of the chosen lambda %s: %v", crit, lambda, err)
+		}
+	}
+
+	if _, err := parseFlags([]string{"-auto-lambda", "aic", input}, io.Discard); err == nil {
+		t.Errorf("expected an error for an unknown criterion")
+	}
+	if _, err := parseFlags([]string{"-auto-lambda", "cv", "-x", "t", input}, io.Discard); err == nil {
+		t.Errorf("expected an error for automatic lambda with positions")
+	}
+}
+
+func TestDoResiduals(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.txt")
+	if err := os.WriteFile(input, []byte("1\n3\n2\n5\n4\n6\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg, err := parseFlags([]string{"-residuals", "-lambdas", "10", "-outdir", dir, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	f, err := os.Open(filepath.Join(dir, "series.txt-lambda-10.png"))
+	if err != nil {
+		t.Fatalf("missing plot: %v", err)
+	}
+	defer f.Close()
+	img, err := png.DecodeConfig(f)
+	if err != nil {
+		t.Fatalf("Failed to decode the plot: %v", err)
+	}
+	// the residual panel adds half the height of the main plot
+	if img.Height*4 != img.Width*3 {
+		t.Errorf("plot is %d x %d, want a residual panel below it", img.Width, img.Height)
+	}
+}
+
+func TestParseLambdaRange(t *testing.T) {
+	cfg, err := parseFlags([]string{"-lambda-range", "1:1e4:5", "a.dat"}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	want := []float64{1, 10, 100, 1000, 10000}
+	if len(cfg.lambdas) != len(want) {
+		t.Fatalf("got lambdas %v, want %v", cfg.lambdas, want)
+	}
+	for k := range want {
+		if cfg.lambdas[k] != want[k] {
+			t.Errorf("got lambdas %v, want %v", cfg.lambdas, want)
+			break
+		}
+	}
+	if lambdas, err := parseLambdaRange("0.5:0.5:1"); err != nil || len(lambdas) != 1 || lambdas[0] != 0.5 {
+		t.Errorf("got %v and %v for a single lambda", lambdas, err)
+	}
+	if lambdas, err := parseLambdaRange("2:50:3"); err != nil || lambdas[1] != 10 {
+		t.Errorf("got %v and %v, want 10 between 2 and 50", lambdas, err)
+	}
+
+	for _, r := range []string{"1:10", "0:10:3", "10:1:3", "1:10:1", "1:x:3", "1:10:0"} {
+		if _, err := parseLambdaRange(r); err == nil {
+			t.Errorf("%q: expected an error", r)
+		}
+	}
+	if _, err := parseFlags([]string{"-lambdas", "1,2", "-lambda-range", "1:10:2", "a.dat"}, io.Discard); err == nil {
+		t.Errorf("expected an error for both -lambdas and -lambda-range")
+	}
+}
+
+func TestRun(t *testing.T) {
+	defer func(w io.Writer) { stdout = w }(stdout)
+	stdout = io.Discard
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.txt")
+	if err := os.WriteFile(input, []byte("1\n3\n2\n5\n4\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	out := filepath.Join(dir, "out")
+	var stderr strings.Builder
+	if code := run(context.Background(), []string{"-lambdas", "10", "-outdir", out, input}, &stderr); code != 0 {
+		t.Fatalf("got exit code %d, errors: %s", code, stderr.String())
+	}
+	for _, name := range []string{"series.txt-lambda-10.png", "manifest.json"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("missing output %s: %v", name, err)
+		}
+	}
+
+	if code := run(context.Background(), []string{"-lambdas", "x", input}, io.Discard); code != 2 {
+		t.Errorf("got exit code %d for an invalid lambda, want 2", code)
+	}
+	if code := run(context.Background(), []string{"-outdir", out, filepath.Join(dir, "missing.txt")}, io.Discard); code != 1 {
+		t.Errorf("got exit code %d for a missing input, want 1", code)
+	}
+}
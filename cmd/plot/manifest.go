@@ -0,0 +1,52 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/grutz/go-whittaker-eilers/dataio"
+	"github.com/grutz/go-whittaker-eilers/internal/runmanifest"
+)
+
+// manifestEntries returns the manifest entries of the outputs written for the named input from its smooths, one
+// per output below the output directory of cfg. Outputs elsewhere, such as a csv file of -out in another
+// directory, are not part of the run and are left out.
+func manifestEntries(cfg *config, input string, smooths []dataio.Smooth, outputs []string) []runmanifest.Entry {
+	lambdas := make([]float64, len(smooths))
+	for i, sm := range smooths {
+		lambdas[i] = sm.Lambda
+	}
+	var entries []runmanifest.Entry
+	for _, output := range outputs {
+		rel, err := filepath.Rel(cfg.outDir, output)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		entries = append(entries, runmanifest.Entry{Input: input, Output: filepath.ToSlash(rel), Lambdas: lambdas, Order: cfg.order})
+	}
+	return entries
+}
+
+// recordOutputs replaces the entries of the named input in the manifest of cfg, if it has one, by entries, so an
+// input smoothed again by -watch is recorded once.
+func recordOutputs(cfg *config, input string, entries []runmanifest.Entry) {
+	if cfg.manifest == nil {
+		return
+	}
+	kept := cfg.manifest.Entries[:0]
+	for _, e := range cfg.manifest.Entries {
+		if e.Input != input {
+			kept = append(kept, e)
+		}
+	}
+	cfg.manifest.Entries = append(kept, entries...)
+}
+
+// writeManifest writes the manifest of cfg, if it has one, to its output directory, so cmd/we diff can compare
+// the run with another.
+func writeManifest(cfg *config) error {
+	if cfg.manifest == nil {
+		return nil
+	}
+	return runmanifest.Write(cfg.outDir, cfg.manifest)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/grutz/go-whittaker-eilers/internal/runmanifest"
+)
+
+func TestDoManifest(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/x.dat", "b/x.dat"} {
+		path := filepath.Join(dir, "in", filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("1\n3\n2\n5\n4\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(dir, "out")
+	cfg, err := parseFlags([]string{"-lambdas", "10,100", "-summary", "-outdir", out, filepath.Join(dir, "in")}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// the first input is smoothed twice, as by -watch, and must be recorded once
+	for _, input := range append(cfg.inputs, cfg.inputs[0]) {
+		if err := do(input, cfg); err != nil {
+			t.Fatalf("Failed to plot: %v", err)
+		}
+	}
+	if err := writeManifest(cfg); err != nil {
+		t.Fatalf("Failed to write the manifest: %v", err)
+	}
+
+	m, err := runmanifest.Read(out)
+	if err != nil {
+		t.Fatalf("Failed to read the manifest: %v", err)
+	}
+	if m.Tool != "plot" {
+		t.Errorf("got tool %q", m.Tool)
+	}
+	var outputs []string
+	for _, e := range m.Entries {
+		outputs = append(outputs, e.Output)
+		if !reflect.DeepEqual(e.Lambdas, []float64{10, 100}) || e.Order != 2 {
+			t.Errorf("%s: got lambdas %v and order %d", e.Output, e.Lambdas, e.Order)
+		}
+		if _, err := os.Stat(filepath.Join(out, filepath.FromSlash(e.Output))); err != nil {
+			t.Errorf("%s: recorded output is missing: %v", e.Output, err)
+		}
+	}
+	sort.Strings(outputs)
+	want := []string{
+		"a/x.dat-combined.png", "a/x.dat-lambda-10.png", "a/x.dat-lambda-100.png", "a/x.dat.summary.json",
+		"b/x.dat-combined.png", "b/x.dat-lambda-10.png", "b/x.dat-lambda-100.png", "b/x.dat.summary.json",
+	}
+	if !reflect.DeepEqual(outputs, want) {
+		t.Errorf("got outputs %v, want %v", outputs, want)
+	}
+}
+
+func TestManifestEntriesOutside(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config{outDir: filepath.Join(dir, "out"), order: 2}
+	entries := manifestEntries(cfg, "a.dat", nil, []string{filepath.Join(dir, "out", "a.png"), filepath.Join(dir, "a.csv"), "-"})
+	if len(entries) != 1 || entries[0].Output != "a.png" {
+		t.Errorf("got entries %+v, want only a.png", entries)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// progressInterval is the least time between two status lines of reading an input, replaced in tests.
+var progressInterval = time.Second
+
+// progress counts the bytes read of an input and writes a status line of them every progressInterval, so reading
+// a large input shows it is under way.
+type progress struct {
+	w    io.Writer
+	name string
+	// total is the size of the input, negative if it is not known.
+	total int64
+
+	mu         sync.Mutex
+	read       int64
+	start, due time.Time
+}
+
+// newProgress returns the progress of reading the named input of the given size, or of unknown size if negative,
+// written to w.
+func newProgress(w io.Writer, name string, total int64) *progress {
+	now := time.Now()
+	return &progress{w: w, name: name, total: total, start: now, due: now.Add(progressInterval)}
+}
+
+// add counts n more bytes read and writes a status line if one is due. Parquet inputs are read at offsets by
+// several goroutines, so it may be called concurrently.
+func (p *progress) add(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.read += int64(n)
+	if now := time.Now(); !now.Before(p.due) {
+		p.due = now.Add(progressInterval)
+		fmt.Fprintf(p.w, "Reading %s: %s\n", p.name, p.status())
+	}
+}
+
+// done writes the final status line, of the bytes read and the time it took.
+func (p *progress) done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.w, "Read %s: %s in %s\n", p.name, formatBytes(p.read), time.Since(p.start).Round(time.Millisecond))
+}
+
+// status returns the bytes read so far, out of the size and as a percentage of it when that is known.
+func (p *progress) status() string {
+	if p.total <= 0 {
+		return formatBytes(p.read)
+	}
+	return fmt.Sprintf("%s of %s (%d%%)", formatBytes(p.read), formatBytes(p.total), 100*p.read/p.total)
+}
+
+// progressReader reports the progress of reading a stream, such as standard input or the body of a response.
+type progressReader struct {
+	io.ReadCloser
+	p *progress
+}
+
+// Read implements io.Reader.
+func (r progressReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	r.p.add(n)
+	return n, err
+}
+
+// Close implements io.Closer.
+func (r progressReader) Close() error {
+	r.p.done()
+	return r.ReadCloser.Close()
+}
+
+// progressFile reports the progress of reading a file. It keeps the random access of the file, which decompress
+// and parquet inputs rely on.
+type progressFile struct {
+	*os.File
+	p *progress
+}
+
+// Read implements io.Reader.
+func (f progressFile) Read(b []byte) (int, error) {
+	n, err := f.File.Read(b)
+	f.p.add(n)
+	return n, err
+}
+
+// ReadAt implements io.ReaderAt.
+func (f progressFile) ReadAt(b []byte, off int64) (int, error) {
+	n, err := f.File.ReadAt(b, off)
+	f.p.add(n)
+	return n, err
+}
+
+// Close implements io.Closer.
+func (f progressFile) Close() error {
+	f.p.done()
+	return f.File.Close()
+}
+
+// formatBytes formats a number of bytes in decimal units, such as 12.5 MB.
+func formatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	v, prefix := float64(n)/unit, 0
+	for ; v >= unit && prefix < 3; prefix++ {
+		v /= unit
+	}
+	return fmt.Sprintf("%.1f %cB", v, "kMGT"[prefix])
+}
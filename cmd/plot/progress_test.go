@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{0: "0 B", 999: "999 B", 1000: "1.0 kB", 12_500_000: "12.5 MB", 3e12: "3.0 TB", 4e15: "4000.0 TB"} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestDoProgress(t *testing.T) {
+	defer func(d time.Duration) { progressInterval = d }(progressInterval)
+	progressInterval = 0
+	defer func(w io.Writer) { stdout = w }(stdout)
+	stdout = io.Discard
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "long.txt")
+	if err := os.WriteFile(input, bytes.Repeat([]byte("1\n3\n2\n"), 500), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	var status bytes.Buffer
+	cfg, err := parseFlags([]string{"-progress", "-format", "svg", "-lambdas", "1,10", "-outdir", dir, input}, &status)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	for _, want := range []string{
+		"Reading " + input + ": ",
+		"of 3.0 kB (100%)\n",
+		"Read " + input + ": 3.0 kB in ",
+		"Smoothing long.txt: 1500 values with lambda 1 (1 of 2)\n",
+		"Smoothing long.txt: 1500 values with lambda 10 (2 of 2)\n",
+	} {
+		if !strings.Contains(status.String(), want) {
+			t.Errorf("missing status %q in %q", want, status.String())
+		}
+	}
+
+	status.Reset()
+	stdin = strings.NewReader("1\n2\n")
+	defer func() { stdin = os.Stdin }()
+	if _, err := loadSeries(stdinName, cfg); err != nil {
+		t.Fatalf("Failed to read standard input: %v", err)
+	}
+	if got := status.String(); !strings.Contains(got, "Reading stdin: 4 B\n") || !strings.Contains(got, "Read stdin: 4 B in ") {
+		t.Errorf("got status %q for standard input", got)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/colornames"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// Default size and resolution of the plots.
+const (
+	defaultWidth  = 20 * vg.Inch
+	defaultHeight = 10 * vg.Inch
+	defaultDPI    = 96
+)
+
+// style holds the appearance of the plots.
+type style struct {
+	// width and height are the size of a plot, of its first panel when it has several, and dpi the resolution of
+	// png, jpg and tiff images, zero for the defaults.
+	width, height vg.Length
+	dpi           int
+	// lineWidth is the width of the lines, zero for the default of gonum/plot.
+	lineWidth vg.Length
+	// colors are the colors of the lines in turn, nil for those of plotutil.
+	colors []color.Color
+	// title replaces the titles of the plots of the series and xLabel and yLabel the labels of their axes, unless
+	// they are empty.
+	title, xLabel, yLabel string
+}
+
+// size returns the size and resolution of the plots, replacing zero values by the defaults.
+func (st *style) size() (w, h vg.Length, dpi int) {
+	w, h, dpi = st.width, st.height, st.dpi
+	if w == 0 {
+		w = defaultWidth
+	}
+	if h == 0 {
+		h = defaultHeight
+	}
+	if dpi == 0 {
+		dpi = defaultDPI
+	}
+	return w, h, dpi
+}
+
+// parseColors parses a comma separated list of colors, each an SVG color name such as steelblue or a hex code
+// #rgb or #rrggbb.
+func parseColors(s string) ([]color.Color, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var colors []color.Color
+	for _, field := range strings.Split(s, ",") {
+		name := strings.ToLower(strings.TrimSpace(field))
+		if c, ok := colornames.Map[name]; ok {
+			colors = append(colors, c)
+			continue
+		}
+		hex := strings.TrimPrefix(name, "#")
+		if hex == name || (len(hex) != 3 && len(hex) != 6) {
+			return nil, fmt.Errorf("invalid color %q, want a color name or #rgb or #rrggbb", field)
+		}
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid color %q, want a color name or #rgb or #rrggbb", field)
+		}
+		colors = append(colors, color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255})
+	}
+	return colors, nil
+}
+
+// plotTitle returns the title of a plot of the series: auto, or the title of the style with {input} replaced by
+// the name of the input and {lambda} by lambdas.
+func plotTitle(st *style, auto, input, lambdas string) string {
+	if st.title == "" {
+		return auto
+	}
+	return strings.NewReplacer("{input}", input, "{lambda}", lambdas).Replace(st.title)
+}
+
+// addLines adds a line to p for every plotter.XYer of vs, with a legend entry named by the string before it if
+// there is one, like plotutil.AddLines but in the colors and line width of the style.
+func addLines(p *plot.Plot, st *style, vs ...interface{}) error {
+	var lines []plot.Plotter
+	name := ""
+	for _, v := range vs {
+		switch v := v.(type) {
+		case string:
+			name = v
+		case plotter.XYer:
+			l, err := plotter.NewLine(v)
+			if err != nil {
+				return err
+			}
+			i := len(lines)
+			l.Color = plotutil.Color(i)
+			if len(st.colors) > 0 {
+				l.Color = st.colors[i%len(st.colors)]
+			}
+			l.Dashes = plotutil.Dashes(i)
+			if st.lineWidth > 0 {
+				l.Width = st.lineWidth
+			}
+			lines = append(lines, l)
+			if name != "" {
+				p.Legend.Add(name, l)
+				name = ""
+			}
+		default:
+			return fmt.Errorf("cannot plot a %T as a line", v)
+		}
+	}
+	p.Add(lines...)
+	return nil
+}
+
+// newCanvas returns a canvas of the given size for an image in format, with the resolution dpi for png, jpg and
+// tiff images.
+func newCanvas(w, h vg.Length, format string, dpi int) (vg.CanvasWriterTo, error) {
+	img := func() *vgimg.Canvas { return vgimg.NewWith(vgimg.UseWH(w, h), vgimg.UseDPI(dpi)) }
+	switch format {
+	case "png":
+		return vgimg.PngCanvas{Canvas: img()}, nil
+	case "jpg", "jpeg":
+		return vgimg.JpegCanvas{Canvas: img()}, nil
+	case "tif", "tiff":
+		return vgimg.TiffCanvas{Canvas: img()}, nil
+	}
+	return draw.NewFormattedCanvas(w, h, format)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseColors(t *testing.T) {
+	colors, err := parseColors("steelblue, #f00,#00800A")
+	if err != nil {
+		t.Fatalf("Failed to parse colors: %v", err)
+	}
+	want := []color.Color{color.RGBA{70, 130, 180, 255}, color.RGBA{255, 0, 0, 255}, color.RGBA{0, 128, 10, 255}}
+	if len(colors) != len(want) {
+		t.Fatalf("got %v, want %v", colors, want)
+	}
+	for i := range want {
+		if colors[i] != want[i] {
+			t.Errorf("color %d is %v, want %v", i, colors[i], want[i])
+		}
+	}
+	if colors, err := parseColors(""); err != nil || colors != nil {
+		t.Errorf("got %v and %v for no colors", colors, err)
+	}
+	for _, s := range []string{"bluish", "#12345", "#ggg", "f00"} {
+		if _, err := parseColors(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestPlotTitle(t *testing.T) {
+	if got := plotTitle(&style{}, "auto", "a.csv", "10"); got != "auto" {
+		t.Errorf("got %q without a title", got)
+	}
+	if got := plotTitle(&style{title: "{input} at {lambda}"}, "auto", "a.csv", "10, 100"); got != "a.csv at 10, 100" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestDoStyle(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.txt")
+	if err := os.WriteFile(input, []byte("1\n3\n2\n5\n4\n6\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	args := []string{"-width", "4", "-height", "2", "-dpi", "50", "-line-width", "2.5", "-colors", "red,#0000ff",
+		"-title", "{input}", "-xlabel", "Day", "-ylabel", "Count", "-residuals", "-lambdas", "10", "-outdir", dir, input}
+	cfg, err := parseFlags(args, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	for name, height := range map[string]int{"series.txt-combined.png": 100, "series.txt-lambda-10.png": 150} {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("missing plot: %v", err)
+		}
+		img, err := png.DecodeConfig(f)
+		f.Close()
+		if err != nil || img.Width != 200 || img.Height != height {
+			t.Errorf("%s is %d x %d (%v), want 200 x %d", name, img.Width, img.Height, err, height)
+		}
+	}
+
+	for _, flag := range []string{"-width", "-height", "-dpi", "-line-width"} {
+		if _, err := parseFlags([]string{flag, "0", "a.dat"}, io.Discard); err == nil {
+			t.Errorf("expected an error for %s 0", flag)
+		}
+	}
+	if _, err := parseFlags([]string{"-colors", "nope", "a.dat"}, io.Discard); err == nil {
+		t.Errorf("expected an error for an invalid color")
+	}
+}
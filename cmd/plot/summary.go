@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+
+	smoother "github.com/grutz/go-whittaker-eilers"
+	"github.com/grutz/go-whittaker-eilers/dataio"
+)
+
+// summary is the JSON summary of -summary of the smooths of an input, or of one column of it with -columns, for
+// pipelines that consume the results of a batch run.
+type summary struct {
+	Input  string `json:"input"`
+	Column string `json:"column,omitempty"`
+	// N is the number of values of the series.
+	N       int             `json:"n"`
+	Order   int             `json:"order"`
+	Smooths []smoothSummary `json:"smooths"`
+	// Outputs holds the names of the files written for the series.
+	Outputs []string `json:"outputs"`
+}
+
+// smoothSummary summarizes the smooth of a series for one lambda.
+type smoothSummary struct {
+	Lambda float64 `json:"lambda"`
+	// RMSE is the root mean square of the residuals, leaving out the values that are not finite.
+	RMSE float64 `json:"rmse"`
+	// Roughness is the roughness of the smooth, the sum of its squared differences of the order of the penalty.
+	Roughness float64 `json:"roughness"`
+	// EffectiveDF is the trace of the hat matrix, only known for evenly spaced, unweighted series and left out if it
+	// cannot be computed.
+	EffectiveDF *float64 `json:"effective_df,omitempty"`
+}
+
+// writeSummary writes the summary of the smooths of the series s of the named input, or of its named column, and
+// of the outputs written for it to the file name.
+func writeSummary(name, input, column string, s *dataio.Series, smooths []dataio.Smooth, outputs []string, cfg *config) error {
+	sum := summary{Input: input, Column: column, N: len(s.Y), Order: cfg.order, Outputs: outputs}
+	if sum.Outputs == nil {
+		sum.Outputs = []string{}
+	}
+	for _, sm := range smooths {
+		ss := smoothSummary{Lambda: sm.Lambda, RMSE: rmse(s.Y, sm.Z), Roughness: smoother.Roughness(sm.Z, sm.Order)}
+		// the trace comes from the band factor in O(n·d²), an input it fails for only loses the field
+		if s.X == nil && s.W == nil {
+			if df, err := smoother.EffectiveDF(sm.Lambda, sm.Order, len(s.Y)); err == nil {
+				ss.EffectiveDF = &df
+			}
+		}
+		sum.Smooths = append(sum.Smooths, ss)
+	}
+
+	data, err := json.MarshalIndent(sum, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(data, '\n'), 0o644)
+}
+
+// rmse returns the root mean square of the residuals y - z, over the finite ones, or zero if there are none.
+func rmse(y, z []float64) float64 {
+	var sum float64
+	n := 0
+	for i := range y {
+		if r := y[i] - z[i]; !math.IsNaN(r) && !math.IsInf(r, 0) {
+			sum += r * r
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return math.Sqrt(sum / float64(n))
+}
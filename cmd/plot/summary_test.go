@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grutz/go-whittaker-eilers/dataio"
+)
+
+func TestDoSummary(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "series.txt")
+	if err := os.WriteFile(input, []byte("1\n3\n2\n5\n4\n6\n5\n7\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg, err := parseFlags([]string{"-summary", "-json", "-format", "svg", "-lambdas", "1,100", "-outdir", dir, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(input, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "series.txt.summary.json"))
+	if err != nil {
+		t.Fatalf("missing summary: %v", err)
+	}
+	var sum summary
+	if err := json.Unmarshal(data, &sum); err != nil {
+		t.Fatalf("Failed to decode the summary: %v", err)
+	}
+	if sum.Input != input || sum.N != 8 || sum.Order != 2 || len(sum.Smooths) != 2 {
+		t.Fatalf("got summary %+v", sum)
+	}
+	low, high := sum.Smooths[0], sum.Smooths[1]
+	if low.Lambda != 1 || high.Lambda != 100 || !(low.RMSE > 0) || !(high.RMSE > low.RMSE) || !(high.Roughness < low.Roughness) {
+		t.Errorf("got smooths %+v and %+v, want more smoothing for the larger lambda", low, high)
+	}
+	if low.EffectiveDF == nil || high.EffectiveDF == nil || !(*high.EffectiveDF < *low.EffectiveDF) || !(*high.EffectiveDF > 2) {
+		t.Errorf("got effective degrees of freedom %v and %v", low.EffectiveDF, high.EffectiveDF)
+	}
+	want := []string{"series.txt-lambda-1.svg", "series.txt-lambda-100.svg", "series.txt-combined.svg", "series.txt.json"}
+	if len(sum.Outputs) != len(want) {
+		t.Fatalf("got outputs %v, want %v", sum.Outputs, want)
+	}
+	for i, name := range want {
+		if sum.Outputs[i] != filepath.Join(dir, name) {
+			t.Errorf("got output %s, want %s", sum.Outputs[i], filepath.Join(dir, name))
+		}
+	}
+
+	csv := filepath.Join(dir, "pair.csv")
+	if err := os.WriteFile(csv, []byte("t,a,b\n0,1,2\n1,3,1\n3,2,4\n4,5,3\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	cfg, err = parseFlags([]string{"-summary", "-columns", "all", "-combine-columns", "-x", "t", "-format", "svg", "-lambdas", "10", "-outdir", dir, csv}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := do(csv, cfg); err != nil {
+		t.Fatalf("Failed to plot: %v", err)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "pair.csv-b.summary.json"))
+	if err != nil {
+		t.Fatalf("missing summary of a column: %v", err)
+	}
+	sum = summary{}
+	if err := json.Unmarshal(data, &sum); err != nil {
+		t.Fatalf("Failed to decode the summary: %v", err)
+	}
+	if sum.Column != "b" || sum.N != 4 || len(sum.Smooths) != 1 || sum.Smooths[0].EffectiveDF != nil {
+		t.Errorf("got summary %+v of a column with positions", sum)
+	}
+	if len(sum.Outputs) != 1 || sum.Outputs[0] != filepath.Join(dir, "pair.csv-columns-lambda-10.svg") {
+		t.Errorf("got outputs %v, want the plot of the columns", sum.Outputs)
+	}
+}
+
+func TestRMSE(t *testing.T) {
+	if got := rmse([]float64{1, 2, math.NaN(), 4}, []float64{0, 2, 3, 6}); math.Abs(got-math.Sqrt(5.0/3)) > 1e-12 {
+		t.Errorf("got %g, want the root mean square of the finite residuals", got)
+	}
+	if got := rmse([]float64{math.NaN()}, []float64{1}); got != 0 {
+		t.Errorf("got %g without finite residuals, want 0", got)
+	}
+}
+
+func TestWriteSummaryLong(t *testing.T) {
+	n := 2_000_000
+	s := &dataio.Series{Y: make([]float64, n)}
+	smooths := []dataio.Smooth{{Lambda: 1e4, Order: 2, Z: make([]float64, n)}}
+	name := filepath.Join(t.TempDir(), "long.summary.json")
+	if err := writeSummary(name, "long.txt", "", s, smooths, nil, &config{order: 2}); err != nil {
+		t.Fatalf("Failed to write the summary of %d values: %v", n, err)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("missing summary: %v", err)
+	}
+	var sum summary
+	if err := json.Unmarshal(data, &sum); err != nil {
+		t.Fatalf("Failed to decode the summary: %v", err)
+	}
+	if sum.N != n || len(sum.Smooths) != 1 || sum.Smooths[0].EffectiveDF == nil || !(*sum.Smooths[0].EffectiveDF > 2) {
+		t.Errorf("got summary %+v", sum)
+	}
+}
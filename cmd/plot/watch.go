@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// watchInterval is the time between two looks of -watch at its inputs, replaced in tests.
+var watchInterval = 500 * time.Millisecond
+
+// fileState is what -watch compares to tell that a file changed.
+type fileState struct {
+	size int64
+	// modTime is the modification time in nanoseconds since the Unix epoch.
+	modTime int64
+}
+
+// watch smooths and plots every input of cfg, then again every time it changes, until ctx is done. The inputs are
+// polled every watchInterval for their size and modification time, which also catches files that are appended to
+// or replaced, and one that is missing is tried again once it exists. The errors of a run are written to stderr
+// rather than ending the watch, as a file in the middle of being written may fail to read. The run manifest is
+// written again after every successful run.
+func watch(ctx context.Context, cfg *config, stderr io.Writer) {
+	states := make(map[string]fileState, len(cfg.inputs))
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		for _, input := range cfg.inputs {
+			info, err := os.Stat(input)
+			if err != nil {
+				// reported once, the zero state stands for a missing file
+				if last, ok := states[input]; !ok || last != (fileState{}) {
+					fmt.Fprintln(stderr, err)
+					states[input] = fileState{}
+				}
+				continue
+			}
+			state := fileState{size: info.Size(), modTime: info.ModTime().UnixNano()}
+			if last, ok := states[input]; ok && last == state {
+				continue
+			}
+			states[input] = state
+			if err := do(input, cfg); err != nil {
+				fmt.Fprintln(stderr, err)
+				continue
+			}
+			if err := writeManifest(cfg); err != nil {
+				fmt.Fprintln(stderr, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a bytes.Buffer that the watch and the test can use at once.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write implements io.Writer.
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// String returns the contents written so far.
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatch(t *testing.T) {
+	defer func(d time.Duration) { watchInterval = d }(watchInterval)
+	watchInterval = 10 * time.Millisecond
+	defer func(w io.Writer) { stdout = w }(stdout)
+	stdout = io.Discard
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "live.txt")
+	cfg, err := parseFlags([]string{"-watch", "-json", "-lambdas", "10", "-outdir", dir, input}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if !cfg.watch {
+		t.Fatalf("-watch did not set up a watch")
+	}
+	output := filepath.Join(dir, "live.txt.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var stderr lockedBuffer
+	done := make(chan struct{})
+	go func() {
+		watch(ctx, cfg, &stderr)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// waitFor waits until the output holds n values.
+	waitFor := func(n int) {
+		t.Helper()
+		want := `"y":[` + strings.TrimSuffix(strings.Repeat("1,", n), ",") + "]"
+		for deadline := time.Now().Add(30 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if data, err := os.ReadFile(output); err == nil && strings.Contains(strings.ReplaceAll(string(data), " ", ""), want) {
+				return
+			}
+		}
+		data, _ := os.ReadFile(output)
+		t.Fatalf("the output never held %d values, errors: %s, output %s", n, stderr.String(), data)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if !strings.Contains(stderr.String(), "live.txt") {
+		t.Errorf("missing input not reported, got %q", stderr.String())
+	}
+	if err := os.WriteFile(input, []byte("1\n1\n1\n1\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	waitFor(4)
+	f, err := os.OpenFile(input, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Failed to open input: %v", err)
+	}
+	if _, err := f.WriteString("1\n1\n"); err != nil {
+		t.Fatalf("Failed to append to input: %v", err)
+	}
+	f.Close()
+	waitFor(6)
+
+	if _, err := parseFlags([]string{"-watch", "-"}, io.Discard); err == nil {
+		t.Errorf("expected an error for watching standard input")
+	}
+}
+
+func TestRunWatch(t *testing.T) {
+	defer func(d time.Duration) { watchInterval = d }(watchInterval)
+	watchInterval = 10 * time.Millisecond
+	defer func(w io.Writer) { stdout = w }(stdout)
+	stdout = io.Discard
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "live.txt")
+	if err := os.WriteFile(input, []byte("1\n1\n1\n1\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var stderr lockedBuffer
+	code := make(chan int)
+	go func() {
+		code <- run(ctx, []string{"-watch", "-lambdas", "10", "-outdir", dir, input}, &stderr)
+	}()
+
+	manifest := filepath.Join(dir, "manifest.json")
+	for deadline := time.Now().Add(30 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(manifest); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the watch never wrote the manifest, errors: %s", stderr.String())
+		}
+	}
+	// a run that does not watch returns right after writing the manifest
+	select {
+	case c := <-code:
+		t.Fatalf("run returned %d before the watch was cancelled", c)
+	case <-time.After(100 * time.Millisecond):
+	}
+	cancel()
+	if c := <-code; c != 0 {
+		t.Errorf("got exit code %d, errors: %s", c, stderr.String())
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/grutz/go-whittaker-eilers/datasets"
+)
+
+// runData implements "we data list" and "we data fetch" and returns the exit code.
+func runData(args []string) int {
+	if len(args) == 0 {
+		usage()
+	}
+
+	switch args[0] {
+	case "list":
+		for _, name := range datasets.Names() {
+			fmt.Println(name)
+		}
+		return 0
+	case "fetch":
+		flags := flag.NewFlagSet("data fetch", flag.ExitOnError)
+		dir := flags.String("dir", "", "directory to write the datasets into (default the user cache directory)")
+		flags.Parse(args[1:])
+		if flags.NArg() == 0 {
+			usage()
+		}
+
+		for _, name := range flags.Args() {
+			path, err := datasets.Fetch(name, *dir)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 2
+			}
+			fmt.Println(path)
+		}
+		return 0
+	}
+
+	usage()
+	return 2
+}
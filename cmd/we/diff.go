@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grutz/go-whittaker-eilers/internal/runmanifest"
+)
+
+// fileDiff is the comparison of one output file between two runs.
+type fileDiff struct {
+	Path    string
+	Missing string // "A" or "B" when the file is only in one run
+	Values  [2]int
+	MaxAbs  float64
+	Bytes   bool     // the contents differ, for files other than data compared byte for byte
+	Changes []string // parameter changes recorded in the manifests
+}
+
+// differs reports whether the file differs by more than tolerance between the runs.
+func (f fileDiff) differs(tolerance float64) bool {
+	return f.Missing != "" || f.Values[0] != f.Values[1] || f.MaxAbs > tolerance || f.Bytes || len(f.Changes) > 0
+}
+
+// runDiff implements "we diff" and returns the exit code: 0 when the runs match, 1 when they differ and 2 on
+// errors.
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	tolerance := flags.Float64("tolerance", 0, "largest absolute difference between values that is ignored")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		usage()
+	}
+
+	diffs, err := diffRuns(flags.Arg(0), flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if writeDiff(os.Stdout, diffs, *tolerance) {
+		return 1
+	}
+	return 0
+}
+
+// diffRuns compares the outputs of the runs in dirs a and b. The outputs are listed in the run manifests, or are
+// every file of the run when it has no manifest. Data files are compared by their values, and the others, such as
+// plots, byte for byte.
+func diffRuns(a, b string) ([]fileDiff, error) {
+	entriesA, manifestA, err := runEntries(a)
+	if err != nil {
+		return nil, err
+	}
+	entriesB, manifestB, err := runEntries(b)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string]bool{}
+	for p := range entriesA {
+		paths[p] = true
+	}
+	for p := range entriesB {
+		paths[p] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	var diffs []fileDiff
+	for _, p := range sorted {
+		ea, inA := entriesA[p]
+		eb, inB := entriesB[p]
+		d := fileDiff{Path: p}
+		switch {
+		case !inB:
+			d.Missing = "B"
+		case !inA:
+			d.Missing = "A"
+		default:
+			if manifestA && manifestB {
+				d.Changes = parameterChanges(ea, eb)
+			}
+			compare := compareValues
+			if !dataFile(p) {
+				compare = compareBytes
+			}
+			if err := compare(&d, filepath.Join(a, p), filepath.Join(b, p)); err != nil {
+				return nil, err
+			}
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+// runEntries returns the manifest entries of the run in dir keyed by output path, and whether the run has a
+// manifest. Without a manifest every file in the run is listed with an empty entry.
+func runEntries(dir string) (map[string]runmanifest.Entry, bool, error) {
+	entries := map[string]runmanifest.Entry{}
+	m, err := runmanifest.Read(dir)
+	switch {
+	case err == nil:
+		for _, e := range m.Entries {
+			entries[filepath.ToSlash(e.Output)] = e
+		}
+		return entries, true, nil
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, false, err
+	}
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		entries[filepath.ToSlash(rel)] = runmanifest.Entry{Output: rel}
+		return nil
+	})
+	return entries, false, err
+}
+
+// parameterChanges describes the differences between the parameters recorded for an output in two runs.
+func parameterChanges(a, b runmanifest.Entry) []string {
+	var changes []string
+	if a.Input != b.Input {
+		changes = append(changes, fmt.Sprintf("input %s -> %s", a.Input, b.Input))
+	}
+	if !reflect.DeepEqual(a.Lambdas, b.Lambdas) {
+		changes = append(changes, fmt.Sprintf("lambdas %v -> %v", a.Lambdas, b.Lambdas))
+	}
+	if a.Order != b.Order {
+		changes = append(changes, fmt.Sprintf("order %d -> %d", a.Order, b.Order))
+	}
+	return changes
+}
+
+// dataFile reports whether the output at path holds numbers in text, such as csv or JSON, which are compared by
+// value.
+func dataFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".tsv", ".txt", ".dat", ".json":
+		return true
+	}
+	return false
+}
+
+// compareBytes records in d whether the contents of the files at a and b differ.
+func compareBytes(d *fileDiff, a, b string) error {
+	ca, err := os.ReadFile(a)
+	if err != nil {
+		return err
+	}
+	cb, err := os.ReadFile(b)
+	if err != nil {
+		return err
+	}
+	d.Bytes = !bytes.Equal(ca, cb)
+	return nil
+}
+
+// compareValues reads the numbers in the files at a and b and records their count and largest absolute
+// difference in d.
+func compareValues(d *fileDiff, a, b string) error {
+	va, err := readValues(a)
+	if err != nil {
+		return err
+	}
+	vb, err := readValues(b)
+	if err != nil {
+		return err
+	}
+
+	d.Values = [2]int{len(va), len(vb)}
+	for i := 0; i < min(len(va), len(vb)); i++ {
+		diff := math.Abs(va[i] - vb[i])
+		if math.IsNaN(diff) && !(math.IsNaN(va[i]) && math.IsNaN(vb[i])) {
+			diff = math.Inf(1)
+		}
+		if diff > d.MaxAbs {
+			d.MaxAbs = diff
+		}
+	}
+	return nil
+}
+
+// readValues returns every number in the file, reading fields separated by commas, tabs, spaces or the brackets,
+// braces, colons and quotes of JSON and skipping fields that are not numbers, such as headers and keys.
+func readValues(filename string) ([]float64, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var values []float64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.FieldsFunc(scanner.Text(), func(r rune) bool {
+			return strings.ContainsRune(",; \t[]{}:\"", r)
+		})
+		for _, f := range fields {
+			v, err := strconv.ParseFloat(f, 64)
+			if err != nil {
+				// skip non-float fields
+				continue
+			}
+			values = append(values, v)
+		}
+	}
+	return values, scanner.Err()
+}
+
+// writeDiff reports the file differences to w and returns whether any file differs by more than tolerance.
+func writeDiff(w io.Writer, diffs []fileDiff, tolerance float64) bool {
+	differs := false
+	for _, d := range diffs {
+		if !d.differs(tolerance) {
+			if dataFile(d.Path) {
+				fmt.Fprintf(w, "  %s: max abs diff %g\n", d.Path, d.MaxAbs)
+			} else {
+				fmt.Fprintf(w, "  %s: identical\n", d.Path)
+			}
+			continue
+		}
+		differs = true
+		switch {
+		case d.Missing != "":
+			fmt.Fprintf(w, "! %s: missing from run %s\n", d.Path, d.Missing)
+		case d.Bytes:
+			fmt.Fprintf(w, "! %s: contents differ\n", d.Path)
+		case d.Values[0] != d.Values[1]:
+			fmt.Fprintf(w, "! %s: %d values vs %d\n", d.Path, d.Values[0], d.Values[1])
+		default:
+			fmt.Fprintf(w, "! %s: max abs diff %g\n", d.Path, d.MaxAbs)
+		}
+		for _, c := range d.Changes {
+			fmt.Fprintf(w, "    %s\n", c)
+		}
+	}
+	return differs
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grutz/go-whittaker-eilers/internal/runmanifest"
+)
+
+func writeRun(t *testing.T, files map[string]string, m *runmanifest.Manifest) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if m != nil {
+		if err := runmanifest.Write(dir, m); err != nil {
+			t.Fatalf("Failed to write manifest: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestDiffRuns(t *testing.T) {
+	a := writeRun(t, map[string]string{
+		"nmr.csv":  "x,smooth\n0,1.5\n1,2.5\n",
+		"wood.csv": "x,smooth\n0,10\n1,11\n",
+	}, &runmanifest.Manifest{Entries: []runmanifest.Entry{
+		{Input: "nmr.dat", Output: "nmr.csv", Lambdas: []float64{10}, Order: 2},
+		{Input: "wood.txt", Output: "wood.csv", Lambdas: []float64{10}, Order: 2},
+	}})
+	b := writeRun(t, map[string]string{
+		"nmr.csv":  "x,smooth\n0,1.5\n1,2.75\n",
+		"wood.csv": "x,smooth\n0,10\n1,11\n",
+	}, &runmanifest.Manifest{Entries: []runmanifest.Entry{
+		{Input: "nmr.dat", Output: "nmr.csv", Lambdas: []float64{10}, Order: 2},
+		{Input: "wood.txt", Output: "wood.csv", Lambdas: []float64{50}, Order: 2},
+	}})
+
+	diffs, err := diffRuns(a, b)
+	if err != nil {
+		t.Fatalf("Failed to diff runs: %v", err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("got %d file diffs, want 2", len(diffs))
+	}
+
+	nmr, wood := diffs[0], diffs[1]
+	if nmr.MaxAbs != 0.25 || !nmr.differs(0.1) || nmr.differs(0.5) {
+		t.Errorf("nmr.csv: got max abs diff %g", nmr.MaxAbs)
+	}
+	if wood.MaxAbs != 0 || len(wood.Changes) != 1 || !wood.differs(1) {
+		t.Errorf("wood.csv: got max abs diff %g and changes %v", wood.MaxAbs, wood.Changes)
+	}
+
+	// without manifests every file in the run is compared
+	c := writeRun(t, map[string]string{"nmr.csv": "x,smooth\n0,1.5\n1,2.5\n"}, nil)
+	diffs, err = diffRuns(a, c)
+	if err != nil {
+		t.Fatalf("Failed to diff runs: %v", err)
+	}
+	if len(diffs) != 2 || diffs[0].Path != "nmr.csv" || diffs[0].differs(0) || diffs[1].Missing != "B" {
+		t.Errorf("unexpected diffs against a run without manifest: %+v", diffs)
+	}
+}
+
+func TestDiffRunsFiles(t *testing.T) {
+	a := writeRun(t, map[string]string{
+		"nmr.json":     `{"x": [0, 1], "y": [1, 2], "smooths": [{"lambda": 10, "order": 2, "z": [1.5, 2.5]}]}`,
+		"nmr.png":      "\x89PNG\x00\x01",
+		"nmr-same.png": "\x89PNG\x00\x01",
+	}, nil)
+	b := writeRun(t, map[string]string{
+		"nmr.json":     `{"x": [0, 1], "y": [1, 2], "smooths": [{"lambda": 10, "order": 2, "z": [1.5, 2.75]}]}`,
+		"nmr.png":      "\x89PNG\x00\x02",
+		"nmr-same.png": "\x89PNG\x00\x01",
+	}, nil)
+
+	diffs, err := diffRuns(a, b)
+	if err != nil {
+		t.Fatalf("Failed to diff runs: %v", err)
+	}
+	if len(diffs) != 3 {
+		t.Fatalf("got %d file diffs, want 3", len(diffs))
+	}
+	same, json, png := diffs[0], diffs[1], diffs[2]
+	if json.Values != [2]int{8, 8} || json.MaxAbs != 0.25 || json.Bytes {
+		t.Errorf("nmr.json: got %d values and max abs diff %g", json.Values, json.MaxAbs)
+	}
+	if !png.Bytes || !png.differs(1) {
+		t.Errorf("nmr.png: expected the contents to differ")
+	}
+	if same.differs(0) {
+		t.Errorf("nmr-same.png: expected no difference, got %+v", same)
+	}
+
+	var out strings.Builder
+	if !writeDiff(&out, diffs, 0.5) {
+		t.Errorf("expected the runs to differ")
+	}
+	want := "  nmr-same.png: identical\n  nmr.json: max abs diff 0.25\n! nmr.png: contents differ\n"
+	if out.String() != want {
+		t.Errorf("got report\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,38 @@
+// Command we is a companion tool for working with Whittaker-Eilers smoothing runs.
+//
+// Usage:
+//
+//	we diff [-tolerance t] runA/ runB/
+//	we data list
+//	we data fetch [-dir d] nmr|wood|synthetic-step ...
+//
+// we diff compares the outputs of two runs of cmd/plot, those listed in their manifest.json or every file of a run
+// without one. Data files such as csv and JSON are compared by their values and plots byte for byte.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: we diff [-tolerance t] runA/ runB/")
+	fmt.Fprintln(os.Stderr, "       we data list")
+	fmt.Fprintln(os.Stderr, "       we data fetch [-dir d] nmr|wood|synthetic-step ...")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "diff":
+		os.Exit(runDiff(os.Args[2:]))
+	case "data":
+		os.Exit(runData(os.Args[2:]))
+	default:
+		usage()
+	}
+}
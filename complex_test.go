@@ -0,0 +1,48 @@
+package smoother
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherComplex(t *testing.T) {
+	rng := rand.New(rand.NewSource(19))
+	n := 300
+	y := make([]complex128, n)
+	re := make([]float64, n)
+	im := make([]float64, n)
+	for i := range y {
+		// a decaying rotating signal with complex noise
+		fid := cmplx.Exp(complex(-float64(i)/100, float64(i)/20))
+		y[i] = fid + complex(rng.NormFloat64()*0.05, rng.NormFloat64()*0.05)
+		re[i], im[i] = real(y[i]), imag(y[i])
+	}
+
+	z, err := WESmootherComplex(y, 50, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherComplex: %v", err)
+	}
+	wantRe, err := WESmoother(re, 50, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	wantIm, err := WESmoother(im, 50, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	for i := range z {
+		if math.Abs(real(z[i])-wantRe[i]) > 1e-12 || math.Abs(imag(z[i])-wantIm[i]) > 1e-12 {
+			t.Fatalf("index %d: got %v, want %v", i, z[i], complex(wantRe[i], wantIm[i]))
+		}
+	}
+
+	y[7] = complex(math.NaN(), 0)
+	if _, err := WESmootherComplex(y, 50, 2); err == nil {
+		t.Errorf("expected an error for a NaN value")
+	}
+	if _, err := WESmootherComplex(y[:2], 50, 2); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
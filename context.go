@@ -0,0 +1,32 @@
+package smoother
+
+import (
+	"context"
+)
+
+// WESmootherCtx applies the Whittaker-Eilers smoothing function to y like WESmoother, but gives up with the error
+// of ctx, such as context.Canceled or context.DeadlineExceeded, once ctx is done. It solves the band system in
+// O(n·d²) and checks ctx every few thousand rows of the factorization, so a service can bound the time spent on
+// a huge request.
+func WESmootherCtx(ctx context.Context, y []float64, lambda float64, d int) ([]float64, error) {
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	P := pooledPenaltyBand(len(y), d)
+	defer putBand(P)
+	chol, err := pooledFactor(ctx, P, lambda, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer putFactor(chol)
+	return chol.solve(y), nil
+}
+
+// WESmootherRobustCtx is WESmootherRobust, giving up with the error of ctx once ctx is done. ctx is checked
+// before every reweighting step, and every step is reported to the ProgressFunc set by WithProgress.
+func WESmootherRobustCtx(ctx context.Context, y []float64, lambda float64, d int, opts ...FitOption) (smooth, weights []float64, err error) {
+	return robustSmooth(ctx, y, lambda, d, newProgressReporter(opts))
+}
@@ -0,0 +1,50 @@
+package smoother
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWESmootherCtx(t *testing.T) {
+	y := make([]float64, 300)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/20) + 0.2*math.Cos(float64(i)*2.1)
+	}
+	want, err := WESmoother(y, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	z, err := WESmootherCtx(context.Background(), y, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to smooth: %v", err)
+	}
+	for i := range want {
+		if math.Abs(z[i]-want[i]) > 1e-9 {
+			t.Fatalf("index %d: got %f, want %f", i, z[i], want[i])
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := WESmootherCtx(ctx, y, 100, 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+	if _, _, err := WESmootherRobustCtx(ctx, y, 100, 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("robust: got error %v, want context.Canceled", err)
+	}
+
+	// a deadline expiring during the factorization of a huge series stops it
+	big := make([]float64, 5_000_000)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := WESmootherCtx(ctx, big, 100, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+
+	if _, err := WESmootherCtx(context.Background(), y[:2], 100, 2); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
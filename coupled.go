@@ -0,0 +1,86 @@
+package smoother
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// WESmootherCoupled is like WESmootherChannels, but additionally penalizes the differences between channels, so
+// related sensors are smoothed jointly and share structure. It minimizes
+//
+//	Σ_c |y_c - z_c|² + λ Σ_c |D z_c|² + coupling Σ_{c<c'} |z_c - z_c'|²
+//
+// over the smooth channels z_c. A coupling of zero smooths every channel on its own and a large coupling pulls
+// every channel towards the common smooth of their mean.
+//
+// The coupling penalty only acts on the deviations of the channels from their mean, so the problem separates:
+// the mean is smoothed by (I + λD'D) and the deviations by (I + λD'D + C·coupling·I) for C channels, which takes
+// two factorizations however many channels there are.
+func WESmootherCoupled(channels [][]float64, lambda float64, d int, coupling float64) ([][]float64, error) {
+	if len(channels) == 0 {
+		return nil, errors.New("no channels given")
+	}
+	n := len(channels[0])
+	for c := range channels {
+		if len(channels[c]) != n {
+			return nil, fmt.Errorf("%w: channel %d has %d samples, want %d", ErrLengthMismatch, c, len(channels[c]), n)
+		}
+	}
+	if err := checkLength(n, d); err != nil {
+		return nil, err
+	}
+	if err := checkLambda(lambda); err != nil {
+		return nil, err
+	}
+	if !(coupling >= 0) {
+		return nil, fmt.Errorf("coupling %f must not be negative", coupling)
+	}
+	for c := range channels {
+		if err := checkFinite(channels[c]); err != nil {
+			return nil, fmt.Errorf("channel %d: %w", c, err)
+		}
+	}
+
+	C := float64(len(channels))
+	mean := make([]float64, n)
+	for c := range channels {
+		for i, v := range channels[c] {
+			mean[i] += v / C
+		}
+	}
+
+	P := penaltyMatrix(n, lambda, d)
+	meanSmooth, err := solvePenalized(mean, nil, P, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The deviations see the coupling as an additional ridge penalty
+	Q := mat.DenseCopyOf(P)
+	for i := 0; i < n; i++ {
+		Q.Set(i, i, Q.At(i, i)+C*coupling)
+	}
+	sys, err := factorizePenalized(nil, Q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	smooth := make([][]float64, len(channels))
+	dev := make([]float64, n)
+	for c := range channels {
+		for i, v := range channels[c] {
+			dev[i] = v - mean[i]
+		}
+		z, err := sys.solve(dev)
+		if err != nil {
+			return nil, err
+		}
+		for i := range z {
+			z[i] += meanSmooth[i]
+		}
+		smooth[c] = z
+	}
+	return smooth, nil
+}
@@ -0,0 +1,79 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestWESmootherCoupled(t *testing.T) {
+	rng := rand.New(rand.NewSource(21))
+	n := 60
+	channels := make([][]float64, 3)
+	for c := range channels {
+		channels[c] = make([]float64, n)
+		for i := range channels[c] {
+			channels[c][i] = math.Sin(float64(i)/10) + 0.2*float64(c) + rng.NormFloat64()*0.2
+		}
+	}
+	const lambda, coupling = 50.0, 2.0
+
+	z, err := WESmootherCoupled(channels, lambda, 2, coupling)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherCoupled: %v", err)
+	}
+
+	// Solve the joint system of all channels directly
+	C := len(channels)
+	P := penaltyMatrix(n, lambda, 2)
+	A := mat.NewDense(C*n, C*n, nil)
+	b := mat.NewVecDense(C*n, nil)
+	for c := 0; c < C; c++ {
+		for i := 0; i < n; i++ {
+			b.SetVec(c*n+i, channels[c][i])
+			for j := 0; j < n; j++ {
+				A.Set(c*n+i, c*n+j, P.At(i, j))
+			}
+			A.Set(c*n+i, c*n+i, A.At(c*n+i, c*n+i)+1+coupling*float64(C-1))
+			for o := 0; o < C; o++ {
+				if o != c {
+					A.Set(c*n+i, o*n+i, -coupling)
+				}
+			}
+		}
+	}
+	var want mat.VecDense
+	if err := want.SolveVec(A, b); err != nil {
+		t.Fatalf("Failed to solve the joint system: %v", err)
+	}
+	for c := 0; c < C; c++ {
+		for i := 0; i < n; i++ {
+			if math.Abs(z[c][i]-want.AtVec(c*n+i)) > 1e-9 {
+				t.Fatalf("channel %d, index %d: got %f, want %f", c, i, z[c][i], want.AtVec(c*n+i))
+			}
+		}
+	}
+
+	// without coupling every channel is smoothed on its own
+	if z, err = WESmootherCoupled(channels, lambda, 2, 0); err != nil {
+		t.Fatalf("Failed to apply WESmootherCoupled: %v", err)
+	}
+	alone, err := WESmoother(channels[2], lambda, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	for i := range alone {
+		if math.Abs(z[2][i]-alone[i]) > 1e-9 {
+			t.Fatalf("index %d: uncoupled got %f, want %f", i, z[2][i], alone[i])
+		}
+	}
+
+	if _, err := WESmootherCoupled(channels, lambda, 2, -1); err == nil {
+		t.Errorf("expected an error for a negative coupling")
+	}
+	if _, err := WESmootherCoupled(nil, lambda, 2, 1); err == nil {
+		t.Errorf("expected an error for no channels")
+	}
+}
@@ -0,0 +1,22 @@
+package smoother
+
+// CrossValidationError returns the root mean square leave-one-out prediction error of smoothing y with lambda and
+// order d, as computed by whitsm.m from the supporting info of the paper. Leaving out a point does not require a
+// new fit: its prediction error is (y_i - z_i) / (1 - h_i), where h is the diagonal of the hat matrix. The lambda
+// minimizing the error is a good automatic choice of smoothing parameter. The smooth and the hat diagonal come
+// from a band Cholesky factorization, so this takes O(n·d²) like SmoothSweep.
+func CrossValidationError(y []float64, lambda float64, d int) (float64, error) {
+	if err := checkLength(len(y), d); err != nil {
+		return 0, err
+	}
+	if err := checkLambda(lambda); err != nil {
+		return 0, err
+	}
+	P := pooledPenaltyBand(len(y), d)
+	defer putBand(P)
+	z, h, err := bandSmooth(y, P, lambda)
+	if err != nil {
+		return 0, err
+	}
+	return leaveOneOutError(y, z, h), nil
+}
@@ -0,0 +1,30 @@
+package smoother
+
+import (
+	"testing"
+
+	"github.com/grutz/go-whittaker-eilers/datasets"
+)
+
+func TestCrossValidationError(t *testing.T) {
+	data, err := datasets.Load("nmr")
+	if err != nil {
+		t.Fatalf("Failed to load dataset: %v", err)
+	}
+
+	// the NMR spectrum is undersmoothed at small lambdas and oversmoothed at large ones
+	var cvs []float64
+	for _, lambda := range []float64{1e-2, 10, 1e6} {
+		cv, err := CrossValidationError(data, lambda, 2)
+		if err != nil {
+			t.Fatalf("Failed to compute CrossValidationError: %v", err)
+		}
+		cvs = append(cvs, cv)
+	}
+	if cvs[1] >= cvs[0] || cvs[1] >= cvs[2] {
+		t.Errorf("cross-validation error has no interior minimum: %v", cvs)
+	}
+	if _, err := CrossValidationError([]float64{1, 2}, 10, 2); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
@@ -0,0 +1,203 @@
+package dataio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/ipc"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+)
+
+// ArrowOptions selects the columns ReadArrow reads.
+type ArrowOptions struct {
+	// X and Y select the columns of the sample positions and of the values, by field name or, if no field has that
+	// name, by zero-based index. An empty X reads no positions, an empty Y the first column that is not X.
+	X, Y string
+	// W selects the column of the weights of the values in the same way, an empty W reads no weights.
+	W string
+}
+
+// ArrowFloats returns the values of a numeric Arrow array as float64, NaN for null values. A float64 array
+// without nulls is returned without copying, so the smoother can read a column of a record in place; the slice
+// then shares the memory of the array and is only valid while the array is.
+func ArrowFloats(a arrow.Array) ([]float64, error) {
+	if f, ok := a.(*array.Float64); ok && f.NullN() == 0 {
+		return f.Float64Values(), nil
+	}
+	return appendArrowFloats(make([]float64, 0, a.Len()), a)
+}
+
+// NewArrowFloats returns a float64 Arrow array of the values without copying them, so a smooth can be appended
+// to a record as a column. The values must not be modified while the array is in use.
+func NewArrowFloats(values []float64) *array.Float64 {
+	data := array.NewData(arrow.PrimitiveTypes.Float64, len(values),
+		[]*memory.Buffer{nil, memory.NewBufferBytes(arrow.Float64Traits.CastToBytes(values))}, nil, 0, 0)
+	defer data.Release()
+	return array.NewFloat64Data(data)
+}
+
+// appendArrowFloats appends the values of a numeric Arrow array to dst as float64, NaN for null values.
+// Timestamps and dates are appended as seconds since the Unix epoch.
+func appendArrowFloats(dst []float64, a arrow.Array) ([]float64, error) {
+	var at func(i int) float64
+	switch a := a.(type) {
+	case *array.Float64:
+		at = a.Value
+	case *array.Float32:
+		at = func(i int) float64 { return float64(a.Value(i)) }
+	case *array.Int64:
+		at = func(i int) float64 { return float64(a.Value(i)) }
+	case *array.Int32:
+		at = func(i int) float64 { return float64(a.Value(i)) }
+	case *array.Int16:
+		at = func(i int) float64 { return float64(a.Value(i)) }
+	case *array.Int8:
+		at = func(i int) float64 { return float64(a.Value(i)) }
+	case *array.Uint64:
+		at = func(i int) float64 { return float64(a.Value(i)) }
+	case *array.Uint32:
+		at = func(i int) float64 { return float64(a.Value(i)) }
+	case *array.Uint16:
+		at = func(i int) float64 { return float64(a.Value(i)) }
+	case *array.Uint8:
+		at = func(i int) float64 { return float64(a.Value(i)) }
+	case *array.Timestamp:
+		unit := a.DataType().(*arrow.TimestampType).Unit
+		at = func(i int) float64 { return unixSeconds(a.Value(i).ToTime(unit)) }
+	case *array.Date32:
+		at = func(i int) float64 { return float64(a.Value(i)) * 86400 }
+	case *array.Date64:
+		at = func(i int) float64 { return float64(a.Value(i)) / 1e3 }
+	default:
+		return nil, fmt.Errorf("%s is not a numeric type", a.DataType())
+	}
+	for i := 0; i < a.Len(); i++ {
+		if a.IsNull(i) {
+			dst = append(dst, math.NaN())
+			continue
+		}
+		dst = append(dst, at(i))
+	}
+	return dst, nil
+}
+
+// ReadArrow reads a series from an Arrow IPC stream, taking the values and optionally the sample positions and
+// weights from the columns selected by opts across all its record batches. The columns must hold integers,
+// floating point numbers, timestamps or dates, which read as seconds since the Unix epoch; null values read as
+// NaN.
+func ReadArrow(r io.Reader, opts ArrowOptions) (*Series, error) {
+	rd, err := ipc.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Release()
+
+	fields := rd.Schema().Fields()
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	xCol := -1
+	if opts.X != "" {
+		if xCol, err = column(names, opts.X); err != nil {
+			return nil, fmt.Errorf("arrow: %w", err)
+		}
+	}
+	yCol := 0
+	if xCol == 0 {
+		yCol = 1
+	}
+	if opts.Y != "" {
+		if yCol, err = column(names, opts.Y); err != nil {
+			return nil, fmt.Errorf("arrow: %w", err)
+		}
+	}
+	if yCol >= len(names) {
+		return nil, errors.New("arrow stream has no value column")
+	}
+	wCol := -1
+	if opts.W != "" {
+		if wCol, err = column(names, opts.W); err != nil {
+			return nil, fmt.Errorf("arrow: %w", err)
+		}
+	}
+
+	s := &Series{Y: []float64{}}
+	if xCol >= 0 {
+		s.X = []float64{}
+	}
+	if wCol >= 0 {
+		s.W = []float64{}
+	}
+	for rd.Next() {
+		rec := rd.Record()
+		if s.Y, err = appendArrowFloats(s.Y, rec.Column(yCol)); err != nil {
+			return nil, fmt.Errorf("arrow column %q: %w", names[yCol], err)
+		}
+		if xCol >= 0 {
+			if s.X, err = appendArrowFloats(s.X, rec.Column(xCol)); err != nil {
+				return nil, fmt.Errorf("arrow column %q: %w", names[xCol], err)
+			}
+		}
+		if wCol >= 0 {
+			if s.W, err = appendArrowFloats(s.W, rec.Column(wCol)); err != nil {
+				return nil, fmt.Errorf("arrow column %q: %w", names[wCol], err)
+			}
+		}
+	}
+	if err := rd.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// WriteArrow writes the series s and its smooths to w as an Arrow IPC stream of one record batch with the float64
+// columns x, left out when s has no positions, y and smooth_<lambda> for every smooth, whose field metadata holds
+// its lambda and order. The values are not copied into the batch.
+func WriteArrow(w io.Writer, s *Series, smooths []Smooth) error {
+	var fields []arrow.Field
+	var cols []arrow.Array
+	add := func(name string, md arrow.Metadata, values []float64) error {
+		if len(values) != len(s.Y) {
+			return fmt.Errorf("arrow column %q has %d values, want %d", name, len(values), len(s.Y))
+		}
+		fields = append(fields, arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Float64, Metadata: md})
+		cols = append(cols, NewArrowFloats(values))
+		return nil
+	}
+	defer func() {
+		for _, c := range cols {
+			c.Release()
+		}
+	}()
+	if s.X != nil {
+		if err := add("x", arrow.Metadata{}, s.X); err != nil {
+			return err
+		}
+	}
+	if err := add("y", arrow.Metadata{}, s.Y); err != nil {
+		return err
+	}
+	for _, sm := range smooths {
+		lambda := formatFloat(sm.Lambda)
+		md := arrow.NewMetadata([]string{"lambda", "order"}, []string{lambda, strconv.Itoa(sm.Order)})
+		if err := add("smooth_"+lambda, md, sm.Z); err != nil {
+			return err
+		}
+	}
+
+	schema := arrow.NewSchema(fields, nil)
+	rec := array.NewRecord(schema, cols, int64(len(s.Y)))
+	defer rec.Release()
+	wr := ipc.NewWriter(w, ipc.WithSchema(schema))
+	if err := wr.Write(rec); err != nil {
+		wr.Close()
+		return err
+	}
+	return wr.Close()
+}
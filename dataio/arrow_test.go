@@ -0,0 +1,130 @@
+package dataio
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/ipc"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+)
+
+func TestArrowFloats(t *testing.T) {
+	values := []float64{1, 2, 3}
+	a := NewArrowFloats(values)
+	defer a.Release()
+	got, err := ArrowFloats(a)
+	if err != nil {
+		t.Fatalf("Failed to read the array: %v", err)
+	}
+	if &got[0] != &values[0] {
+		t.Errorf("a float64 array without nulls was copied")
+	}
+
+	b := array.NewInt32Builder(memory.DefaultAllocator)
+	defer b.Release()
+	b.AppendValues([]int32{4, 0, 6}, []bool{true, false, true})
+	ints := b.NewArray()
+	defer ints.Release()
+	if got, err := ArrowFloats(ints); err != nil || len(got) != 3 || got[0] != 4 || !math.IsNaN(got[1]) || got[2] != 6 {
+		t.Errorf("got %v and %v for an int32 array with a null", got, err)
+	}
+
+	tb := array.NewTimestampBuilder(memory.DefaultAllocator, &arrow.TimestampType{Unit: arrow.Millisecond})
+	defer tb.Release()
+	tb.AppendValues([]arrow.Timestamp{1500, 60000}, nil)
+	stamps := tb.NewArray()
+	defer stamps.Release()
+	if got, err := ArrowFloats(stamps); err != nil || !equal(got, []float64{1.5, 60}) {
+		t.Errorf("got %v and %v for a timestamp array", got, err)
+	}
+	db := array.NewDate32Builder(memory.DefaultAllocator)
+	defer db.Release()
+	db.Append(2)
+	dates := db.NewArray()
+	defer dates.Release()
+	if got, err := ArrowFloats(dates); err != nil || !equal(got, []float64{2 * 86400}) {
+		t.Errorf("got %v and %v for a date array", got, err)
+	}
+
+	sb := array.NewStringBuilder(memory.DefaultAllocator)
+	defer sb.Release()
+	sb.Append("a")
+	strs := sb.NewArray()
+	defer strs.Release()
+	if _, err := ArrowFloats(strs); err == nil {
+		t.Errorf("expected an error for a string array")
+	}
+}
+
+func TestArrowRoundTrip(t *testing.T) {
+	s := &Series{X: []float64{0, 1, 4}, Y: []float64{2, math.NaN(), 3}}
+	var buf bytes.Buffer
+	if err := WriteArrow(&buf, s, []Smooth{{Lambda: 50, Order: 2, Z: []float64{2.1, 2.4, 2.9}}}); err != nil {
+		t.Fatalf("Failed to write arrow: %v", err)
+	}
+	raw := bytes.NewReader(buf.Bytes())
+
+	rd, err := ipc.NewReader(raw)
+	if err != nil {
+		t.Fatalf("Failed to open the stream: %v", err)
+	}
+	fields := rd.Schema().Fields()
+	if len(fields) != 3 || fields[0].Name != "x" || fields[1].Name != "y" || fields[2].Name != "smooth_50" {
+		t.Fatalf("unexpected schema %v", rd.Schema())
+	}
+	if i := fields[2].Metadata.FindKey("order"); i < 0 || fields[2].Metadata.Values()[i] != "2" {
+		t.Errorf("smooth metadata %v lacks the order", fields[2].Metadata)
+	}
+	rd.Release()
+
+	raw.Reset(buf.Bytes())
+	back, err := ReadArrow(raw, ArrowOptions{X: "x", Y: "y"})
+	if err != nil {
+		t.Fatalf("Failed to read arrow: %v", err)
+	}
+	if !equal(back.X, s.X) || back.Y[0] != 2 || !math.IsNaN(back.Y[1]) || back.Y[2] != 3 {
+		t.Errorf("read back x %v and y %v", back.X, back.Y)
+	}
+	raw.Reset(buf.Bytes())
+	if back, err := ReadArrow(raw, ArrowOptions{Y: "smooth_50"}); err != nil || back.X != nil || !equal(back.Y, []float64{2.1, 2.4, 2.9}) {
+		t.Errorf("got %v and %v for the smooth column", back, err)
+	}
+	raw.Reset(buf.Bytes())
+	if back, err := ReadArrow(raw, ArrowOptions{Y: "y", W: "x"}); err != nil || !equal(back.W, s.X) {
+		t.Errorf("got %v and %v reading weights", back, err)
+	}
+	raw.Reset(buf.Bytes())
+	if _, err := ReadArrow(raw, ArrowOptions{Y: "z"}); err == nil {
+		t.Errorf("expected an error for an unknown column")
+	}
+
+	if err := WriteArrow(&buf, s, []Smooth{{Lambda: 1, Z: []float64{1}}}); err == nil {
+		t.Errorf("expected an error for a smooth of another length")
+	}
+}
+
+func TestReadArrowBatches(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{{Name: "v", Type: arrow.PrimitiveTypes.Float32}}, nil)
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	for _, batch := range [][]float32{{1, 2}, {3}} {
+		b.Field(0).(*array.Float32Builder).AppendValues(batch, nil)
+		rec := b.NewRecord()
+		if err := w.Write(rec); err != nil {
+			t.Fatalf("Failed to write a batch: %v", err)
+		}
+		rec.Release()
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close the stream: %v", err)
+	}
+	s, err := ReadArrow(&buf, ArrowOptions{})
+	if err != nil || !equal(s.Y, []float64{1, 2, 3}) {
+		t.Errorf("got %v and %v across batches", s, err)
+	}
+}
@@ -0,0 +1,319 @@
+package dataio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVOptions selects the columns ReadCSV reads.
+type CSVOptions struct {
+	// X and Y select the columns of the sample positions and of the values, by header name or, if no header has
+	// that name, by zero-based index. An empty X reads no positions, an empty Y the first column that is not X.
+	X, Y string
+	// W selects the column of the weights of the values in the same way, an empty W reads no weights.
+	W string
+	// XTime, if not empty, reads the positions as timestamps in this layout of ParseTime.
+	XTime string
+
+	// Comma is the field delimiter, such as ';' for European exports or '\t' for TSV; zero means ','.
+	Comma rune
+	// Whitespace splits the fields at runs of spaces and tabs instead of at Comma, as in the column aligned exports
+	// of many instruments. Quoting is not supported then.
+	Whitespace bool
+}
+
+// recordReader reads the records of a delimited file one by one, returning io.EOF after the last one.
+type recordReader interface {
+	// read returns the next record and the line it starts on. The record may be reused by the next call.
+	read() (record []string, line int, err error)
+}
+
+// csvRecords reads records with encoding/csv.
+type csvRecords struct {
+	r *csv.Reader
+}
+
+// read implements recordReader.
+func (c csvRecords) read() ([]string, int, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		return nil, 0, err
+	}
+	line, _ := c.r.FieldPos(0)
+	return record, line, nil
+}
+
+// fieldRecords reads records whose fields are separated by runs of whitespace, skipping blank lines.
+type fieldRecords struct {
+	scanner *bufio.Scanner
+	line    int
+	// fields is the number of fields of the first record, which all others must have.
+	fields int
+}
+
+// read implements recordReader.
+func (f *fieldRecords) read() ([]string, int, error) {
+	for f.scanner.Scan() {
+		f.line++
+		record := strings.Fields(f.scanner.Text())
+		if len(record) == 0 {
+			continue
+		}
+		if f.fields == 0 {
+			f.fields = len(record)
+		} else if len(record) != f.fields {
+			return nil, 0, fmt.Errorf("line %d: %d fields, want %d", f.line, len(record), f.fields)
+		}
+		return record, f.line, nil
+	}
+	if err := f.scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	return nil, 0, io.EOF
+}
+
+// newRecordReader returns the reader of the records of r as opts delimits them.
+func newRecordReader(r io.Reader, opts CSVOptions) recordReader {
+	if opts.Whitespace {
+		return &fieldRecords{scanner: bufio.NewScanner(r)}
+	}
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	return csvRecords{cr}
+}
+
+// ReadCSV reads a series from CSV with a header row, taking the values and optionally the sample positions and
+// weights from the columns selected by opts. Every selected field must be a number; NaN and Inf are accepted as the
+// parser of the strconv package accepts them.
+func ReadCSV(r io.Reader, opts CSVOptions) (*Series, error) {
+	_, series, err := readCSV(r, opts, func(header []string, xCol, _ int) ([]int, error) {
+		yCol := 0
+		if xCol == 0 {
+			yCol = 1
+		}
+		if opts.Y != "" {
+			var err error
+			if yCol, err = column(header, opts.Y); err != nil {
+				return nil, err
+			}
+		}
+		if yCol >= len(header) {
+			return nil, errors.New("csv has no value column")
+		}
+		return []int{yCol}, nil
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	return series[0], nil
+}
+
+// ReadCSVColumns reads a series for each of several value columns of CSV with a header row, as many channels
+// recorded side by side, in one pass. The columns are selected by name or index like opts.Y, which is ignored,
+// and the series share the positions and weights selected by opts. Without columns every column other than those
+// of the positions and weights is read whose fields are all numbers. The names of the columns are returned
+// with their series.
+func ReadCSVColumns(r io.Reader, opts CSVOptions, columns []string) ([]string, []*Series, error) {
+	return readCSV(r, opts, func(header []string, xCol, wCol int) ([]int, error) {
+		var cols []int
+		if columns == nil {
+			for i := range header {
+				if i != xCol && i != wCol {
+					cols = append(cols, i)
+				}
+			}
+			return cols, nil
+		}
+		for _, sel := range columns {
+			col, err := column(header, sel)
+			if err != nil {
+				return nil, err
+			}
+			cols = append(cols, col)
+		}
+		return cols, nil
+	}, columns == nil)
+}
+
+// columnPicker selects the value columns to read from the header of a csv file, given the columns of the positions
+// and weights or -1 for none.
+type columnPicker func(header []string, xCol, wCol int) ([]int, error)
+
+// readCSV reads the series of the value columns that pick selects. If lenient is set, columns with a field that is
+// not a number are left out instead of failing the read, as long as any are left.
+func readCSV(r io.Reader, opts CSVOptions, pick columnPicker, lenient bool) ([]string, []*Series, error) {
+	if opts.Comma == '\r' || opts.Comma == '\n' || opts.Comma == '"' || opts.Comma == 0xFFFD {
+		return nil, nil, fmt.Errorf("invalid csv delimiter %q", opts.Comma)
+	}
+	records := newRecordReader(r, opts)
+	header, _, err := records.read()
+	if err == io.EOF {
+		return nil, nil, errors.New("csv has no header")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// the header is kept for error messages, while the reader may reuse its record
+	header = append([]string(nil), header...)
+
+	xCol, wCol := -1, -1
+	if opts.X != "" {
+		if xCol, err = column(header, opts.X); err != nil {
+			return nil, nil, err
+		}
+	}
+	if opts.W != "" {
+		if wCol, err = column(header, opts.W); err != nil {
+			return nil, nil, err
+		}
+	}
+	cols, err := pick(header, xCol, wCol)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var x, w []float64
+	values := make([][]float64, len(cols))
+	bad := make([]bool, len(cols))
+	for {
+		record, line, err := records.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for i, col := range cols {
+			if bad[i] {
+				continue
+			}
+			v, err := parseField(record, col, header, line)
+			if err != nil && lenient {
+				bad[i] = true
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			values[i] = append(values[i], v)
+		}
+		if xCol >= 0 {
+			v, err := parseTimeField(record, xCol, header, line, opts.XTime)
+			if err != nil {
+				return nil, nil, err
+			}
+			x = append(x, v)
+		}
+		if wCol >= 0 {
+			v, err := parseField(record, wCol, header, line)
+			if err != nil {
+				return nil, nil, err
+			}
+			w = append(w, v)
+		}
+	}
+
+	var names []string
+	var series []*Series
+	for i, col := range cols {
+		if !bad[i] {
+			names = append(names, strings.TrimSpace(header[col]))
+			series = append(series, &Series{X: x, Y: values[i], W: w})
+		}
+	}
+	if len(series) == 0 {
+		return nil, nil, errors.New("csv has no numeric value column")
+	}
+	return names, series, nil
+}
+
+// column returns the index of the column selected by name or index in header.
+func column(header []string, sel string) (int, error) {
+	for i, name := range header {
+		if strings.TrimSpace(name) == sel {
+			return i, nil
+		}
+	}
+	if i, err := strconv.Atoi(sel); err == nil && i >= 0 && i < len(header) {
+		return i, nil
+	}
+	return 0, fmt.Errorf("csv has no column %q", sel)
+}
+
+// parseField parses the field in column col of record, read from the given line.
+func parseField(record []string, col int, header []string, line int) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(record[col]), 64)
+	if err != nil {
+		return 0, fmt.Errorf("line %d, column %q: %q is not a number", line, header[col], record[col])
+	}
+	return v, nil
+}
+
+// parseTimeField parses the field in column col of record, read from the given line, as a timestamp in layout,
+// or as a number when layout is empty.
+func parseTimeField(record []string, col int, header []string, line int, layout string) (float64, error) {
+	if layout == "" {
+		return parseField(record, col, header, line)
+	}
+	v, err := ParseTime(record[col], layout)
+	if err != nil {
+		return 0, fmt.Errorf("line %d, column %q: %w", line, header[col], err)
+	}
+	return v, nil
+}
+
+// WriteCSV writes the series s and its smooths to w as CSV with a header row. The columns are the sample
+// positions x, or the index of every value when s has none, the original values, and the smoothed values and
+// residuals of every smooth. These are named smoothed and residual for a single smooth and smoothed_<lambda> and
+// residual_<lambda> for several.
+func WriteCSV(w io.Writer, s *Series, smooths []Smooth) error {
+	header := []string{"index", "original"}
+	if s.X != nil {
+		header[0] = "x"
+	}
+	for _, sm := range smooths {
+		if len(sm.Z) != len(s.Y) {
+			return fmt.Errorf("smooth with lambda %g has %d values, want %d", sm.Lambda, len(sm.Z), len(s.Y))
+		}
+		suffix := ""
+		if len(smooths) > 1 {
+			suffix = "_" + formatFloat(sm.Lambda)
+		}
+		header = append(header, "smoothed"+suffix, "residual"+suffix)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(header))
+	for i, y := range s.Y {
+		record[0] = strconv.Itoa(i)
+		if s.X != nil {
+			record[0] = formatFloat(s.X[i])
+		}
+		record[1] = formatFloat(y)
+		for j, sm := range smooths {
+			record[2+2*j] = formatFloat(sm.Z[i])
+			record[3+2*j] = formatFloat(y - sm.Z[i])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatFloat formats v in the shortest form that parses back to it.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
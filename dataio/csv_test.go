@@ -0,0 +1,160 @@
+package dataio
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	data := "time,temperature,pressure\n0,20.5,1013\n0.5,21,1012\n1.5, 22.25 ,1010\n"
+	tests := []struct {
+		name string
+		opts CSVOptions
+		x, y []float64
+	}{
+		{"defaults", CSVOptions{}, nil, []float64{0, 0.5, 1.5}},
+		{"by name", CSVOptions{X: "time", Y: "temperature"}, []float64{0, 0.5, 1.5}, []float64{20.5, 21, 22.25}},
+		{"by index", CSVOptions{Y: "2"}, nil, []float64{1013, 1012, 1010}},
+		{"x only", CSVOptions{X: "0"}, []float64{0, 0.5, 1.5}, []float64{20.5, 21, 22.25}},
+	}
+	for _, tt := range tests {
+		s, err := ReadCSV(strings.NewReader(data), tt.opts)
+		if err != nil {
+			t.Fatalf("%s: Failed to read csv: %v", tt.name, err)
+		}
+		if !equal(s.X, tt.x) || !equal(s.Y, tt.y) {
+			t.Errorf("%s: got x %v and y %v, want %v and %v", tt.name, s.X, s.Y, tt.x, tt.y)
+		}
+	}
+
+	s, err := ReadCSV(strings.NewReader(data), CSVOptions{Y: "temperature", W: "pressure"})
+	if err != nil || !equal(s.W, []float64{1013, 1012, 1010}) {
+		t.Errorf("got %v and %v reading weights", s, err)
+	}
+
+	failures := []struct {
+		name, input string
+		opts        CSVOptions
+	}{
+		{"no header", "", CSVOptions{}},
+		{"unknown column", "a,b\n1,2\n", CSVOptions{Y: "c"}},
+		{"not a number", "a,b\n1,x\n", CSVOptions{Y: "b"}},
+		{"ragged", "a,b\n1,2,3\n", CSVOptions{}},
+		{"no value", "a\n1\n", CSVOptions{X: "a"}},
+		{"unknown weights", "a,b\n1,2\n", CSVOptions{W: "c"}},
+	}
+	for _, tt := range failures {
+		if _, err := ReadCSV(strings.NewReader(tt.input), tt.opts); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestReadCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		name, input string
+		opts        CSVOptions
+	}{
+		{"semicolon", "t;v\n1;2.5\n2;3\n", CSVOptions{Comma: ';'}},
+		{"tab", "t\tv\n1\t2.5\n2\t3\n", CSVOptions{Comma: '\t'}},
+		{"whitespace", "  t    v\n\n1 \t 2.5\n  2   3  \n", CSVOptions{Whitespace: true}},
+	}
+	for _, tt := range tests {
+		tt.opts.X, tt.opts.Y = "t", "v"
+		s, err := ReadCSV(strings.NewReader(tt.input), tt.opts)
+		if err != nil {
+			t.Fatalf("%s: Failed to read csv: %v", tt.name, err)
+		}
+		if !equal(s.X, []float64{1, 2}) || !equal(s.Y, []float64{2.5, 3}) {
+			t.Errorf("%s: got x %v and y %v", tt.name, s.X, s.Y)
+		}
+	}
+
+	if _, err := ReadCSV(strings.NewReader("a b\n1 2\n3\n"), CSVOptions{Whitespace: true}); err == nil {
+		t.Errorf("expected an error for a ragged whitespace separated record")
+	}
+	if _, err := ReadCSV(strings.NewReader("a\n1\n"), CSVOptions{Comma: '"'}); err == nil {
+		t.Errorf("expected an error for a quote delimiter")
+	}
+	_, err := ReadCSV(strings.NewReader("first;second\n1;x\n"), CSVOptions{Comma: ';', Y: "second"})
+	if err == nil || !strings.Contains(err.Error(), `line 2, column "second"`) {
+		t.Errorf("got %v, want the line and column of the bad field", err)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	s := &Series{Y: []float64{1, 2.5, math.NaN()}}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, s, []Smooth{{Lambda: 10, Order: 2, Z: []float64{1.25, 2, 3}}}); err != nil {
+		t.Fatalf("Failed to write csv: %v", err)
+	}
+	want := "index,original,smoothed,residual\n0,1,1.25,-0.25\n1,2.5,2,0.5\n2,NaN,3,NaN\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	s = &Series{X: []float64{0, 0.5}, Y: []float64{4, 6}}
+	buf.Reset()
+	smooths := []Smooth{{Lambda: 1, Z: []float64{4.5, 5.5}}, {Lambda: 1e3, Z: []float64{5, 5}}}
+	if err := WriteCSV(&buf, s, smooths); err != nil {
+		t.Fatalf("Failed to write csv: %v", err)
+	}
+	want = "x,original,smoothed_1,residual_1,smoothed_1000,residual_1000\n0,4,4.5,-0.5,5,-1\n0.5,6,5.5,0.5,5,1\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	// the output reads back as a series
+	back, err := ReadCSV(&buf, CSVOptions{X: "x", Y: "smoothed_1000"})
+	if err != nil || !equal(back.X, s.X) || !equal(back.Y, []float64{5, 5}) {
+		t.Errorf("read back %v and %v", back, err)
+	}
+
+	if err := WriteCSV(&buf, s, []Smooth{{Lambda: 1, Z: []float64{1}}}); err == nil {
+		t.Errorf("expected an error for a smooth of another length")
+	}
+}
+
+// equal reports whether a and b hold the same values, a nil slice only equalling another nil slice.
+func equal(a, b []float64) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestReadCSVColumns(t *testing.T) {
+	data := "time,a,label,b,quality\n0,1,x,10,1\n1,2,y,20,0.5\n"
+	names, series, err := ReadCSVColumns(strings.NewReader(data), CSVOptions{X: "time", W: "quality"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to read csv: %v", err)
+	}
+	if strings.Join(names, ",") != "a,b" || !equal(series[0].Y, []float64{1, 2}) || !equal(series[1].Y, []float64{10, 20}) {
+		t.Fatalf("got columns %v with %v and %v", names, series[0], series[1])
+	}
+	for _, s := range series {
+		if !equal(s.X, []float64{0, 1}) || !equal(s.W, []float64{1, 0.5}) {
+			t.Errorf("got x %v and w %v", s.X, s.W)
+		}
+	}
+
+	names, series, err = ReadCSVColumns(strings.NewReader(data), CSVOptions{}, []string{"b", "1"})
+	if err != nil || strings.Join(names, ",") != "b,a" || !equal(series[1].Y, []float64{1, 2}) {
+		t.Errorf("got %v and %v selecting columns", names, err)
+	}
+	for _, columns := range [][]string{{"label"}, {"missing"}} {
+		if _, _, err := ReadCSVColumns(strings.NewReader(data), CSVOptions{}, columns); err == nil {
+			t.Errorf("%v: expected an error", columns)
+		}
+	}
+	if _, _, err := ReadCSVColumns(strings.NewReader("a,b\nx,y\n"), CSVOptions{}, nil); err == nil {
+		t.Errorf("expected an error without numeric columns")
+	}
+}
@@ -0,0 +1,9 @@
+// Package dataio reads data series for the smoother from common file formats, so tools and services do not have
+// to parse them by hand.
+package dataio
+
+// Series is a data series read from a file: the sample positions X, nil when the file has none, the values Y and
+// the weights W of the values, nil when none were read.
+type Series struct {
+	X, Y, W []float64
+}
@@ -0,0 +1,117 @@
+package dataio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// jsonFloats is a series of values encoded as a JSON array of numbers, null standing for NaN since JSON has no
+// NaN. Infinite values cannot be encoded.
+type jsonFloats []float64
+
+// MarshalJSON implements json.Marshaler.
+func (f jsonFloats) MarshalJSON() ([]byte, error) {
+	if f == nil {
+		return []byte("null"), nil
+	}
+	values := make([]*float64, len(f))
+	for i := range f {
+		if !math.IsNaN(f[i]) {
+			values[i] = &f[i]
+		}
+	}
+	return json.Marshal(values)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *jsonFloats) UnmarshalJSON(data []byte) error {
+	var values []*float64
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	if values == nil {
+		*f = nil
+		return nil
+	}
+	*f = make(jsonFloats, len(values))
+	for i, v := range values {
+		(*f)[i] = math.NaN()
+		if v != nil {
+			(*f)[i] = *v
+		}
+	}
+	return nil
+}
+
+// jsonSeries is the object form of a series in JSON.
+type jsonSeries struct {
+	X jsonFloats `json:"x,omitempty"`
+	Y jsonFloats `json:"y"`
+	W jsonFloats `json:"w,omitempty"`
+}
+
+// ReadJSON reads a series from JSON, either an array of values or an object {"x": [...], "y": [...], "w": [...]}
+// whose optional x holds the sample positions and w the weights. A null value reads as NaN, so missing values can
+// be smoothed with the NaN policies of the smoother. Other fields of the object are ignored, so the output of
+// WriteJSON reads back as its series.
+func ReadJSON(r io.Reader) (*Series, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var y jsonFloats
+		if err := json.Unmarshal(data, &y); err != nil {
+			return nil, err
+		}
+		return &Series{Y: y}, nil
+	}
+
+	var s jsonSeries
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if s.Y == nil {
+		return nil, errors.New(`json series has no "y"`)
+	}
+	if s.X != nil && len(s.X) != len(s.Y) {
+		return nil, fmt.Errorf("json series has %d positions and %d values", len(s.X), len(s.Y))
+	}
+	if s.W != nil && len(s.W) != len(s.Y) {
+		return nil, fmt.Errorf("json series has %d weights and %d values", len(s.W), len(s.Y))
+	}
+	return &Series{X: s.X, Y: s.Y, W: s.W}, nil
+}
+
+// Smooth is the smooth of a series with one smoothing parameter.
+type Smooth struct {
+	Lambda float64
+	Order  int
+	Z      []float64
+}
+
+// jsonSmooth is the JSON form of a Smooth.
+type jsonSmooth struct {
+	Lambda float64    `json:"lambda"`
+	Order  int        `json:"order"`
+	Z      jsonFloats `json:"z"`
+}
+
+// WriteJSON writes the series s and its smooths to w as a JSON object {"x": [...], "y": [...], "smooths":
+// [{"lambda": ..., "order": ..., "z": [...]}, ...]}, leaving out x when s has no positions and w, the weights,
+// when it has none. NaN values are written as null.
+func WriteJSON(w io.Writer, s *Series, smooths []Smooth) error {
+	out := struct {
+		jsonSeries
+		Smooths []jsonSmooth `json:"smooths"`
+	}{jsonSeries: jsonSeries{X: s.X, Y: s.Y, W: s.W}, Smooths: make([]jsonSmooth, len(smooths))}
+	for i, sm := range smooths {
+		out.Smooths[i] = jsonSmooth{Lambda: sm.Lambda, Order: sm.Order, Z: sm.Z}
+	}
+	return json.NewEncoder(w).Encode(out)
+}
@@ -0,0 +1,74 @@
+package dataio
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestReadJSON(t *testing.T) {
+	s, err := ReadJSON(strings.NewReader(" [1, 2.5, null, -4]\n"))
+	if err != nil {
+		t.Fatalf("Failed to read an array: %v", err)
+	}
+	if s.X != nil || len(s.Y) != 4 || s.Y[1] != 2.5 || !math.IsNaN(s.Y[2]) || s.Y[3] != -4 {
+		t.Errorf("got x %v and y %v", s.X, s.Y)
+	}
+
+	s, err = ReadJSON(strings.NewReader(`{"x": [0, 1, 3], "y": [5, 6, 7]}`))
+	if err != nil {
+		t.Fatalf("Failed to read an object: %v", err)
+	}
+	if !equal(s.X, []float64{0, 1, 3}) || !equal(s.Y, []float64{5, 6, 7}) {
+		t.Errorf("got x %v and y %v", s.X, s.Y)
+	}
+
+	s, err = ReadJSON(strings.NewReader(`{"y": [5, 6], "w": [1, 0]}`))
+	if err != nil || !equal(s.W, []float64{1, 0}) {
+		t.Errorf("got %v and %v reading weights", s, err)
+	}
+
+	for _, input := range []string{
+		"",
+		"[1, \"a\"]",
+		`{"x": [1, 2]}`,
+		`{"x": [1], "y": [1, 2]}`,
+		`{"y": [1, 2], "w": [1]}`,
+	} {
+		if _, err := ReadJSON(strings.NewReader(input)); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	s := &Series{X: []float64{0, 2}, Y: []float64{1, math.NaN()}}
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, s, []Smooth{{Lambda: 10, Order: 2, Z: []float64{0.5, 0.75}}}); err != nil {
+		t.Fatalf("Failed to write json: %v", err)
+	}
+	want := `{"x":[0,2],"y":[1,null],"smooths":[{"lambda":10,"order":2,"z":[0.5,0.75]}]}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got %s, want %s", buf.String(), want)
+	}
+
+	back, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatalf("Failed to read the output back: %v", err)
+	}
+	if !equal(back.X, s.X) || back.Y[0] != 1 || !math.IsNaN(back.Y[1]) {
+		t.Errorf("read back x %v and y %v", back.X, back.Y)
+	}
+
+	buf.Reset()
+	if err := WriteJSON(&buf, &Series{Y: []float64{3}}, nil); err != nil {
+		t.Fatalf("Failed to write json: %v", err)
+	}
+	if got := buf.String(); got != "{\"y\":[3],\"smooths\":[]}\n" {
+		t.Errorf("got %s without positions", got)
+	}
+	if err := WriteJSON(&buf, &Series{Y: []float64{math.Inf(1)}}, nil); err == nil {
+		t.Errorf("expected an error for an infinite value")
+	}
+}
@@ -0,0 +1,113 @@
+package dataio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/file"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+)
+
+// ReaderAtSeeker is the random access a Parquet file is read with, such as an *os.File or a *bytes.Reader.
+type ReaderAtSeeker interface {
+	io.ReaderAt
+	io.Seeker
+}
+
+// ParquetOptions selects the columns ReadParquet reads.
+type ParquetOptions struct {
+	// X and Y select the columns of the sample positions and of the values, by column name, the dotted path for
+	// nested columns, or, if no column has that name, by zero-based index. An empty X reads no positions, an empty
+	// Y the first column that is not X.
+	X, Y string
+	// W selects the column of the weights of the values in the same way, an empty W reads no weights.
+	W string
+}
+
+// ReadParquet reads a series from a Parquet file, taking the values and optionally the sample positions and
+// weights from the columns selected by opts. Only the selected columns are read. They must hold integers,
+// floating point numbers, timestamps or dates, which read as seconds since the Unix epoch; null values read as
+// NaN.
+func ReadParquet(r ReaderAtSeeker, opts ParquetOptions) (*Series, error) {
+	pf, err := file.NewParquetReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer pf.Close()
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		return nil, err
+	}
+
+	sc := pf.MetaData().Schema
+	names := make([]string, sc.NumColumns())
+	for i := range names {
+		names[i] = sc.Column(i).Path()
+	}
+	xCol := -1
+	if opts.X != "" {
+		if xCol, err = column(names, opts.X); err != nil {
+			return nil, fmt.Errorf("parquet: %w", err)
+		}
+	}
+	yCol := 0
+	if xCol == 0 {
+		yCol = 1
+	}
+	if opts.Y != "" {
+		if yCol, err = column(names, opts.Y); err != nil {
+			return nil, fmt.Errorf("parquet: %w", err)
+		}
+	}
+	if yCol >= len(names) {
+		return nil, errors.New("parquet file has no value column")
+	}
+	wCol := -1
+	if opts.W != "" {
+		if wCol, err = column(names, opts.W); err != nil {
+			return nil, fmt.Errorf("parquet: %w", err)
+		}
+	}
+
+	s := &Series{}
+	if s.Y, err = readParquetColumn(fr, yCol, names[yCol]); err != nil {
+		return nil, err
+	}
+	if xCol >= 0 {
+		if s.X, err = readParquetColumn(fr, xCol, names[xCol]); err != nil {
+			return nil, err
+		}
+	}
+	if wCol >= 0 {
+		if s.W, err = readParquetColumn(fr, wCol, names[wCol]); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// readParquetColumn reads the leaf column col, named name, of the file read by fr as float64 values.
+func readParquetColumn(fr *pqarrow.FileReader, col int, name string) ([]float64, error) {
+	rowGroups := make([]int, fr.ParquetReader().NumRowGroups())
+	for i := range rowGroups {
+		rowGroups[i] = i
+	}
+	tbl, err := fr.ReadRowGroups(context.Background(), []int{col}, rowGroups)
+	if err != nil {
+		return nil, err
+	}
+	defer tbl.Release()
+	values := make([]float64, 0, tbl.NumRows())
+	for _, chunk := range tbl.Column(0).Data().Chunks() {
+		if values, err = appendArrowFloats(values, chunk); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parquet column %q: %w", name, err)
+	}
+	return values, nil
+}
@@ -0,0 +1,69 @@
+package dataio
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+)
+
+// parquetFile returns a Parquet file with an int64 column t, a float64 column v with a null value and a string
+// column label.
+func parquetFile(t *testing.T) *bytes.Reader {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "t", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "v", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "label", Type: arrow.BinaryTypes.String},
+	}, nil)
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	b.Field(0).(*array.Int64Builder).AppendValues([]int64{0, 1, 3}, nil)
+	b.Field(1).(*array.Float64Builder).AppendValues([]float64{1.5, 0, 2}, []bool{true, false, true})
+	b.Field(2).(*array.StringBuilder).AppendValues([]string{"a", "b", "c"}, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+	tbl := array.NewTableFromRecords(schema, []arrow.Record{rec})
+	defer tbl.Release()
+
+	var buf bytes.Buffer
+	if err := pqarrow.WriteTable(tbl, &buf, 2, nil, pqarrow.DefaultWriterProps()); err != nil {
+		t.Fatalf("Failed to write parquet: %v", err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestReadParquet(t *testing.T) {
+	s, err := ReadParquet(parquetFile(t), ParquetOptions{X: "t", Y: "v"})
+	if err != nil {
+		t.Fatalf("Failed to read parquet: %v", err)
+	}
+	if !equal(s.X, []float64{0, 1, 3}) || len(s.Y) != 3 || s.Y[0] != 1.5 || !math.IsNaN(s.Y[1]) || s.Y[2] != 2 {
+		t.Errorf("got x %v and y %v", s.X, s.Y)
+	}
+
+	s, err = ReadParquet(parquetFile(t), ParquetOptions{})
+	if err != nil || s.X != nil || !equal(s.Y, []float64{0, 1, 3}) {
+		t.Errorf("got %v and %v with the default columns", s, err)
+	}
+	if s, err = ReadParquet(parquetFile(t), ParquetOptions{X: "t"}); err != nil || len(s.Y) != 3 || s.Y[2] != 2 {
+		t.Errorf("got %v and %v with the default value column", s, err)
+	}
+
+	if s, err = ReadParquet(parquetFile(t), ParquetOptions{Y: "v", W: "t"}); err != nil || !equal(s.W, []float64{0, 1, 3}) {
+		t.Errorf("got %v and %v reading weights", s, err)
+	}
+
+	for _, opts := range []ParquetOptions{{Y: "pressure"}, {W: "label"}, {Y: "label"}, {Y: "7"}} {
+		if _, err := ReadParquet(parquetFile(t), opts); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+	if _, err := ReadParquet(bytes.NewReader([]byte("not parquet")), ParquetOptions{}); err == nil {
+		t.Errorf("expected an error for a file that is not parquet")
+	}
+}
@@ -0,0 +1,50 @@
+package dataio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the timestamp layouts of ParseTime that are not layouts of time.Parse.
+const (
+	// TimeRFC3339 is the layout of time.RFC3339, with optional fractional seconds.
+	TimeRFC3339 = "rfc3339"
+	// TimeUnix and TimeUnixMilli are seconds and milliseconds since the Unix epoch, possibly fractional.
+	TimeUnix      = "unix"
+	TimeUnixMilli = "unixmilli"
+)
+
+// excelEpoch is the Excel serial number of the Unix epoch in the 1900 date system.
+const excelEpoch = 25569
+
+// ParseTime parses the timestamp s in the given layout into seconds since the Unix epoch, the sample positions
+// that timestamps are read as. The layout is TimeRFC3339, TimeUnix, TimeUnixMilli or else a layout of time.Parse,
+// such as "2006-01-02 15:04"; times without a zone are taken as UTC.
+func ParseTime(s, layout string) (float64, error) {
+	s = strings.TrimSpace(s)
+	switch layout {
+	case TimeUnix, TimeUnixMilli:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a %s timestamp", s, layout)
+		}
+		if layout == TimeUnixMilli {
+			v /= 1e3
+		}
+		return v, nil
+	case TimeRFC3339:
+		layout = time.RFC3339
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return 0, err
+	}
+	return unixSeconds(t), nil
+}
+
+// unixSeconds returns t in seconds since the Unix epoch.
+func unixSeconds(t time.Time) float64 {
+	return float64(t.Unix()) + float64(t.Nanosecond())/1e9
+}
@@ -0,0 +1,39 @@
+package dataio
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		s, layout string
+		want      float64
+	}{
+		{"1970-01-02T00:00:00Z", TimeRFC3339, 86400},
+		{"1970-01-01T01:00:00.5+01:00", TimeRFC3339, 0.5},
+		{" 1700000000.25 ", TimeUnix, 1700000000.25},
+		{"1500", TimeUnixMilli, 1.5},
+		{"1970-01-01 00:02", "2006-01-02 15:04", 120},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.s, tt.layout)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseTime(%q, %q) = %v, %v, want %v", tt.s, tt.layout, got, err, tt.want)
+		}
+	}
+	for _, tt := range []struct{ s, layout string }{{"yesterday", TimeRFC3339}, {"x", TimeUnix}, {"1970-01-01", "15:04"}} {
+		if _, err := ParseTime(tt.s, tt.layout); err == nil {
+			t.Errorf("ParseTime(%q, %q): expected an error", tt.s, tt.layout)
+		}
+	}
+
+	s, err := ReadCSV(strings.NewReader("t,v\n1970-01-01T00:00:10Z,1\n1970-01-01T00:01:00Z,2\n"), CSVOptions{X: "t", XTime: TimeRFC3339})
+	if err != nil || !equal(s.X, []float64{10, 60}) {
+		t.Errorf("got %v and %v reading timestamps from csv", s, err)
+	}
+	_, err = ReadCSV(strings.NewReader("t,v\nnoon,1\n"), CSVOptions{X: "t", XTime: TimeRFC3339})
+	if err == nil || !strings.Contains(err.Error(), `line 2, column "t"`) {
+		t.Errorf("got %v, want the line and column of the bad timestamp", err)
+	}
+}
@@ -0,0 +1,145 @@
+package dataio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXOptions selects the sheet and columns ReadXLSX reads.
+type XLSXOptions struct {
+	// Sheet is the name of the sheet, empty for the first one.
+	Sheet string
+	// X and Y select the columns of the sample positions and of the values by header name, by zero-based index or
+	// by column letter such as B, tried in that order. An empty X reads no positions, an empty Y the first column
+	// that is not X.
+	X, Y string
+	// W selects the column of the weights of the values in the same way, an empty W reads no weights.
+	W string
+	// XTime, if not empty, reads the positions as timestamps: cells holding a number as Excel dates, which are
+	// serial numbers, and the others as text in this layout of ParseTime.
+	XTime string
+}
+
+// ReadXLSX reads a series from a sheet of an Excel workbook whose first row is a header, taking the values and
+// optionally the sample positions and weights from the columns selected by opts. The cells are read as stored,
+// without their number format, so dates read as Excel serial numbers unless XTime is set. Empty cells read as NaN.
+func ReadXLSX(r io.Reader, opts XLSXOptions) (*Series, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheet := opts.Sheet
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	}
+	rows, err := f.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("sheet %q has no header", sheet)
+	}
+	header := rows[0]
+
+	xCol := -1
+	if opts.X != "" {
+		if xCol, err = xlsxColumn(header, opts.X); err != nil {
+			return nil, err
+		}
+	}
+	yCol := 0
+	if xCol == 0 {
+		yCol = 1
+	}
+	if opts.Y != "" {
+		if yCol, err = xlsxColumn(header, opts.Y); err != nil {
+			return nil, err
+		}
+	}
+	if yCol >= len(header) && opts.Y == "" {
+		return nil, errors.New("sheet has no value column")
+	}
+	wCol := -1
+	if opts.W != "" {
+		if wCol, err = xlsxColumn(header, opts.W); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Series{}
+	for i, row := range rows[1:] {
+		y, err := parseCell(row, yCol, i+2)
+		if err != nil {
+			return nil, err
+		}
+		s.Y = append(s.Y, y)
+		if xCol >= 0 {
+			x, err := parseTimeCell(row, xCol, i+2, opts.XTime)
+			if err != nil {
+				return nil, err
+			}
+			s.X = append(s.X, x)
+		}
+		if wCol >= 0 {
+			w, err := parseCell(row, wCol, i+2)
+			if err != nil {
+				return nil, err
+			}
+			s.W = append(s.W, w)
+		}
+	}
+	return s, nil
+}
+
+// xlsxColumn returns the index of the column selected by name, index or letter in header.
+func xlsxColumn(header []string, sel string) (int, error) {
+	if i, err := column(header, sel); err == nil {
+		return i, nil
+	}
+	if strings.Trim(strings.ToUpper(sel), "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "" {
+		if n, err := excelize.ColumnNameToNumber(sel); err == nil {
+			return n - 1, nil
+		}
+	}
+	return 0, fmt.Errorf("sheet has no column %q", sel)
+}
+
+// parseCell parses the cell in column col of row, the row numbered line in the sheet. A missing or empty cell
+// is NaN.
+func parseCell(row []string, col, line int) (float64, error) {
+	if col >= len(row) || strings.TrimSpace(row[col]) == "" {
+		return math.NaN(), nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(row[col]), 64)
+	if err != nil {
+		name, _ := excelize.CoordinatesToCellName(col+1, line)
+		return 0, fmt.Errorf("cell %s: %q is not a number", name, row[col])
+	}
+	return v, nil
+}
+
+// parseTimeCell parses the cell in column col of row, the row numbered line in the sheet, as a timestamp in
+// layout unless it holds a number, which is an Excel date, or as a number when layout is empty.
+func parseTimeCell(row []string, col, line int, layout string) (float64, error) {
+	v, err := parseCell(row, col, line)
+	if layout == "" || err == nil {
+		if layout != "" {
+			// days since the end of 1899, in the 1900 date system of Excel
+			v = (v - excelEpoch) * 86400
+		}
+		return v, err
+	}
+	if v, err = ParseTime(row[col], layout); err != nil {
+		name, _ := excelize.CoordinatesToCellName(col+1, line)
+		return 0, fmt.Errorf("cell %s: %w", name, err)
+	}
+	return v, nil
+}
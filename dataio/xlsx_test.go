@@ -0,0 +1,81 @@
+package dataio
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// workbook returns an xlsx workbook with a first sheet Notes and a sheet Trace holding a header, three rows of
+// time and intensity with an empty cell, and a label column.
+func workbook(t *testing.T) *bytes.Reader {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", "Notes"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.NewSheet("Trace"); err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{
+		{"time", "intensity", "label"},
+		{0, 1.5, "a"},
+		{0.5, nil, "b"},
+		{2, 3.25, "c"},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Trace", cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.SetCellValue("Notes", "A1", "see the trace sheet"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatalf("Failed to write the workbook: %v", err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestReadXLSX(t *testing.T) {
+	s, err := ReadXLSX(workbook(t), XLSXOptions{Sheet: "Trace", X: "time", Y: "intensity"})
+	if err != nil {
+		t.Fatalf("Failed to read the workbook: %v", err)
+	}
+	if !equal(s.X, []float64{0, 0.5, 2}) || len(s.Y) != 3 || s.Y[0] != 1.5 || !math.IsNaN(s.Y[1]) || s.Y[2] != 3.25 {
+		t.Errorf("got x %v and y %v", s.X, s.Y)
+	}
+	if s, err := ReadXLSX(workbook(t), XLSXOptions{Sheet: "Trace", X: "A", Y: "1"}); err != nil || len(s.X) != 3 || s.Y[2] != 3.25 {
+		t.Errorf("got %v and %v selecting by letter and index", s, err)
+	}
+	if s, err := ReadXLSX(workbook(t), XLSXOptions{Sheet: "Trace", Y: "intensity", W: "A"}); err != nil || !equal(s.W, []float64{0, 0.5, 2}) {
+		t.Errorf("got %v and %v reading weights", s, err)
+	}
+
+	if s, err := ReadXLSX(workbook(t), XLSXOptions{Sheet: "Trace", X: "time", XTime: TimeRFC3339}); err != nil || !equal(s.X, []float64{-excelEpoch * 86400, (0.5 - excelEpoch) * 86400, (2 - excelEpoch) * 86400}) {
+		t.Errorf("got %v and %v reading Excel dates", s, err)
+	}
+	if _, err := ReadXLSX(workbook(t), XLSXOptions{Sheet: "Trace", X: "label", XTime: TimeRFC3339}); err == nil || !strings.Contains(err.Error(), "cell C2") {
+		t.Errorf("got %v, want the cell of the bad timestamp", err)
+	}
+
+	if s, err := ReadXLSX(workbook(t), XLSXOptions{}); err != nil || len(s.Y) != 0 {
+		t.Errorf("got %v and %v for the first sheet without data", s, err)
+	}
+	for _, opts := range []XLSXOptions{
+		{Sheet: "Missing"},
+		{Sheet: "Trace", Y: "label"},
+		{Sheet: "Trace", Y: "pressure"},
+		{Sheet: "Trace", W: "label"},
+	} {
+		if _, err := ReadXLSX(workbook(t), opts); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+}
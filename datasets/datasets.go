@@ -0,0 +1,130 @@
+// Package datasets provides the standard datasets used by the examples and tests of the smoother, so they do not
+// depend on paths relative to the repository root.
+//
+// The NMR spectrum and wood data are the example series from the supporting info of Paul H.C. Eilers "A Perfect
+// Smoother". The synthetic step is generated: a noisy unit step, useful to judge how well edges are preserved.
+package datasets
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed data
+var files embed.FS
+
+// dataset describes a named dataset and how to produce its content.
+type dataset struct {
+	filename string
+	content  func() ([]byte, error)
+}
+
+var registry = map[string]dataset{
+	"nmr":            {filename: "nmr.dat", content: embedded("data/nmr.dat")},
+	"wood":           {filename: "wood.txt", content: embedded("data/wood.txt")},
+	"synthetic-step": {filename: "synthetic-step.dat", content: syntheticStep},
+}
+
+// embedded returns a content function reading the embedded file name.
+func embedded(name string) func() ([]byte, error) {
+	return func() ([]byte, error) { return files.ReadFile(name) }
+}
+
+// syntheticStep creates 500 samples of a unit step half way through, with seeded Gaussian noise of standard
+// deviation 0.1 so the dataset is the same on every call.
+func syntheticStep() ([]byte, error) {
+	rng := rand.New(rand.NewSource(1))
+	var buf bytes.Buffer
+	for i := 0; i < 500; i++ {
+		v := rng.NormFloat64() * 0.1
+		if i >= 250 {
+			v++
+		}
+		fmt.Fprintf(&buf, "%.6f\n", v)
+	}
+	return buf.Bytes(), nil
+}
+
+// Names returns the names of the available datasets in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup returns the named dataset.
+func lookup(name string) (dataset, error) {
+	ds, ok := registry[name]
+	if !ok {
+		return dataset{}, fmt.Errorf("unknown dataset %q, available: %s", name, strings.Join(Names(), ", "))
+	}
+	return ds, nil
+}
+
+// Load returns the values of the named dataset.
+func Load(name string) ([]float64, error) {
+	ds, err := lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ds.content()
+	if err != nil {
+		return nil, err
+	}
+
+	var numbers []float64
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		number, err := strconv.ParseFloat(strings.TrimSpace(scanner.Text()), 64)
+		if err != nil {
+			// ignore any non-numeric lines
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, scanner.Err()
+}
+
+// CacheDir returns the default directory Fetch writes datasets into, below the user cache directory.
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-whittaker-eilers", "datasets"), nil
+}
+
+// Fetch writes the named dataset as a file with one value per line into dir, or into CacheDir when dir is
+// empty, and returns the path of the file.
+func Fetch(name, dir string) (string, error) {
+	ds, err := lookup(name)
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		if dir, err = CacheDir(); err != nil {
+			return "", err
+		}
+	}
+	content, err := ds.content()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, ds.filename)
+	return path, os.WriteFile(path, content, 0o644)
+}
@@ -0,0 +1,34 @@
+package datasets
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	lengths := map[string]int{"nmr": 1024, "wood": 320, "synthetic-step": 500}
+	for _, name := range Names() {
+		data, err := Load(name)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", name, err)
+		}
+		if len(data) != lengths[name] {
+			t.Errorf("%s: got %d values, want %d", name, len(data), lengths[name])
+		}
+	}
+
+	if _, err := Load("missing"); err == nil {
+		t.Errorf("expected an error for an unknown dataset")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	dir := t.TempDir()
+	path, err := Fetch("synthetic-step", dir)
+	if err != nil {
+		t.Fatalf("Failed to fetch dataset: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("fetched file missing: %v", err)
+	}
+}
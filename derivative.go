@@ -0,0 +1,50 @@
+package smoother
+
+import (
+	"fmt"
+)
+
+// Derivative applies the Whittaker-Eilers smoothing function to y and returns the first derivative of the smooth
+// for samples spaced dx apart, as needed for peak finding and rate estimation. The derivative is estimated with
+// central differences of the smooth, and second order one-sided differences at the ends of the series.
+func Derivative(y []float64, lambda float64, d int, dx float64) ([]float64, error) {
+	return DerivativeN(y, lambda, d, 1, dx)
+}
+
+// DerivativeN is like Derivative, but returns the derivative of the given order of the smooth, such as the second
+// derivative for curvature and inflection analysis. The first derivative is differentiated order times, each
+// time scaled by dx, so the result is in units of y per dx^order. A penalty order d above the derivative order
+// keeps the estimate itself smooth, since a penalty of order d pushes the d-th derivative towards zero.
+func DerivativeN(y []float64, lambda float64, d int, order int, dx float64) ([]float64, error) {
+	if order < 1 {
+		return nil, fmt.Errorf("derivative order %d must be at least 1", order)
+	}
+	if !(dx > 0) {
+		return nil, fmt.Errorf("sample spacing %f must be positive", dx)
+	}
+	z, err := WESmoother(y, lambda, d)
+	if err != nil {
+		return nil, err
+	}
+	if len(z) < 3 {
+		return nil, fmt.Errorf("%w: series of length %d too short for a derivative", ErrSeriesTooShort, len(z))
+	}
+
+	for k := 0; k < order; k++ {
+		z = gradient(z, dx)
+	}
+	return z, nil
+}
+
+// gradient returns the first derivative of z for samples spaced dx apart, using central differences within the
+// series and second order one-sided differences at its ends. z must hold at least three values.
+func gradient(z []float64, dx float64) []float64 {
+	m := len(z)
+	g := make([]float64, m)
+	for i := 1; i < m-1; i++ {
+		g[i] = (z[i+1] - z[i-1]) / (2 * dx)
+	}
+	g[0] = (-3*z[0] + 4*z[1] - z[2]) / (2 * dx)
+	g[m-1] = (3*z[m-1] - 4*z[m-2] + z[m-3]) / (2 * dx)
+	return g
+}
@@ -0,0 +1,44 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDerivative(t *testing.T) {
+	rng := rand.New(rand.NewSource(17))
+	n := 500
+	dx := 0.01
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)*dx) + rng.NormFloat64()*0.01
+	}
+
+	deriv, err := Derivative(y, 1e5, 3, dx)
+	if err != nil {
+		t.Fatalf("Failed to apply Derivative: %v", err)
+	}
+	for i := 50; i < n-50; i++ {
+		if want := math.Cos(float64(i) * dx); math.Abs(deriv[i]-want) > 0.05 {
+			t.Fatalf("index %d: got %f, want %f", i, deriv[i], want)
+		}
+	}
+
+	second, err := DerivativeN(y, 1e7, 3, 2, dx)
+	if err != nil {
+		t.Fatalf("Failed to apply DerivativeN: %v", err)
+	}
+	for i := 50; i < n-50; i++ {
+		if want := -math.Sin(float64(i) * dx); math.Abs(second[i]-want) > 0.1 {
+			t.Fatalf("index %d: second derivative %f, want %f", i, second[i], want)
+		}
+	}
+
+	if _, err := DerivativeN(y, 1e5, 3, 0, dx); err == nil {
+		t.Errorf("expected an error for a derivative order of 0")
+	}
+	if _, err := Derivative(y, 1e5, 3, 0); err == nil {
+		t.Errorf("expected an error for a zero sample spacing")
+	}
+}
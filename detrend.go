@@ -0,0 +1,17 @@
+package smoother
+
+// Detrend applies the Whittaker-Eilers smoothing function to y and returns the smooth as the trend together with
+// the residual y - trend, the detrended signal. A large lambda gives a slowly varying trend and keeps all but the
+// slowest variation in the residual.
+func Detrend(y []float64, lambda float64, d int) (trend, residual []float64, err error) {
+	trend, err = WESmoother(y, lambda, d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	residual = make([]float64, len(y))
+	for i := range y {
+		residual[i] = y[i] - trend[i]
+	}
+	return trend, residual, nil
+}
@@ -0,0 +1,37 @@
+package smoother
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDetrend(t *testing.T) {
+	n := 400
+	y := make([]float64, n)
+	for i := range y {
+		// a fast oscillation riding on a slow quadratic drift
+		x := float64(i) / float64(n)
+		y[i] = 5*x*x + 0.5*math.Sin(float64(i)/2)
+	}
+
+	trend, residual, err := Detrend(y, 1e6, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply Detrend: %v", err)
+	}
+
+	for i := range y {
+		if math.Abs(trend[i]+residual[i]-y[i]) > 1e-9 {
+			t.Fatalf("index %d: trend and residual do not add up to the input", i)
+		}
+	}
+	for i := 50; i < n-50; i++ {
+		x := float64(i) / float64(n)
+		if math.Abs(trend[i]-5*x*x) > 0.1 {
+			t.Fatalf("index %d: trend %f, want %f", i, trend[i], 5*x*x)
+		}
+	}
+
+	if _, _, err := Detrend([]float64{1}, 1e6, 2); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
@@ -0,0 +1,137 @@
+package smoother
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// smootherStateVersion is the version of the encoding of a Smoother, bumped whenever smootherState changes.
+// Version 2 added InPlace.
+const smootherStateVersion = 2
+
+// smootherState is the encoded form of a Smoother. Band matrices are stored as their size, bandwidth and data.
+type smootherState struct {
+	Version   int
+	Lambda    float64
+	Order     int
+	Weights   []float64
+	Robust    bool
+	Tuning    float64
+	NaNPolicy NaNPolicy
+	InPlace   bool
+	Penalty   *bandState
+	Factor    *bandState
+}
+
+// bandState is the encoded form of a band matrix.
+type bandState struct {
+	N, Bandwidth int
+	Data         []float64
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, so a Smoother can be stored with encoding/gob. The encoding
+// holds the settings and the cached factorization, so a worker process loading a smoother prepared for
+// fixed-size frames can smooth its first frame without factorizing.
+func (s *Smoother) MarshalBinary() ([]byte, error) {
+	state := smootherState{
+		Version:   smootherStateVersion,
+		Lambda:    s.lambda,
+		Order:     s.d,
+		Weights:   s.weights,
+		Robust:    s.robust,
+		Tuning:    s.tuning,
+		NaNPolicy: s.nanPolicy,
+		InPlace:   s.inPlace,
+	}
+	if s.penalty != nil {
+		state.Penalty = &bandState{N: s.penalty.n, Bandwidth: s.penalty.bw, Data: s.penalty.data}
+	}
+	if chol := s.chol.Load(); chol != nil {
+		state.Factor = &bandState{N: chol.n, Bandwidth: chol.bw, Data: chol.data}
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the state of s by that of a Smoother encoded
+// by MarshalBinary. The settings are validated as New does, and a factorization whose size or bandwidth does not
+// fit them, or whose diagonal is not positive, is rejected.
+func (s *Smoother) UnmarshalBinary(data []byte) error {
+	var state smootherState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+	// older versions decode with the zero value of the fields they lack
+	if state.Version < 1 || state.Version > smootherStateVersion {
+		return fmt.Errorf("unsupported smoother encoding version %d", state.Version)
+	}
+
+	opts := []Option{WithLambda(state.Lambda), WithOrder(state.Order), WithNaNPolicy(state.NaNPolicy)}
+	if state.Weights != nil {
+		opts = append(opts, WithWeights(state.Weights))
+	}
+	if state.Robust {
+		opts = append(opts, WithRobust(state.Tuning))
+	}
+	if state.InPlace {
+		opts = append(opts, WithInPlace())
+	}
+	if state.Penalty != nil {
+		if err := state.Penalty.check(); err != nil {
+			return fmt.Errorf("penalty: %w", err)
+		}
+		// gonum stores the upper band row by row like symBand
+		opts = append(opts, WithPenalty(mat.NewSymBandDense(state.Penalty.N, state.Penalty.Bandwidth, state.Penalty.Data)))
+	}
+	if state.Factor != nil {
+		opts = append(opts, withoutFactorization())
+	}
+	restored, err := New(opts...)
+	if err != nil {
+		return err
+	}
+
+	if state.Factor != nil {
+		f := state.Factor
+		if err := f.check(); err != nil {
+			return fmt.Errorf("factorization: %w", err)
+		}
+		bw := restored.d
+		if restored.penalty != nil {
+			bw = restored.penalty.bw
+		}
+		for i := 0; i < f.N; i++ {
+			if v := f.Data[i*(f.Bandwidth+1)+f.Bandwidth]; !(v > 0) || math.IsInf(v, 1) {
+				return fmt.Errorf("factorization: %w", ErrNotPositiveDefinite)
+			}
+		}
+		if f.Bandwidth != bw {
+			return fmt.Errorf("factorization bandwidth %d, want %d", f.Bandwidth, bw)
+		}
+		if (restored.weights != nil && f.N != len(restored.weights)) || (restored.penalty != nil && f.N != restored.penalty.n) {
+			return fmt.Errorf("%w: factorization of size %d does not fit the smoother", ErrLengthMismatch, f.N)
+		}
+		restored.chol.Store(&bandCholesky{n: f.N, bw: f.Bandwidth, data: f.Data})
+	}
+
+	s.lambda, s.d, s.weights = restored.lambda, restored.d, restored.weights
+	s.robust, s.tuning, s.nanPolicy, s.penalty = restored.robust, restored.tuning, restored.nanPolicy, restored.penalty
+	s.inPlace = restored.inPlace
+	s.chol.Store(restored.chol.Load())
+	return nil
+}
+
+// check returns an error unless the data fits the size and bandwidth of the band matrix.
+func (b *bandState) check() error {
+	if b.N < 1 || b.Bandwidth < 0 || len(b.Data) != b.N*(b.Bandwidth+1) {
+		return fmt.Errorf("%d values do not fit a band matrix of size %d and bandwidth %d", len(b.Data), b.N, b.Bandwidth)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package smoother
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"testing"
+)
+
+func TestSmootherEncoding(t *testing.T) {
+	n := 400
+	y := make([]float64, n)
+	w := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/30) + 0.2*math.Cos(float64(i)*1.9)
+		w[i] = 1 + float64(i%3)
+	}
+	L, err := PenaltyMatrix(n, 1, 3)
+	if err != nil {
+		t.Fatalf("Failed to create penalty matrix: %v", err)
+	}
+
+	for name, opts := range map[string][]Option{
+		"plain":    {WithLambda(50)},
+		"weighted": {WithLambda(50), WithWeights(w)},
+		"robust":   {WithLambda(50), WithRobust(3), WithNaNPolicy(NaNZeroWeight)},
+		"penalty":  {WithLambda(50), WithPenalty(L)},
+	} {
+		s, err := New(opts...)
+		if err != nil {
+			t.Fatalf("%s: Failed to create Smoother: %v", name, err)
+		}
+		want, err := s.Smooth(y)
+		if err != nil {
+			t.Fatalf("%s: Failed to smooth: %v", name, err)
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(s); err != nil {
+			t.Fatalf("%s: Failed to encode: %v", name, err)
+		}
+		var restored Smoother
+		if err := gob.NewDecoder(&buf).Decode(&restored); err != nil {
+			t.Fatalf("%s: Failed to decode: %v", name, err)
+		}
+		if name != "robust" && restored.chol.Load() == nil {
+			t.Errorf("%s: factorization not restored", name)
+		}
+		if restored.Lambda() != 50 || restored.robust != s.robust || restored.tuning != s.tuning || restored.nanPolicy != s.nanPolicy {
+			t.Errorf("%s: settings not restored", name)
+		}
+		z, err := restored.Smooth(y)
+		if err != nil {
+			t.Fatalf("%s: Failed to smooth with the restored Smoother: %v", name, err)
+		}
+		for i := range want {
+			if z[i] != want[i] {
+				t.Fatalf("%s, index %d: got %f, want %f", name, i, z[i], want[i])
+			}
+		}
+	}
+
+	// settings that do not change the smooth are restored as well
+	s, err := New(WithInPlace())
+	if err != nil {
+		t.Fatalf("Failed to create Smoother: %v", err)
+	}
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	var inPlace Smoother
+	if err := inPlace.UnmarshalBinary(data); err != nil || !inPlace.inPlace {
+		t.Errorf("in place setting not restored: %v", err)
+	}
+
+	// a restored factorization is used right away
+	s, err = New(WithLambda(50))
+	if err != nil {
+		t.Fatalf("Failed to create Smoother: %v", err)
+	}
+	if _, err := s.Smooth(y); err != nil {
+		t.Fatalf("Failed to smooth: %v", err)
+	}
+	data, err = s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	var restored Smoother
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	dst := make([]float64, n)
+	allocs := testing.AllocsPerRun(10, func() {
+		if err := restored.SmoothTo(dst, y); err != nil {
+			t.Fatalf("Failed to smooth: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("restored SmoothTo allocated %f times per call, want 0", allocs)
+	}
+
+	if err := restored.UnmarshalBinary(data[:len(data)/2]); err == nil {
+		t.Errorf("expected an error for truncated data")
+	}
+	chol := restored.chol.Load()
+	chol.data[chol.bw] = -1
+	if data, err = restored.MarshalBinary(); err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if err := new(Smoother).UnmarshalBinary(data); err == nil {
+		t.Errorf("expected an error for a corrupt factorization")
+	}
+}
@@ -0,0 +1,38 @@
+package smoother
+
+import (
+	"unsafe"
+)
+
+// WESmootherOf applies the Whittaker-Eilers smoothing function to a series of any floating point type, so
+// float32 telemetry can be smoothed without converting it to float64 first. The band factorization of the
+// system is stored in the element type of y as well, which for float32 halves the memory of the whole solve.
+//
+// Sums are accumulated in float64, but a float32 factor only carries about seven digits: the error of the smooth
+// grows like lambda * 4^d times the float32 rounding error, around 1e-4 relative to the data for lambda = 100 and
+// d = 2. Lambdas for which lambda * 4^d exceeds 1e6 are rejected for float32.
+func WESmootherOf[T Float](y []T, lambda float64, d int) ([]T, error) {
+	if err := checkLength(len(y), d); err != nil {
+		return nil, err
+	}
+	if err := checkLambda(lambda); err != nil {
+		return nil, err
+	}
+	bound := maxConditionedLambda
+	var zero T
+	if unsafe.Sizeof(zero) == 4 {
+		bound = maxConditionedLambda32
+	}
+	if err := checkConditioning(lambda, d, bound); err != nil {
+		return nil, err
+	}
+	if err := checkFinite(y); err != nil {
+		return nil, err
+	}
+
+	chol, err := factorizeScaledBand(differencePenaltyBandOf[T](len(y), d), lambda)
+	if err != nil {
+		return nil, err
+	}
+	return chol.solve(y), nil
+}
@@ -0,0 +1,63 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherOf(t *testing.T) {
+	rng := rand.New(rand.NewSource(31))
+	n := 500
+	y := make([]float64, n)
+	y32 := make([]float32, n)
+	for i := range y {
+		y32[i] = float32(10*math.Sin(float64(i)/40) + rng.NormFloat64())
+		y[i] = float64(y32[i])
+	}
+	want, err := WESmoother(y, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+
+	z, err := WESmootherOf(y, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to smooth float64: %v", err)
+	}
+	for i := range want {
+		if math.Abs(z[i]-want[i]) > 1e-9 {
+			t.Fatalf("float64 index %d: got %f, want %f", i, z[i], want[i])
+		}
+	}
+
+	z32, err := WESmootherOf(y32, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to smooth float32: %v", err)
+	}
+	for i := range want {
+		if math.Abs(float64(z32[i])-want[i]) > 1e-3 {
+			t.Fatalf("float32 index %d: got %f, want %f", i, z32[i], want[i])
+		}
+	}
+
+	// named float types work too
+	type celsius float32
+	c := make([]celsius, n)
+	for i := range c {
+		c[i] = celsius(y32[i])
+	}
+	if _, err := WESmootherOf(c, 100, 2); err != nil {
+		t.Errorf("Failed to smooth named float type: %v", err)
+	}
+
+	if _, err := WESmootherOf(y32, 1e5, 2); err == nil {
+		t.Errorf("expected an error for a lambda too large for float32")
+	}
+	if _, err := WESmootherOf(y, 1e5, 2); err != nil {
+		t.Errorf("unexpected error for a large lambda in float64: %v", err)
+	}
+	y32[3] = float32(math.NaN())
+	if _, err := WESmootherOf(y32, 100, 2); err == nil {
+		t.Errorf("expected an error for a NaN value")
+	}
+}
@@ -0,0 +1,112 @@
+package smoother
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// WESmootherGaps applies the Whittaker-Eilers smoothing function separately to every contiguous segment of y
+// instead of bridging gaps, where bridging would invent a smooth curve through data that was never measured.
+// Segments are split wherever the spacing of the increasing positions x exceeds maxGap and around every run of
+// NaN values in y, which stay NaN in the result.
+//
+// Each segment is smoothed with divided differences of order d over its positions, as for unevenly spaced data,
+// so a suitable lambda depends on the units of x. When x is nil the samples are taken as equally spaced at
+// distance 1 and only NaN runs split the series. Segments of d values or fewer cannot be smoothed and are
+// returned unchanged.
+func WESmootherGaps(x, y []float64, lambda float64, d int, maxGap float64) ([]float64, error) {
+	if x != nil && len(x) != len(y) {
+		return nil, fmt.Errorf("%w: x has %d values, y has %d", ErrLengthMismatch, len(x), len(y))
+	}
+	if !(maxGap > 0) {
+		return nil, fmt.Errorf("maximum gap %f must be positive", maxGap)
+	}
+	if err := checkLength(len(y), d); err != nil {
+		return nil, err
+	}
+	if err := checkLambda(lambda); err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(x); i++ {
+		if !(x[i] > x[i-1]) {
+			return nil, fmt.Errorf("x is not strictly increasing at index %d", i)
+		}
+	}
+
+	z := make([]float64, len(y))
+	start := -1
+	for i := 0; i <= len(y); i++ {
+		split := i == len(y) || math.IsNaN(y[i]) || (x != nil && start >= 0 && x[i]-x[i-1] > maxGap)
+		if split && start >= 0 {
+			if err := smoothSegment(x, y, z, start, i, lambda, d); err != nil {
+				return nil, err
+			}
+			start = -1
+		}
+		if i == len(y) {
+			break
+		}
+		if math.IsNaN(y[i]) {
+			z[i] = y[i]
+			continue
+		}
+		if math.IsInf(y[i], 0) {
+			return nil, fmt.Errorf("value at index %d is infinite", i)
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	return z, nil
+}
+
+// smoothSegment smooths y[lo:hi] into z[lo:hi] with divided differences over x[lo:hi], or plain differences
+// when x is nil, solving the band system in O(n·d²). A segment too short for order d is copied unchanged.
+func smoothSegment(x, y, z []float64, lo, hi int, lambda float64, d int) error {
+	if hi-lo <= d {
+		copy(z[lo:hi], y[lo:hi])
+		return nil
+	}
+
+	var P *symBand
+	if x == nil {
+		P = pooledPenaltyBand(hi-lo, d)
+		defer putBand(P)
+	} else {
+		P = dividedPenaltyBand(x[lo:hi], d)
+	}
+	chol, err := pooledFactor(context.Background(), P, lambda, nil)
+	if err != nil {
+		return err
+	}
+	defer putFactor(chol)
+	chol.solveTo(z[lo:hi], y[lo:hi])
+	return nil
+}
+
+// dividedPenaltyBand returns D'D as a band matrix of bandwidth d, where D is the divided difference matrix of
+// order d over the increasing positions x that dividedDifferenceMatrix builds densely. Row r of D weighs x[r+j]
+// by the product of 1 / (x[r+j] - x[r+m]) over the other m from 0 to d, so every row is found in O(d²) and the
+// band in O(n·d²).
+func dividedPenaltyBand(x []float64, d int) *symBand {
+	n := len(x)
+	P := newSymBand(n, d)
+	c := make([]float64, d+1)
+	for r := 0; r+d < n; r++ {
+		for j := range c {
+			c[j] = 1
+			for m := 0; m <= d; m++ {
+				if m != j {
+					c[j] /= x[r+j] - x[r+m]
+				}
+			}
+		}
+		for a := 0; a <= d; a++ {
+			for b := a; b <= d; b++ {
+				P.add(r+a, r+b, c[a]*c[b])
+			}
+		}
+	}
+	return P
+}
@@ -0,0 +1,100 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherGaps(t *testing.T) {
+	rng := rand.New(rand.NewSource(10))
+	var x, y []float64
+	// two segments at different levels separated by a gap in x
+	for i := 0; i < 100; i++ {
+		x = append(x, float64(i))
+		y = append(y, 1+rng.NormFloat64()*0.1)
+	}
+	for i := 0; i < 100; i++ {
+		x = append(x, 200+float64(i))
+		y = append(y, 5+rng.NormFloat64()*0.1)
+	}
+
+	z, err := WESmootherGaps(x, y, 1e4, 2, 10)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherGaps: %v", err)
+	}
+	// without bridging the levels hold right up to the gap
+	if math.Abs(z[99]-1) > 0.15 || math.Abs(z[100]-5) > 0.15 {
+		t.Errorf("segments bridged across the gap: %f before, %f after", z[99], z[100])
+	}
+
+	// NaN runs split the series and stay NaN
+	y[50], y[51] = math.NaN(), math.NaN()
+	if z, err = WESmootherGaps(nil, y, 1e4, 2, 10); err != nil {
+		t.Fatalf("Failed to apply WESmootherGaps: %v", err)
+	}
+	if !math.IsNaN(z[50]) || !math.IsNaN(z[51]) {
+		t.Errorf("missing values not kept: %f, %f", z[50], z[51])
+	}
+	if math.IsNaN(z[49]) || math.IsNaN(z[52]) {
+		t.Errorf("segments around the missing values not smoothed")
+	}
+
+	// a single isolated value cannot be smoothed and is kept
+	y[53] = math.NaN()
+	if z, err = WESmootherGaps(nil, y, 1e4, 2, 10); err != nil {
+		t.Fatalf("Failed to apply WESmootherGaps: %v", err)
+	}
+	if z[52] != y[52] {
+		t.Errorf("isolated value changed from %f to %f", y[52], z[52])
+	}
+
+	if _, err := WESmootherGaps(x, y, 1e4, 2, 0); err == nil {
+		t.Errorf("expected an error for a zero gap")
+	}
+	x[10] = x[9]
+	if _, err := WESmootherGaps(x, y, 1e4, 2, 10); err == nil {
+		t.Errorf("expected an error for repeated positions")
+	}
+	if _, err := WESmootherGaps(x[1:], y, 1e4, 2, 10); err == nil {
+		t.Errorf("expected an error for mismatched lengths")
+	}
+}
+
+func TestWESmootherGapsLong(t *testing.T) {
+	// the band of the divided difference penalty matches the dense one it replaces
+	x := []float64{0, 0.5, 1.7, 2, 3.1, 4.6, 5, 7.2, 8, 8.3}
+	for d := 1; d <= 3; d++ {
+		D := dividedDifferenceMatrix(x, d)
+		rows, _ := D.Dims()
+		ones := make([]float64, rows)
+		for i := range ones {
+			ones[i] = 1
+		}
+		want := gramMatrix(D, ones)
+		P := dividedPenaltyBand(x, d)
+		for i := range x {
+			for j := range x {
+				if got := P.at(i, j); math.Abs(got-want.At(i, j)) > 1e-12*math.Max(1, math.Abs(want.At(i, j))) {
+					t.Fatalf("order %d: entry (%d, %d) is %g, want %g", d, i, j, got, want.At(i, j))
+				}
+			}
+		}
+	}
+
+	// a dense system of this length would take 8 TB
+	n := 1_000_000
+	xs := make([]float64, n)
+	y := make([]float64, n)
+	for i := range xs {
+		xs[i] = float64(i) + 0.3*float64(i%3)
+		y[i] = math.Sin(xs[i] / 1000)
+	}
+	z, err := WESmootherGaps(xs, y, 1e4, 2, math.Inf(1))
+	if err != nil {
+		t.Fatalf("Failed to smooth a long series: %v", err)
+	}
+	if math.Abs(z[n/2]-y[n/2]) > 1e-2 {
+		t.Errorf("got %g at the middle of a long series, want about %g", z[n/2], y[n/2])
+	}
+}
@@ -0,0 +1,42 @@
+package smoother
+
+import (
+	"context"
+)
+
+// HatDiagonal returns the leverage of every point of a series of length n smoothed with lambda and order d, the
+// diagonal of the hat matrix H = (I + λD'D)⁻¹ that maps the data onto the smooth. A leverage close to one means
+// the smooth follows that point closely, a leverage close to zero means it is mostly determined by its neighbours.
+// The hat matrix does not depend on the data itself. The diagonal is computed from the band Cholesky factor in
+// O(n·d²), without forming the hat matrix.
+func HatDiagonal(lambda float64, d, n int) ([]float64, error) {
+	if err := checkLength(n, d); err != nil {
+		return nil, err
+	}
+	if err := checkLambda(lambda); err != nil {
+		return nil, err
+	}
+	P := pooledPenaltyBand(n, d)
+	defer putBand(P)
+	chol, err := pooledFactor(context.Background(), P, lambda, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer putFactor(chol)
+	return chol.inverseDiagonal(), nil
+}
+
+// EffectiveDF returns the effective degrees of freedom of smoothing a series of length n with lambda and order d,
+// the trace of the hat matrix. It ranges from n when lambda is zero down to d, the number of parameters of the
+// polynomial a very large lambda reduces the smooth to, and is a direct measure of how much smoothing happened.
+func EffectiveDF(lambda float64, d, n int) (float64, error) {
+	h, err := HatDiagonal(lambda, d, n)
+	if err != nil {
+		return 0, err
+	}
+	var df float64
+	for _, v := range h {
+		df += v
+	}
+	return df, nil
+}
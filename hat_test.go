@@ -0,0 +1,76 @@
+package smoother
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEffectiveDF(t *testing.T) {
+	n := 200
+	prev := float64(n)
+	for _, lambda := range []float64{1e-6, 1, 100, 1e4, 1e10} {
+		df, err := EffectiveDF(lambda, 2, n)
+		if err != nil {
+			t.Fatalf("Failed to compute EffectiveDF: %v", err)
+		}
+		if df > prev || df < 2-1e-6 {
+			t.Errorf("lambda %g: effective df %f not in [2, %f]", lambda, df, prev)
+		}
+		prev = df
+	}
+	if math.Abs(prev-2) > 0.01 {
+		t.Errorf("large lambda: got %f effective df, want 2", prev)
+	}
+
+	h, err := HatDiagonal(100, 2, n)
+	if err != nil {
+		t.Fatalf("Failed to compute HatDiagonal: %v", err)
+	}
+	for i, v := range h {
+		if v <= 0 || v >= 1 {
+			t.Errorf("index %d: leverage %f not in (0, 1)", i, v)
+		}
+	}
+	if h[0] <= h[n/2] {
+		t.Errorf("end point leverage %f not above center leverage %f", h[0], h[n/2])
+	}
+
+	if _, err := EffectiveDF(100, 2, 2); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
+
+func TestHatDiagonalLong(t *testing.T) {
+	n, lambda, d := 60, 50.0, 3
+	sys, err := factorizePenalized(nil, penaltyMatrix(n, lambda, d), nil)
+	if err != nil {
+		t.Fatalf("Failed to factorize: %v", err)
+	}
+	want, err := sys.inverseDiagonal()
+	if err != nil {
+		t.Fatalf("Failed to invert: %v", err)
+	}
+	got, err := HatDiagonal(lambda, d, n)
+	if err != nil {
+		t.Fatalf("Failed to compute HatDiagonal: %v", err)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-12 {
+			t.Errorf("index %d: leverage %g, want %g of the dense inverse", i, got[i], want[i])
+		}
+	}
+
+	// a dense hat matrix of this length would take 8 TB
+	long := 1_000_000
+	df, err := EffectiveDF(1e4, 2, long)
+	if err != nil {
+		t.Fatalf("Failed to compute EffectiveDF of a long series: %v", err)
+	}
+	if !(df > 2) || !(df < float64(long)) {
+		t.Errorf("got effective df %g for %d values", df, long)
+	}
+	cv, err := CrossValidationError(make([]float64, long), 1e4, 2)
+	if err != nil || cv != 0 {
+		t.Errorf("got cross-validation error %g and %v for a long zero series", cv, err)
+	}
+}
@@ -0,0 +1,109 @@
+package smoother
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// maxVarianceIterations caps the number of weight updates of WESmootherHeteroscedastic.
+const maxVarianceIterations = 50
+
+// varianceTolerance is the largest relative change of any local variance at which WESmootherHeteroscedastic
+// stops.
+const varianceTolerance = 1e-6
+
+// varianceFloor bounds the local variances of WESmootherHeteroscedastic from below, relative to their mean, so
+// no sample gets an unbounded weight where the smoothed squared residuals dip to zero.
+const varianceFloor = 1e-6
+
+// WESmootherHeteroscedastic applies the Whittaker-Eilers smoothing function to data whose noise level varies
+// along the series. It alternates between smoothing y with lambda and weights 1/v, and re-estimating the local
+// noise variance v from the squared residuals, until v settles. The variance is fitted to the squared residuals by
+// penalized gamma regression on the logarithm of v with varianceLambda, which keeps it positive and smooth. Noisy
+// stretches end up weighted down and smoothed harder than quiet ones.
+//
+// It returns the smooth and the final local variance. varianceLambda should be large enough that v follows the
+// noise level rather than individual residuals. The weights are scaled to average one, so lambda smooths about as
+// much overall as it does for WESmoother. Every update of the variance is reported to the ProgressFunc set by
+// WithProgress.
+func WESmootherHeteroscedastic(y []float64, lambda, varianceLambda float64, d int, opts ...FitOption) (smooth, variance []float64, err error) {
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, nil, err
+	}
+	if err := checkLambda(varianceLambda); err != nil {
+		return nil, nil, err
+	}
+
+	n := len(y)
+	P := penaltyMatrix(n, lambda, d)
+
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1
+	}
+	squared := make([]float64, n)
+	progress := newProgressReporter(opts)
+	for iter := 0; iter < maxVarianceIterations; iter++ {
+		if smooth, err = solvePenalized(y, w, P, nil); err != nil {
+			return nil, nil, err
+		}
+		var mean float64
+		for i := range y {
+			squared[i] = (y[i] - smooth[i]) * (y[i] - smooth[i])
+			mean += squared[i] / float64(n)
+		}
+		if mean == 0 {
+			// the smooth fits exactly, there is no noise to weigh by
+			return smooth, squared, nil
+		}
+		next, err := localVariance(squared, mean, varianceLambda, d)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		change, total := 0.0, 0.0
+		for i := range next {
+			next[i] = math.Max(next[i], varianceFloor*mean)
+			// the first update starts from the constant variance of the uniform weights
+			prev := mean
+			if variance != nil {
+				prev = variance[i]
+			}
+			change = math.Max(change, math.Abs(next[i]-prev)/prev)
+			w[i] = 1 / next[i]
+			total += w[i]
+		}
+		for i := range w {
+			w[i] *= float64(n) / total
+		}
+		if err := progress.report(change); err != nil {
+			return nil, nil, err
+		}
+		if variance != nil && change < varianceTolerance {
+			return smooth, next, nil
+		}
+		variance = next
+	}
+	return nil, nil, fmt.Errorf("local variance did not settle in %d iterations", maxVarianceIterations)
+}
+
+// localVariance fits a smooth positive variance to the squared residuals with penalized gamma regression and a
+// log link, whose working weights are all one. mean is the mean of squared and is used as the starting value and
+// to floor the result.
+func localVariance(squared []float64, mean, lambda float64, d int) ([]float64, error) {
+	eta, err := penalizedIRLS(context.Background(), squared, lambda, d, family{
+		start: func(int, float64) float64 { return math.Log(mean) },
+		working: func(_ int, r2, eta float64) (float64, float64) {
+			mu := math.Exp(eta)
+			return eta + (r2-mu)/mu, 1
+		},
+	}, newProgressReporter(nil))
+	if err != nil {
+		return nil, err
+	}
+	for i := range eta {
+		eta[i] = math.Max(math.Exp(eta[i]), varianceFloor*mean)
+	}
+	return eta, nil
+}
@@ -0,0 +1,48 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherHeteroscedastic(t *testing.T) {
+	rng := rand.New(rand.NewSource(23))
+	n := 400
+	truth := make([]float64, n)
+	noise := make([]float64, n)
+	y := make([]float64, n)
+	for i := range y {
+		// the noise level grows steadily along the series
+		truth[i] = math.Sin(float64(i) / 35)
+		noise[i] = 0.02 + 0.5*float64(i)/float64(n)
+		y[i] = truth[i] + rng.NormFloat64()*noise[i]
+	}
+
+	smooth, variance, err := WESmootherHeteroscedastic(y, 1000, 1e6, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherHeteroscedastic: %v", err)
+	}
+	for _, i := range []int{50, 200, 350} {
+		if got := math.Sqrt(variance[i]); math.Abs(got-noise[i]) > 0.3*noise[i]+0.02 {
+			t.Errorf("index %d: noise level %f, want about %f", i, got, noise[i])
+		}
+	}
+
+	plain, err := WESmoother(y, 1000, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	var errWeighted, errPlain float64
+	for i := range y {
+		errWeighted += (smooth[i] - truth[i]) * (smooth[i] - truth[i])
+		errPlain += (plain[i] - truth[i]) * (plain[i] - truth[i])
+	}
+	if errWeighted >= errPlain {
+		t.Errorf("reweighted fit error %f not below the unweighted %f", errWeighted, errPlain)
+	}
+
+	if _, _, err := WESmootherHeteroscedastic(y, 1000, -1, 2); err == nil {
+		t.Errorf("expected an error for a negative variance lambda")
+	}
+}
@@ -0,0 +1,24 @@
+package smoother
+
+import (
+	"fmt"
+)
+
+// Integral applies the Whittaker-Eilers smoothing function to y and integrates the smooth with the trapezoidal
+// rule for samples spaced dx apart, for dose or exposure computations on noisy measurements. It returns the area
+// under the smooth and the cumulative integral, which starts at zero at the first sample and ends at the area.
+func Integral(y []float64, lambda float64, d int, dx float64) (float64, []float64, error) {
+	if !(dx > 0) {
+		return 0, nil, fmt.Errorf("sample spacing %f must be positive", dx)
+	}
+	z, err := WESmoother(y, lambda, d)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	cumulative := make([]float64, len(z))
+	for i := 1; i < len(z); i++ {
+		cumulative[i] = cumulative[i-1] + (z[i-1]+z[i])*dx/2
+	}
+	return cumulative[len(z)-1], cumulative, nil
+}
@@ -0,0 +1,35 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestIntegral(t *testing.T) {
+	rng := rand.New(rand.NewSource(19))
+	n := 1001
+	dx := math.Pi / float64(n-1)
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)*dx) + rng.NormFloat64()*0.05
+	}
+
+	area, cumulative, err := Integral(y, 1e4, 2, dx)
+	if err != nil {
+		t.Fatalf("Failed to apply Integral: %v", err)
+	}
+	if math.Abs(area-2) > 0.01 {
+		t.Errorf("area: got %f, want 2", area)
+	}
+	if cumulative[0] != 0 || cumulative[n-1] != area {
+		t.Errorf("cumulative integral runs from %f to %f, want 0 to %f", cumulative[0], cumulative[n-1], area)
+	}
+	if mid := cumulative[n/2]; math.Abs(mid-1) > 0.01 {
+		t.Errorf("cumulative integral at the center: got %f, want 1", mid)
+	}
+
+	if _, _, err := Integral(y, 1e4, 2, -1); err == nil {
+		t.Errorf("expected an error for a negative sample spacing")
+	}
+}
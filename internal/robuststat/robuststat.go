@@ -0,0 +1,43 @@
+// Package robuststat holds the robust statistics shared by the smoother package and its detectors: the median,
+// the median absolute deviation and the noise scale derived from it.
+package robuststat
+
+import (
+	"math"
+	"sort"
+)
+
+// madScale converts the median absolute deviation into a consistent estimate of the standard deviation of
+// normally distributed noise.
+const madScale = 1.4826
+
+// Median returns the median of v without modifying it, NaN if v is empty.
+func Median(v []float64) float64 {
+	if len(v) == 0 {
+		return math.NaN()
+	}
+	s := make([]float64, len(v))
+	copy(s, v)
+	sort.Float64s(s)
+	m := len(s) / 2
+	if len(s)%2 == 1 {
+		return s[m]
+	}
+	return (s[m-1] + s[m]) / 2
+}
+
+// MedianAbsDeviation returns the median absolute deviation of v from its median.
+func MedianAbsDeviation(v []float64) float64 {
+	med := Median(v)
+	dev := make([]float64, len(v))
+	for i, x := range v {
+		dev[i] = math.Abs(x - med)
+	}
+	return Median(dev)
+}
+
+// Scale returns the robust estimate of the standard deviation of v, 1.4826 times its median absolute deviation,
+// which outliers hardly move.
+func Scale(v []float64) float64 {
+	return madScale * MedianAbsDeviation(v)
+}
@@ -0,0 +1,41 @@
+package robuststat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMedian(t *testing.T) {
+	v := []float64{5, 1, 4, 2}
+	if got := Median(v); got != 3 {
+		t.Errorf("got median %g, want 3", got)
+	}
+	if v[0] != 5 || v[3] != 2 {
+		t.Errorf("Median modified its input: %v", v)
+	}
+	if got := Median([]float64{7, 1, 3}); got != 3 {
+		t.Errorf("got median %g, want 3", got)
+	}
+	if got := Median(nil); !math.IsNaN(got) {
+		t.Errorf("got median %g of no values, want NaN", got)
+	}
+	if got := MedianAbsDeviation([]float64{1, 2, 3, 4, 100}); got != 1 {
+		t.Errorf("got median absolute deviation %g, want 1", got)
+	}
+}
+
+func TestScale(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	v := make([]float64, 20000)
+	for i := range v {
+		v[i] = 2 * rng.NormFloat64()
+	}
+	// outliers leave the estimate of the standard deviation of the noise alone
+	for i := 0; i < len(v); i += 50 {
+		v[i] = 1e6
+	}
+	if got := Scale(v); math.Abs(got-2) > 0.1 {
+		t.Errorf("got scale %g, want about 2", got)
+	}
+}
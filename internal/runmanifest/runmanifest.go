@@ -0,0 +1,55 @@
+// Package runmanifest reads and writes the manifest that records the inputs, parameters and outputs of a batch
+// smoothing run, so two runs can be compared later.
+package runmanifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the name of the manifest file within a run directory.
+const FileName = "manifest.json"
+
+// Manifest records a batch smoothing run.
+type Manifest struct {
+	// Tool and Version identify the program that produced the run.
+	Tool    string `json:"tool"`
+	Version string `json:"version,omitempty"`
+	// Entries holds one entry per smoothed output file.
+	Entries []Entry `json:"entries"`
+}
+
+// Entry records how a single output file of a run was produced.
+type Entry struct {
+	// Input is the path of the data file that was smoothed.
+	Input string `json:"input"`
+	// Output is the path of the smoothed data, relative to the run directory.
+	Output string `json:"output"`
+	// Lambdas and Order are the smoothing parameters used.
+	Lambdas []float64 `json:"lambdas"`
+	Order   int       `json:"order"`
+}
+
+// Read reads the manifest of the run in dir.
+func Read(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Write writes m as the manifest of the run in dir.
+func Write(dir string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, FileName), append(data, '\n'), 0o644)
+}
@@ -0,0 +1,133 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+)
+
+// maxL1Iterations caps the number of ADMM iterations of WESmootherL1.
+const maxL1Iterations = 20000
+
+// l1RhoScale is the ADMM penalty parameter of WESmootherL1 relative to lambda. A fixed ρ = 10·λ converges much
+// faster than ρ = λ on typical trend filtering problems.
+const l1RhoScale = 10
+
+// l1AbsTolerance and l1RelTolerance are the absolute (relative to the RMS of y) and relative tolerances of the
+// ADMM stopping criterion of WESmootherL1.
+const (
+	l1AbsTolerance = 1e-5
+	l1RelTolerance = 1e-4
+)
+
+// WESmootherL1 is the ℓ1 sibling of WESmoother: it minimizes ½·|y - z|² + lambda·|D z|₁ instead of penalizing the
+// squared differences. Penalizing the absolute differences drives most of them to exactly zero, so with d = 2 the
+// result is a piecewise linear trend with sharp kinks (ℓ1 trend filtering), and with d = 1 it is piecewise constant.
+//
+// The problem is solved with the alternating direction method of multipliers (ADMM). The system I + ρ·D'D is
+// factorized once and reused in every iteration.
+func WESmootherL1(y []float64, lambda float64, d int) ([]float64, error) {
+	return l1TrendFilter(y, lambda, d, maxL1Iterations, l1AbsTolerance, l1RelTolerance)
+}
+
+// l1TrendFilter implements WESmootherL1 with the given iteration cap and stopping tolerances.
+func l1TrendFilter(y []float64, lambda float64, d int, iterations int, absTol, relTol float64) ([]float64, error) {
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, err
+	}
+	m := len(y)
+	rho := l1RhoScale * lambda
+	sys, err := factorizePenalized(nil, penaltyMatrix(m, rho, d), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	coeffs := differenceCoefficients(d)
+	rows := m - d
+	alpha := make([]float64, rows)
+	u := make([]float64, rows)
+	step := make([]float64, rows)
+	rhs := make([]float64, m)
+	dual := make([]float64, m)
+	dualScale := make([]float64, m)
+	var ms float64
+	for _, v := range y {
+		ms += v * v
+	}
+	abs := absTol * math.Sqrt(ms/float64(m))
+
+	for iter := 0; iter < iterations; iter++ {
+		// z-update: (I + ρ D'D) z = y + ρ D' (α - u)
+		for i := 0; i < rows; i++ {
+			step[i] = rho * (alpha[i] - u[i])
+		}
+		transposeDifference(coeffs, step, rhs)
+		for i := range rhs {
+			rhs[i] += y[i]
+		}
+		z, err := sys.solve(rhs)
+		if err != nil {
+			return nil, err
+		}
+
+		// α-update by soft thresholding, then the dual update
+		var primal, dzNorm, alphaNorm float64
+		for i := 0; i < rows; i++ {
+			var dz float64
+			for j, c := range coeffs {
+				dz += c * z[i+j]
+			}
+			prev := alpha[i]
+			alpha[i] = softThreshold(dz+u[i], lambda/rho)
+			u[i] += dz - alpha[i]
+			step[i] = alpha[i] - prev
+			primal += (dz - alpha[i]) * (dz - alpha[i])
+			dzNorm += dz * dz
+			alphaNorm += alpha[i] * alpha[i]
+		}
+
+		// Stop on the primal residual D z - α and the dual residual ρ D' (α - α_prev), with the tolerances
+		// described by Boyd et al., "Distributed Optimization and Statistical Learning via the Alternating
+		// Direction Method of Multipliers", section 3.3.1
+		transposeDifference(coeffs, step, dual)
+		transposeDifference(coeffs, u, dualScale)
+		epsPrimal := math.Sqrt(float64(rows))*abs + relTol*math.Sqrt(math.Max(dzNorm, alphaNorm))
+		epsDual := math.Sqrt(float64(m))*abs + relTol*rho*norm(dualScale)
+		if math.Sqrt(primal) <= epsPrimal && rho*norm(dual) <= epsDual {
+			return z, nil
+		}
+	}
+
+	return nil, fmt.Errorf("l1 trend filter did not converge in %d iterations", iterations)
+}
+
+// transposeDifference sets dst to D' * v, where D is the difference matrix with the given row coefficients.
+func transposeDifference(coeffs, v, dst []float64) {
+	for i := range dst {
+		dst[i] = 0
+	}
+	for i, vi := range v {
+		for j, c := range coeffs {
+			dst[i+j] += c * vi
+		}
+	}
+}
+
+// norm returns the Euclidean norm of v.
+func norm(v []float64) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
+
+// softThreshold shrinks v towards zero by t, returning zero when |v| <= t.
+func softThreshold(v, t float64) float64 {
+	switch {
+	case v > t:
+		return v - t
+	case v < -t:
+		return v + t
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherL1(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	n := 200
+	y := make([]float64, n)
+	for i := range y {
+		// a piecewise linear trend with a kink at the center
+		trend := float64(i) * 0.05
+		if i > n/2 {
+			trend = float64(n/2)*0.05 - float64(i-n/2)*0.1
+		}
+		y[i] = trend + rng.NormFloat64()*0.2
+	}
+
+	clean, err := WESmootherL1(y, 50, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherL1: %v", err)
+	}
+
+	// a run with far tighter tolerances and a larger iteration cap must agree with the default stopping point
+	exact, err := l1TrendFilter(y, 50, 2, 10*maxL1Iterations, l1AbsTolerance/1e3, l1RelTolerance/1e3)
+	if err != nil {
+		t.Fatalf("Failed to run tightened l1 trend filter: %v", err)
+	}
+	for i := range exact {
+		if math.Abs(clean[i]-exact[i]) > 1e-2 {
+			t.Fatalf("index %d: stopped at %f, converged value %f", i, clean[i], exact[i])
+		}
+	}
+
+	kinks := 0
+	for i := 0; i+2 < n; i++ {
+		if math.Abs(exact[i+2]-2*exact[i+1]+exact[i]) > 1e-6 {
+			kinks++
+		}
+	}
+	if kinks > 10 {
+		t.Errorf("trend is not piecewise linear: %d kinks", kinks)
+	}
+
+	for _, i := range []int{0, n / 2, n - 1} {
+		trend := float64(i) * 0.05
+		if i > n/2 {
+			trend = float64(n/2)*0.05 - float64(i-n/2)*0.1
+		}
+		if math.Abs(clean[i]-trend) > 0.5 {
+			t.Errorf("index %d: got %f, want about %f", i, clean[i], trend)
+		}
+	}
+
+	if _, err := WESmootherL1([]float64{1, 2}, 50, 2); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
@@ -0,0 +1,45 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+)
+
+// WESmootherLog applies the Whittaker-Eilers smoothing function to log(y) and returns the exponential of the
+// smooth, for strictly positive data such as concentrations with multiplicative noise. The result is positive
+// everywhere and relative deviations are weighed alike whatever the level of the signal.
+//
+// The exponential of the smooth of log(y) estimates the median of y rather than its mean. With biasCorrect the
+// smooth is multiplied by exp(σ²/2), where σ² is the noise variance of log(y) corrected by the effective degrees
+// of freedom, which estimates the mean for lognormal noise.
+func WESmootherLog(y []float64, lambda float64, d int, biasCorrect bool) ([]float64, error) {
+	logY := make([]float64, len(y))
+	for i, v := range y {
+		if !(v > 0) {
+			return nil, fmt.Errorf("value %f at index %d is not positive", v, i)
+		}
+		logY[i] = math.Log(v)
+	}
+
+	var z []float64
+	factor := 1.0
+	if biasCorrect {
+		smooth, h, err := smoothWithHat(logY, lambda, d)
+		if err != nil {
+			return nil, err
+		}
+		sigma2, _ := residualVariance(logY, smooth, nil, h, nil)
+		z, factor = smooth, math.Exp(sigma2/2)
+	} else {
+		smooth, err := WESmoother(logY, lambda, d)
+		if err != nil {
+			return nil, err
+		}
+		z = smooth
+	}
+
+	for i := range z {
+		z[i] = math.Exp(z[i]) * factor
+	}
+	return z, nil
+}
@@ -0,0 +1,62 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherLog(t *testing.T) {
+	rng := rand.New(rand.NewSource(14))
+	n := 500
+	truth := make([]float64, n)
+	y := make([]float64, n)
+	sigma := 0.5
+	for i := range y {
+		// a decay over three orders of magnitude with multiplicative lognormal noise of mean one
+		truth[i] = 1000 * math.Exp(-float64(i)/70)
+		y[i] = truth[i] * math.Exp(rng.NormFloat64()*sigma-sigma*sigma/2)
+	}
+
+	median, err := WESmootherLog(y, 1e4, 2, false)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherLog: %v", err)
+	}
+	mean, err := WESmootherLog(y, 1e4, 2, true)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherLog: %v", err)
+	}
+
+	var medianErr, meanErr float64
+	for i := range y {
+		if median[i] <= 0 {
+			t.Fatalf("index %d: smooth %f not positive", i, median[i])
+		}
+		medianErr += math.Log(median[i] / truth[i])
+		meanErr += math.Log(mean[i] / truth[i])
+	}
+	medianErr /= float64(n)
+	meanErr /= float64(n)
+	// without correction the smooth sits at the median, exp(-σ²/2) below the mean
+	if math.Abs(medianErr+sigma*sigma/2) > 0.08 {
+		t.Errorf("uncorrected smooth off by %f in log, want about %f", medianErr, -sigma*sigma/2)
+	}
+	if math.Abs(meanErr) > 0.08 {
+		t.Errorf("bias corrected smooth off by %f in log, want about 0", meanErr)
+	}
+
+	// the correction is a single factor applied to the whole smooth
+	if ratio := mean[0] / median[0]; math.Abs(math.Log(ratio)-sigma*sigma/2) > 0.03 {
+		t.Errorf("correction factor %f, want about %f", ratio, math.Exp(sigma*sigma/2))
+	}
+	for i := range y {
+		if math.Abs(mean[i]/median[i]-mean[0]/median[0]) > 1e-9 {
+			t.Fatalf("index %d: correction factor differs", i)
+		}
+	}
+
+	y[10] = 0
+	if _, err := WESmootherLog(y, 1e4, 2, false); err == nil {
+		t.Errorf("expected an error for a value that is not positive")
+	}
+}
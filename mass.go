@@ -0,0 +1,67 @@
+package smoother
+
+import (
+	"fmt"
+)
+
+// Mass selects the total of a series that WESmootherConserved preserves.
+type Mass int
+
+const (
+	// MassSum is the plain sum of the values, the total count of a histogram.
+	MassSum Mass = iota
+	// MassTrapezoid is the integral by the trapezoid rule for equally spaced samples, the total flux of a
+	// sampled density.
+	MassTrapezoid
+)
+
+// WESmootherConserved applies the Whittaker-Eilers smoothing function to y under the constraint that the smooth
+// has the same total as y, as needed for histograms, counts or flux where the total matters. The constraint is
+// imposed exactly with a Lagrange multiplier: the smooth is z = A⁻¹(y + μc), where A = I + λD'D, c holds the
+// weight of every sample in the total and μ is chosen so that c'z = c'y.
+//
+// A penalty of order 1 or more does not penalize a constant, so the plain smoother already preserves the sum up to
+// rounding and MassSum only makes that exact. The trapezoid integral weighs the end samples by one half and is
+// not preserved without the constraint.
+func WESmootherConserved(y []float64, lambda float64, d int, mass Mass) ([]float64, error) {
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, err
+	}
+	n := len(y)
+	c := make([]float64, n)
+	for i := range c {
+		c[i] = 1
+	}
+	switch mass {
+	case MassSum:
+	case MassTrapezoid:
+		c[0], c[n-1] = 0.5, 0.5
+	default:
+		return nil, fmt.Errorf("unknown mass %d", mass)
+	}
+
+	sys, err := factorizePenalized(nil, penaltyMatrix(n, lambda, d), nil)
+	if err != nil {
+		return nil, err
+	}
+	z, err := sys.solve(y)
+	if err != nil {
+		return nil, err
+	}
+	u, err := sys.solve(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var target, got, cu float64
+	for i := range y {
+		target += c[i] * y[i]
+		got += c[i] * z[i]
+		cu += c[i] * u[i]
+	}
+	mu := (target - got) / cu
+	for i := range z {
+		z[i] += mu * u[i]
+	}
+	return z, nil
+}
@@ -0,0 +1,58 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherConserved(t *testing.T) {
+	rng := rand.New(rand.NewSource(13))
+	n := 200
+	y := make([]float64, n)
+	for i := range y {
+		// a histogram whose mass sits against the left end
+		y[i] = 100*math.Exp(-float64(i)/20) + float64(rng.Intn(5))
+	}
+	total := func(v []float64, mass Mass) float64 {
+		var s float64
+		for _, x := range v {
+			s += x
+		}
+		if mass == MassTrapezoid {
+			s -= (v[0] + v[len(v)-1]) / 2
+		}
+		return s
+	}
+
+	plain, err := WESmoother(y, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	if math.Abs(total(plain, MassTrapezoid)-total(y, MassTrapezoid)) < 1e-3 {
+		t.Fatalf("expected the plain smooth to change the trapezoid integral")
+	}
+
+	for _, mass := range []Mass{MassSum, MassTrapezoid} {
+		z, err := WESmootherConserved(y, 100, 2, mass)
+		if err != nil {
+			t.Fatalf("Failed to apply WESmootherConserved: %v", err)
+		}
+		want := total(y, mass)
+		if got := total(z, mass); math.Abs(got-want) > 1e-9*want {
+			t.Errorf("mass %d: got total %f, want %f", mass, got, want)
+		}
+		for i := range z {
+			if math.Abs(z[i]-plain[i]) > 5 {
+				t.Fatalf("mass %d: index %d: constrained smooth %f far from the plain one %f", mass, i, z[i], plain[i])
+			}
+		}
+	}
+
+	if _, err := WESmootherConserved(y, 100, 2, Mass(7)); err == nil {
+		t.Errorf("expected an error for an unknown mass")
+	}
+	if _, err := WESmootherConserved([]float64{1, 2}, 100, 2, MassSum); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
@@ -0,0 +1,34 @@
+package smoother
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// DifferenceMatrix returns the (n-d) x n difference matrix D of order d as a gonum band matrix, so D * y gives the
+// differences of order d of a series y of length n. Row i holds the binomial coefficients of the difference in
+// columns i through i+d, so the matrix has no subdiagonals and d superdiagonals.
+func DifferenceMatrix(n, d int) (*mat.BandDense, error) {
+	if err := checkLength(n, d); err != nil {
+		return nil, err
+	}
+	return differenceMatrix(n, d), nil
+}
+
+// PenaltyMatrix returns the penalty λD'D of the Whittaker-Eilers smoother for a series of length n as a gonum
+// symmetric band matrix with bandwidth d, the building block for custom penalized estimators: the smooth solves
+// (W + λD'D) z = W y.
+func PenaltyMatrix(n int, lambda float64, d int) (*mat.SymBandDense, error) {
+	if err := checkLength(n, d); err != nil {
+		return nil, err
+	}
+	if err := checkLambda(lambda); err != nil {
+		return nil, err
+	}
+	P := differencePenaltyBand(n, d)
+	data := make([]float64, len(P.data))
+	for i, v := range P.data {
+		data[i] = lambda * v
+	}
+	// The upper band storage of gonum matches symBand row by row
+	return mat.NewSymBandDense(n, d, data), nil
+}
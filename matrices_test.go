@@ -0,0 +1,53 @@
+package smoother
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDifferenceMatrix(t *testing.T) {
+	for _, d := range []int{1, 2, 3} {
+		D, err := DifferenceMatrix(12, d)
+		if err != nil {
+			t.Fatalf("order %d: Failed to create difference matrix: %v", d, err)
+		}
+		coeffs := differenceCoefficients(d)
+		for i := 0; i < 12-d; i++ {
+			for j := 0; j < 12; j++ {
+				want := 0.0
+				if j >= i && j <= i+d {
+					want = coeffs[j-i]
+				}
+				if D.At(i, j) != want {
+					t.Fatalf("order %d: D(%d, %d) is %f, want %f", d, i, j, D.At(i, j), want)
+				}
+			}
+		}
+	}
+	if _, err := DifferenceMatrix(2, 2); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
+
+func TestPenaltyMatrix(t *testing.T) {
+	for _, d := range []int{1, 2, 3} {
+		P, err := PenaltyMatrix(15, 7, d)
+		if err != nil {
+			t.Fatalf("order %d: Failed to create penalty matrix: %v", d, err)
+		}
+		want := penaltyMatrix(15, 7, d)
+		for i := 0; i < 15; i++ {
+			for j := 0; j < 15; j++ {
+				if math.Abs(P.At(i, j)-want.At(i, j)) > 1e-9 {
+					t.Fatalf("order %d: P(%d, %d) = %f, want %f", d, i, j, P.At(i, j), want.At(i, j))
+				}
+			}
+		}
+		if _, k := P.SymBand(); k != d {
+			t.Errorf("order %d: bandwidth %d", d, k)
+		}
+	}
+	if _, err := PenaltyMatrix(10, -1, 2); err == nil {
+		t.Errorf("expected an error for a negative lambda")
+	}
+}
@@ -0,0 +1,58 @@
+package smoother
+
+import "fmt"
+
+// float64Bytes is the size of a float64 in bytes.
+const float64Bytes = 8
+
+// EstimateMemory returns the peak number of bytes WESmoother allocates, pooled workspace included, for a series
+// of length n and order d with the solver set by SetSolver, so services can reject a request before it runs out
+// of memory. Every solver is linear in n:
+//
+//   - the tridiagonal fast path for d = 1 takes the smooth and two workspace vectors,
+//   - the pentadiagonal fast path for d = 2 takes the smooth and three workspace vectors,
+//   - SolverLAPACK takes the smooth and the band of the system, which is factorized in place,
+//   - SolverBand takes the smooth, the penalty band, the system band and the factor.
+//
+// The estimate leaves out the input series and constant overhead. WESmootherInPlace takes the same less the
+// smooth. Other entry points factorize with SolverBand whatever the solver set, so they are bounded by its
+// estimate, where a band holds n·(d+1) values:
+//
+//   - WESmootherGaps takes the SolverBand estimate and WESmootherRefined one vector more,
+//   - HatDiagonal and EffectiveDF take one band more for the inverse, and CrossValidationError also a vector,
+//   - WESmootherBand and WESmootherDiagnostics take one band and a vector per series of their result more,
+//   - WESmootherBoundary takes the estimate of WESmoother for the padded series of at most 3n-2 values.
+//   - a StreamSmoother over a window of n samples keeps at most the SolverBand estimate for n while it pushes.
+//
+// Entry points that are not listed, such as the robust and penalized likelihood fits, solve dense systems of n²
+// values and are not covered.
+func EstimateMemory(n, d int) (int64, error) {
+	if err := checkLength(n, d); err != nil {
+		return 0, err
+	}
+	vec := int64(n) * float64Bytes
+	band := vec * int64(d+1)
+	switch s := CurrentSolver(); {
+	case s == SolverAuto && d == 1:
+		return 3 * vec, nil
+	case s == SolverAuto && d == 2:
+		return 4 * vec, nil
+	case s == SolverBand:
+		return vec + 3*band, nil
+	}
+	return vec + band, nil
+}
+
+// CheckMemory returns an error wrapping ErrMemoryLimit if smoothing a series of length n with order d takes more
+// than limit bytes by EstimateMemory. It checks WESmoother; a service using another of the entry points listed
+// there checks the bound given for it.
+func CheckMemory(n, d int, limit int64) error {
+	need, err := EstimateMemory(n, d)
+	if err != nil {
+		return err
+	}
+	if need > limit {
+		return fmt.Errorf("%w: smoothing %d values with order %d takes about %d bytes, the limit is %d", ErrMemoryLimit, n, d, need, limit)
+	}
+	return nil
+}
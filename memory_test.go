@@ -0,0 +1,120 @@
+package smoother
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"testing"
+)
+
+func TestEstimateMemory(t *testing.T) {
+	defer SetSolver(SolverAuto)
+	tests := []struct {
+		solver Solver
+		d      int
+		want   int64
+	}{
+		{SolverAuto, 1, 3 * 8 * 1000},
+		{SolverAuto, 2, 4 * 8 * 1000},
+		{SolverAuto, 3, 8*1000 + 8*1000*4},
+		{SolverLAPACK, 2, 8*1000 + 8*1000*3},
+		{SolverBand, 2, 8*1000 + 3*8*1000*3},
+	}
+	for _, tt := range tests {
+		if err := SetSolver(tt.solver); err != nil {
+			t.Fatalf("Failed to set solver: %v", err)
+		}
+		got, err := EstimateMemory(1000, tt.d)
+		if err != nil || got != tt.want {
+			t.Errorf("solver %d, order %d: got %d and %v, want %d", tt.solver, tt.d, got, err, tt.want)
+		}
+	}
+	if _, err := EstimateMemory(2, 2); !errors.Is(err, ErrSeriesTooShort) {
+		t.Errorf("got %v, want ErrSeriesTooShort", err)
+	}
+}
+
+func TestEstimateMemoryBound(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random under the race detector")
+	}
+	n := 50000
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = float64(i % 11)
+	}
+	for _, d := range []int{1, 2, 3} {
+		want, err := EstimateMemory(n, d)
+		if err != nil {
+			t.Fatalf("order %d: Failed to estimate: %v", d, err)
+		}
+		// a cold pool allocates every workspace, so the first smooth takes the whole estimate
+		runtime.GC()
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		if _, err := WESmoother(y, 100, d); err != nil {
+			t.Fatalf("order %d: Failed to smooth: %v", d, err)
+		}
+		runtime.ReadMemStats(&after)
+		if got := int64(after.TotalAlloc - before.TotalAlloc); got > want+64<<10 {
+			t.Errorf("order %d: allocated %d bytes, estimated %d", d, got, want)
+		}
+	}
+}
+
+func TestCheckMemory(t *testing.T) {
+	if err := CheckMemory(1000, 2, 1<<20); err != nil {
+		t.Errorf("got %v for a request within the limit", err)
+	}
+	if err := CheckMemory(1e7, 2, 1<<20); !errors.Is(err, ErrMemoryLimit) {
+		t.Errorf("got %v, want ErrMemoryLimit", err)
+	}
+}
+
+func TestEstimateMemoryEntryPoints(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random under the race detector")
+	}
+	defer SetSolver(SolverAuto)
+	if err := SetSolver(SolverBand); err != nil {
+		t.Fatalf("Failed to set solver: %v", err)
+	}
+	n, d := 50000, 3
+	y := make([]float64, n)
+	x := make([]float64, n)
+	for i := range y {
+		y[i] = float64(i % 11)
+		x[i] = float64(i) + 0.3*float64(i%3)
+	}
+	estimate, err := EstimateMemory(n, d)
+	if err != nil {
+		t.Fatalf("Failed to estimate: %v", err)
+	}
+	vec, band := int64(n)*8, int64(n*(d+1))*8
+
+	// the bounds of the documentation of EstimateMemory
+	tests := []struct {
+		name  string
+		bound int64
+		run   func() error
+	}{
+		{"WESmootherGaps", estimate, func() error { _, err := WESmootherGaps(x, y, 100, d, math.Inf(1)); return err }},
+		{"WESmootherRefined", estimate + vec, func() error { _, err := WESmootherRefined(y, 100, d, 2); return err }},
+		{"HatDiagonal", estimate + band, func() error { _, err := HatDiagonal(100, d, n); return err }},
+		{"CrossValidationError", estimate + band + vec, func() error { _, err := CrossValidationError(y, 100, d); return err }},
+		{"WESmootherBand", estimate + band + 4*vec, func() error { _, err := WESmootherBand(y, 100, d, 0.95); return err }},
+		{"WESmootherDiagnostics", estimate + band + 2*vec, func() error { _, err := WESmootherDiagnostics(y, 100, d); return err }},
+	}
+	for _, tt := range tests {
+		runtime.GC()
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		if err := tt.run(); err != nil {
+			t.Fatalf("%s: Failed to run: %v", tt.name, err)
+		}
+		runtime.ReadMemStats(&after)
+		if got := int64(after.TotalAlloc - before.TotalAlloc); got > tt.bound+64<<10 {
+			t.Errorf("%s: allocated %d bytes, bound %d", tt.name, got, tt.bound)
+		}
+	}
+}
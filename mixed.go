@@ -0,0 +1,44 @@
+package smoother
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Penalty is a single difference penalty term Lambda * D' * D, where D is the difference matrix of order Order.
+type Penalty struct {
+	Lambda float64
+	Order  int
+}
+
+// WESmootherMixed applies the Whittaker-Eilers smoothing function to y with a penalty that combines differences
+// of several orders, for example λ1·D1'D1 + λ2·D2'D2, in a single solve. A small first order term in addition to
+// the usual second order one tames the linear extrapolation a second order penalty produces at the series ends.
+func WESmootherMixed(y []float64, penalties ...Penalty) ([]float64, error) {
+	if len(penalties) == 0 {
+		return nil, errors.New("no penalties given")
+	}
+	m := len(y)
+	if err := checkFinite(y); err != nil {
+		return nil, err
+	}
+
+	P := mat.NewDense(m, m, nil)
+	for _, p := range penalties {
+		if p.Order < 1 || p.Order >= m {
+			return nil, fmt.Errorf("penalty order %d not in [1, %d)", p.Order, m)
+		}
+		if err := checkLambda(p.Lambda); err != nil {
+			return nil, fmt.Errorf("penalty of order %d: %w", p.Order, err)
+		}
+		v := make([]float64, m-p.Order)
+		for i := range v {
+			v[i] = p.Lambda
+		}
+		addDifferencePenalty(P, p.Order, v)
+	}
+
+	return solvePenalized(y, nil, P, nil)
+}
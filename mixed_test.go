@@ -0,0 +1,42 @@
+package smoother
+
+import (
+	"math"
+	"testing"
+
+	"github.com/grutz/go-whittaker-eilers/datasets"
+)
+
+func TestWESmootherMixed(t *testing.T) {
+	data, err := datasets.Load("wood")
+	if err != nil {
+		t.Fatalf("Failed to load dataset: %v", err)
+	}
+
+	// a single term matches the plain smoother
+	single, err := WESmootherMixed(data, Penalty{Lambda: 50, Order: 2})
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherMixed: %v", err)
+	}
+	plain, err := WESmoother(data, 50, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	for i := range plain {
+		if math.Abs(single[i]-plain[i]) > 1e-6 {
+			t.Fatalf("index %d: got %f, want %f", i, single[i], plain[i])
+		}
+	}
+
+	mixed, err := WESmootherMixed(data, Penalty{Lambda: 50, Order: 2}, Penalty{Lambda: 5, Order: 1})
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherMixed: %v", err)
+	}
+	if mixed[0] == plain[0] {
+		t.Errorf("first order term had no effect")
+	}
+
+	if _, err := WESmootherMixed(data); err == nil {
+		t.Errorf("expected an error without penalties")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package smoother
+
+import "errors"
+
+// SmoothFile smooths the float64 values stored raw in the file named in into the file named out through memory
+// mapped files, which this platform does not support, so it always returns an error.
+func SmoothFile(in, out string, lambda float64, d int, chunk, overlap int) (err error) {
+	return errors.New("SmoothFile needs memory mapped files, which are not supported on this platform")
+}
@@ -0,0 +1,71 @@
+//go:build unix
+
+package smoother
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// SmoothFile smooths the float64 values stored raw in the byte order of the host in the file named in, such as a
+// capture written by a C program or numpy's tofile, and writes the smooth in the same layout to the file named
+// out, which is created or truncated. The values are smoothed like WESmootherChunked with the given chunk size and
+// overlap. Both files are memory mapped, so a capture larger than memory is processed with working memory of a
+// few chunks while the operating system pages the values in and out.
+func SmoothFile(in, out string, lambda float64, d int, chunk, overlap int) (err error) {
+	src, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+	if size%8 != 0 {
+		return fmt.Errorf("%s holds %d bytes, which is not a whole number of float64 values", in, size)
+	}
+	if err := checkLength(int(size/8), d); err != nil {
+		return err
+	}
+	if outInfo, err := os.Stat(out); err == nil && os.SameFile(info, outInfo) {
+		return errors.New("the output file must not be the input file")
+	}
+
+	dst, err := os.OpenFile(out, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := dst.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	if err := dst.Truncate(size); err != nil {
+		return err
+	}
+
+	yb, err := syscall.Mmap(int(src.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return fmt.Errorf("mapping %s: %w", in, err)
+	}
+	defer syscall.Munmap(yb)
+	zb, err := syscall.Mmap(int(dst.Fd()), 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return fmt.Errorf("mapping %s: %w", out, err)
+	}
+	defer func() {
+		if uerr := syscall.Munmap(zb); err == nil {
+			err = uerr
+		}
+	}()
+
+	n := int(size / 8)
+	y := unsafe.Slice((*float64)(unsafe.Pointer(&yb[0])), n)
+	z := unsafe.Slice((*float64)(unsafe.Pointer(&zb[0])), n)
+	return smoothChunkedTo(z, y, lambda, d, chunk, overlap)
+}
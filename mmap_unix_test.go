@@ -0,0 +1,63 @@
+//go:build unix
+
+package smoother
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSmoothFile(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "capture.f64"), filepath.Join(dir, "smooth.f64")
+	y := make([]float64, 5000)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/200) + 0.3*math.Cos(float64(i)*2.1)
+	}
+	f, err := os.Create(in)
+	if err != nil {
+		t.Fatalf("Failed to create input: %v", err)
+	}
+	if err := binary.Write(f, binary.NativeEndian, y); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Failed to close input: %v", err)
+	}
+
+	if err := SmoothFile(in, out, 100, 2, 700, 0); err != nil {
+		t.Fatalf("Failed to smooth file: %v", err)
+	}
+	want, err := WESmootherChunked(y, 100, 2, 700, 0)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherChunked: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if len(data) != 8*len(y) {
+		t.Fatalf("output holds %d bytes, want %d", len(data), 8*len(y))
+	}
+	for i := range want {
+		if got := math.Float64frombits(binary.NativeEndian.Uint64(data[8*i:])); got != want[i] {
+			t.Fatalf("index %d: got %f, want %f", i, got, want[i])
+		}
+	}
+
+	if err := SmoothFile(in, in, 100, 2, 700, 0); err == nil {
+		t.Errorf("expected an error for smoothing a file onto itself")
+	}
+	if err := os.WriteFile(in, make([]byte, 12), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	if err := SmoothFile(in, out, 100, 2, 700, 0); err == nil {
+		t.Errorf("expected an error for a partial value")
+	}
+	if err := SmoothFile(filepath.Join(dir, "missing"), out, 100, 2, 700, 0); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
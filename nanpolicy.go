@@ -0,0 +1,88 @@
+package smoother
+
+import (
+	"math"
+)
+
+// NaNPolicy selects how a Smoother treats NaN and infinite values, see WithNaNPolicy.
+type NaNPolicy int
+
+const (
+	// NaNError rejects a series with NaN or infinite values with an *InputError.
+	NaNError NaNPolicy = iota
+	// NaNInterpolate drops the NaN and infinite values, smooths the remaining values as one shorter series and
+	// fills the dropped positions by linear interpolation of the smooth, holding it constant beyond the ends.
+	NaNInterpolate
+	// NaNZeroWeight gives the NaN and infinite values zero weight, so the penalty alone bridges them with a
+	// polynomial of degree 2d-1, as for missing values in WithWeights.
+	NaNZeroWeight
+)
+
+// nonFiniteTo writes the smooth of y, which contains NaN or infinite values, into dst following the NaN policy
+// of the Smoother. err is the error of checkFinite for y, returned if fewer values than the order needs are
+// finite.
+func (s *Smoother) nonFiniteTo(dst, y []float64, err error) error {
+	n := len(y)
+	kept := make([]int, 0, n)
+	for i, v := range y {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			kept = append(kept, i)
+		}
+	}
+	if len(kept) <= s.d {
+		return err
+	}
+
+	if s.nanPolicy == NaNZeroWeight {
+		wbuf, fybuf := getFloats(n), getFloats(n)
+		defer putFloats(wbuf)
+		defer putFloats(fybuf)
+		w, fy := *wbuf, *fybuf
+		for _, i := range kept {
+			w[i], fy[i] = 1, y[i]
+			if s.weights != nil {
+				w[i] = s.weights[i]
+			}
+		}
+		return s.weightedTo(dst, fy, w)
+	}
+
+	kybuf, kzbuf := getFloats(len(kept)), getFloats(len(kept))
+	defer putFloats(kybuf)
+	defer putFloats(kzbuf)
+	ky, kz := *kybuf, *kzbuf
+	var kw []float64
+	if s.weights != nil {
+		kwbuf := getFloats(len(kept))
+		defer putFloats(kwbuf)
+		kw = *kwbuf
+	}
+	for k, i := range kept {
+		ky[k] = y[i]
+		if kw != nil {
+			kw[k] = s.weights[i]
+		}
+	}
+	if err := s.weightedTo(kz, ky, kw); err != nil {
+		return err
+	}
+
+	// Linear interpolation between the kept neighbours, constant beyond the first and last kept value
+	for i := 0; i < kept[0]; i++ {
+		dst[i] = kz[0]
+	}
+	for k, i := range kept {
+		dst[i] = kz[k]
+		if k+1 < len(kept) {
+			next := kept[k+1]
+			for j := i + 1; j < next; j++ {
+				t := float64(j-i) / float64(next-i)
+				dst[j] = kz[k] + t*(kz[k+1]-kz[k])
+			}
+		}
+	}
+	for i := kept[len(kept)-1] + 1; i < n; i++ {
+		dst[i] = kz[len(kz)-1]
+	}
+	return nil
+}
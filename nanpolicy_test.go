@@ -0,0 +1,92 @@
+package smoother
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNaNPolicy(t *testing.T) {
+	n := 120
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/12) + 0.1*math.Cos(float64(i)*2.3)
+	}
+	bad := map[int]bool{0: true, 40: true, 41: true, 42: true, 119: true}
+	var ky []float64
+	w := make([]float64, n)
+	fy := make([]float64, n)
+	for i := range y {
+		if bad[i] {
+			continue
+		}
+		ky = append(ky, y[i])
+		w[i], fy[i] = 1, y[i]
+	}
+	y[0], y[40], y[41], y[42], y[119] = math.NaN(), math.Inf(1), math.NaN(), math.Inf(-1), math.NaN()
+
+	s, err := New(WithLambda(10))
+	if err != nil {
+		t.Fatalf("Failed to create Smoother: %v", err)
+	}
+	var inputErr *InputError
+	if _, err := s.Smooth(y); !errors.As(err, &inputErr) {
+		t.Errorf("default policy: got %v, want an *InputError", err)
+	}
+
+	// zero weights match the dense weighted solve
+	s, err = New(WithLambda(10), WithNaNPolicy(NaNZeroWeight))
+	if err != nil {
+		t.Fatalf("Failed to create Smoother: %v", err)
+	}
+	z, err := s.Smooth(y)
+	if err != nil {
+		t.Fatalf("Failed to smooth with zero weights: %v", err)
+	}
+	want, err := solvePenalized(fy, w, penaltyMatrix(n, 10, 2), nil)
+	if err != nil {
+		t.Fatalf("Failed to solve: %v", err)
+	}
+	for i := range want {
+		if math.Abs(z[i]-want[i]) > 1e-8 {
+			t.Fatalf("zero weight index %d: got %f, want %f", i, z[i], want[i])
+		}
+	}
+
+	// interpolation smooths the finite values as one series and fills the gaps linearly
+	s, err = New(WithLambda(10), WithNaNPolicy(NaNInterpolate))
+	if err != nil {
+		t.Fatalf("Failed to create Smoother: %v", err)
+	}
+	if z, err = s.Smooth(y); err != nil {
+		t.Fatalf("Failed to smooth with interpolation: %v", err)
+	}
+	kz, err := WESmoother(ky, 10, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	k := 0
+	for i := range z {
+		if bad[i] {
+			continue
+		}
+		if math.Abs(z[i]-kz[k]) > 1e-9 {
+			t.Fatalf("interpolated index %d: got %f, want %f", i, z[i], kz[k])
+		}
+		k++
+	}
+	if z[0] != z[1] || z[119] != z[118] {
+		t.Errorf("ends not held constant: %f %f, %f %f", z[0], z[1], z[118], z[119])
+	}
+	if got, want := z[41], (z[39]+z[43])/2; math.Abs(got-want) > 1e-12 {
+		t.Errorf("gap not interpolated linearly: got %f, want %f", got, want)
+	}
+
+	allNaN := []float64{math.NaN(), math.NaN(), math.NaN(), math.NaN()}
+	if _, err := s.Smooth(allNaN); !errors.As(err, &inputErr) {
+		t.Errorf("all NaN: got %v, want an *InputError", err)
+	}
+	if _, err := New(WithNaNPolicy(NaNPolicy(7))); err == nil {
+		t.Errorf("expected an error for an unknown policy")
+	}
+}
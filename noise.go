@@ -0,0 +1,18 @@
+package smoother
+
+// NoiseVariance applies the Whittaker-Eilers smoothing function to y and estimates the variance σ² of the noise
+// in y from the residuals, corrected by the effective degrees of freedom of the smooth:
+//
+//	σ² = Σ (y - z)² / (n - edf)
+//
+// where edf is the trace of the hat matrix. It returns σ² together with edf. This is the variance used by
+// WESmootherBand for its confidence band; the uncorrected mean squared residual underestimates σ², more so the
+// smaller lambda is. The variance is zero when no residual degrees of freedom are left.
+func NoiseVariance(y []float64, lambda float64, d int) (sigma2, edf float64, err error) {
+	z, h, err := smoothWithHat(y, lambda, d)
+	if err != nil {
+		return 0, 0, err
+	}
+	sigma2, edf = residualVariance(y, z, nil, h, nil)
+	return sigma2, edf, nil
+}
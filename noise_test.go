@@ -0,0 +1,48 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestNoiseVariance(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	n := 500
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Cos(float64(i)/25) + rng.NormFloat64()*0.2
+	}
+
+	// A small lambda leaves few residual degrees of freedom, the correction matters most there
+	sigma2, edf, err := NoiseVariance(y, 10, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply NoiseVariance: %v", err)
+	}
+	if math.Abs(sigma2-0.04) > 0.006 {
+		t.Errorf("sigma2: got %f, want about 0.04", sigma2)
+	}
+	want, err := EffectiveDF(10, 2, n)
+	if err != nil {
+		t.Fatalf("Failed to apply EffectiveDF: %v", err)
+	}
+	if math.Abs(edf-want) > 1e-9 {
+		t.Errorf("edf: got %f, want %f", edf, want)
+	}
+
+	z, err := WESmoother(y, 10, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	var rss float64
+	for i := range y {
+		rss += (y[i] - z[i]) * (y[i] - z[i])
+	}
+	if naive := rss / float64(n); naive >= sigma2 {
+		t.Errorf("the corrected variance %f should exceed the mean squared residual %f", sigma2, naive)
+	}
+
+	if _, _, err := NoiseVariance([]float64{1, 2}, 1, 2); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !race
+
+package smoother
+
+// raceEnabled reports that the tests run under the race detector, which makes sync.Pool drop items at random.
+const raceEnabled = false
@@ -0,0 +1,221 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// lambdaTolerance is the width, in decades of lambda, to which OptimizeLambda narrows the bracket of the minimum.
+const lambdaTolerance = 1e-3
+
+// vCurveStep is the spacing, in decades of lambda, of the grid OptimizeLambda evaluates the V-curve on.
+const vCurveStep = 0.1
+
+// invPhi is the inverse of the golden ratio, the fraction by which golden-section search shrinks its bracket.
+var invPhi = (math.Sqrt(5) - 1) / 2
+
+// Criterion is a score of a smooth that automatic lambda selection minimizes.
+type Criterion int
+
+const (
+	// CV is the root mean square leave-one-out prediction error, as returned by CrossValidationError.
+	CV Criterion = iota
+	// GCV is generalized cross-validation, n·RSS / (n - edf)², which replaces the leverage of every point by the
+	// average leverage and is less sensitive to points with a leverage close to one.
+	GCV
+	// VCurve is the V-curve of Frasso and Eilers, the distance between the points (log RSS, log roughness) of
+	// neighbouring lambdas, which is shortest where the L-curve of fit against roughness turns its corner. It needs
+	// no hat diagonal and copes with correlated noise, on which cross-validation tends to undersmooth.
+	VCurve
+)
+
+// score returns the value of the criterion for the smooth z of y with hat diagonal h.
+func (c Criterion) score(y, z, h []float64) (float64, error) {
+	switch c {
+	case CV:
+		return leaveOneOutError(y, z, h), nil
+	case GCV:
+		var rss, edf float64
+		for i := range y {
+			rss += (y[i] - z[i]) * (y[i] - z[i])
+			edf += h[i]
+		}
+		n := float64(len(y))
+		return n * rss / ((n - edf) * (n - edf)), nil
+	}
+	return 0, fmt.Errorf("unknown criterion %d", c)
+}
+
+// LambdaSearch is the result of OptimizeLambda.
+type LambdaSearch struct {
+	// Lambda is the lambda with the lowest score found and Score its score.
+	Lambda float64
+	Score  float64
+	// Smooth is the smooth of the series for Lambda.
+	Smooth []float64
+	// Evaluations is the number of lambdas that were tried.
+	Evaluations int
+	// Curve holds the score of every lambda tried, ordered by lambda, to plot the criterion and check that the
+	// minimum is not on a flat plateau.
+	Curve []LambdaScore
+}
+
+// LambdaScore is the score of the smooth for one lambda.
+type LambdaScore struct {
+	Lambda float64
+	Score  float64
+}
+
+// OptimizeLambda searches the lambda in [lo, hi] that minimizes the criterion for smoothing y with order d. A
+// coarse scan with one lambda per decade first brackets the lowest score, then a golden-section search over
+// log10(lambda) narrows the bracket, which takes a few dozen solves where a grid fine enough for the same
+// precision needs hundreds. Every score computed is returned in the curve, coarsely spaced away from the minimum
+// and densely around it. A minimum at either end of the range suggests widening it.
+//
+// The V-curve compares neighbouring lambdas, so for VCurve the lambdas are instead those of a grid with a step of
+// 0.1 decade, and every score belongs to the geometric mean of two neighbours; the curve then holds one score less
+// than the grid has lambdas, and Evaluations counts the final solve for the chosen lambda as well.
+func OptimizeLambda(y []float64, d int, lo, hi float64, crit Criterion) (*LambdaSearch, error) {
+	if !(lo > 0) || !(hi > lo) || math.IsInf(hi, 1) {
+		return nil, fmt.Errorf("%w: lambda range [%g, %g] must be positive, finite and not empty", ErrInvalidLambda, lo, hi)
+	}
+	if err := checkLength(len(y), d); err != nil {
+		return nil, err
+	}
+	if err := checkFinite(y); err != nil {
+		return nil, err
+	}
+	if crit != CV && crit != GCV && crit != VCurve {
+		return nil, fmt.Errorf("unknown criterion %d", crit)
+	}
+	if crit == VCurve {
+		return vCurveSearch(y, d, lo, hi)
+	}
+
+	P := differencePenaltyBand(len(y), d)
+	best := &LambdaSearch{Score: math.Inf(1)}
+	eval := func(logLambda float64) (float64, error) {
+		lambda := math.Pow(10, logLambda)
+		switch logLambda {
+		case math.Log10(lo):
+			lambda = lo
+		case math.Log10(hi):
+			lambda = hi
+		}
+		z, h, err := bandSmooth(y, P, lambda)
+		if err != nil {
+			return 0, fmt.Errorf("lambda %g: %w", lambda, err)
+		}
+		score, err := crit.score(y, z, h)
+		if err != nil {
+			return 0, err
+		}
+		best.Evaluations++
+		best.Curve = append(best.Curve, LambdaScore{Lambda: lambda, Score: score})
+		if score < best.Score {
+			best.Lambda, best.Score, best.Smooth = lambda, score, z
+		}
+		return score, nil
+	}
+
+	// Coarse scan to bracket the lowest score
+	a, b := math.Log10(lo), math.Log10(hi)
+	steps := max(4, int(math.Ceil(b-a)))
+	scan := make([]float64, steps+1)
+	bestStep := 0
+	for k := range scan {
+		x := a + (b-a)*float64(k)/float64(steps)
+		if k == steps {
+			x = b
+		}
+		var err error
+		if scan[k], err = eval(x); err != nil {
+			return nil, err
+		}
+		if scan[k] < scan[bestStep] {
+			bestStep = k
+		}
+	}
+	a, b = a+(b-a)*float64(max(bestStep-1, 0))/float64(steps), a+(b-a)*float64(min(bestStep+1, steps))/float64(steps)
+
+	// Golden-section search, every step reuses one of the two inner points
+	c, e := b-invPhi*(b-a), a+invPhi*(b-a)
+	fc, err := eval(c)
+	if err != nil {
+		return nil, err
+	}
+	fe, err := eval(e)
+	if err != nil {
+		return nil, err
+	}
+	for b-a > lambdaTolerance {
+		if fc < fe {
+			b, e, fe = e, c, fc
+			c = b - invPhi*(b-a)
+			if fc, err = eval(c); err != nil {
+				return nil, err
+			}
+		} else {
+			a, c, fc = c, e, fe
+			e = a + invPhi*(b-a)
+			if fe, err = eval(e); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Slice(best.Curve, func(i, j int) bool { return best.Curve[i].Lambda < best.Curve[j].Lambda })
+	return best, nil
+}
+
+// vCurveSearch is OptimizeLambda for the V-curve.
+func vCurveSearch(y []float64, d int, lo, hi float64) (*LambdaSearch, error) {
+	P := differencePenaltyBand(len(y), d)
+	smooth := func(lambda float64) ([]float64, error) {
+		chol, err := factorizeScaledBand(P, lambda)
+		if err != nil {
+			return nil, fmt.Errorf("lambda %g: %w", lambda, err)
+		}
+		return chol.solve(y), nil
+	}
+
+	a, b := math.Log10(lo), math.Log10(hi)
+	steps := max(2, int(math.Ceil((b-a)/vCurveStep)))
+	fit, pen := make([]float64, steps+1), make([]float64, steps+1)
+	for k := range fit {
+		lambda := math.Pow(10, a+(b-a)*float64(k)/float64(steps))
+		switch k {
+		case 0:
+			lambda = lo
+		case steps:
+			lambda = hi
+		}
+		z, err := smooth(lambda)
+		if err != nil {
+			return nil, err
+		}
+		var rss float64
+		for i := range y {
+			rss += (y[i] - z[i]) * (y[i] - z[i])
+		}
+		// an exact fit or a polynomial smooth would put the point at minus infinity
+		fit[k] = math.Log10(math.Max(rss, math.SmallestNonzeroFloat64))
+		pen[k] = math.Log10(math.Max(Roughness(z, d), math.SmallestNonzeroFloat64))
+	}
+
+	best := &LambdaSearch{Score: math.Inf(1), Evaluations: steps + 2}
+	for k := 0; k < steps; k++ {
+		lambda := math.Pow(10, a+(b-a)*(float64(k)+0.5)/float64(steps))
+		score := math.Hypot(fit[k+1]-fit[k], pen[k+1]-pen[k])
+		best.Curve = append(best.Curve, LambdaScore{Lambda: lambda, Score: score})
+		if score < best.Score {
+			best.Lambda, best.Score = lambda, score
+		}
+	}
+	var err error
+	if best.Smooth, err = smooth(best.Lambda); err != nil {
+		return nil, err
+	}
+	return best, nil
+}
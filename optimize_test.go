@@ -0,0 +1,123 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestOptimizeLambda(t *testing.T) {
+	rng := rand.New(rand.NewSource(27))
+	n := 400
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/30) + rng.NormFloat64()*0.3
+	}
+
+	// a fine grid over the same range finds the same minimum with far more solves
+	lambdas := make([]float64, 401)
+	for k := range lambdas {
+		lambdas[k] = math.Pow(10, -1+float64(k)/50)
+	}
+	grid, err := SmoothSweep(y, lambdas, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply SmoothSweep: %v", err)
+	}
+	gridBest := grid[0]
+	for _, res := range grid {
+		if res.CVError < gridBest.CVError {
+			gridBest = res
+		}
+	}
+
+	for _, crit := range []Criterion{CV, GCV} {
+		search, err := OptimizeLambda(y, 2, 0.1, 1e7, crit)
+		if err != nil {
+			t.Fatalf("Failed to apply OptimizeLambda: %v", err)
+		}
+		if search.Evaluations > 40 {
+			t.Errorf("criterion %d: %d evaluations, want a few dozen", crit, search.Evaluations)
+		}
+		if math.Abs(math.Log10(search.Lambda/gridBest.Lambda)) > 0.3 {
+			t.Errorf("criterion %d: lambda %g, grid minimum at %g", crit, search.Lambda, gridBest.Lambda)
+		}
+		if crit == CV && search.Score > gridBest.CVError*(1+1e-6) {
+			t.Errorf("CV score %f above the grid minimum %f", search.Score, gridBest.CVError)
+		}
+		if len(search.Curve) != search.Evaluations {
+			t.Errorf("criterion %d: curve has %d points for %d evaluations", crit, len(search.Curve), search.Evaluations)
+		}
+		found := false
+		for k, p := range search.Curve {
+			if k > 0 && p.Lambda <= search.Curve[k-1].Lambda {
+				t.Fatalf("criterion %d: curve not ordered by lambda at %d", crit, k)
+			}
+			if p.Score < search.Score {
+				t.Errorf("criterion %d: curve point %v below the reported minimum %f", crit, p, search.Score)
+			}
+			found = found || p.Lambda == search.Lambda
+		}
+		if !found || search.Curve[0].Lambda != 0.1 || search.Curve[len(search.Curve)-1].Lambda != 1e7 {
+			t.Errorf("criterion %d: curve misses the minimum or the ends of the range", crit)
+		}
+
+		want, err := WESmoother(y, search.Lambda, 2)
+		if err != nil {
+			t.Fatalf("Failed to apply WESmoother: %v", err)
+		}
+		for i := range want {
+			if math.Abs(search.Smooth[i]-want[i]) > 1e-8 {
+				t.Fatalf("criterion %d, index %d: smooth %f, want %f", crit, i, search.Smooth[i], want[i])
+			}
+		}
+	}
+
+	if _, err := OptimizeLambda(y, 2, 10, 1, CV); err == nil {
+		t.Errorf("expected an error for an empty range")
+	}
+	if _, err := OptimizeLambda(y, 2, 1, 10, Criterion(9)); err == nil {
+		t.Errorf("expected an error for an unknown criterion")
+	}
+}
+
+func TestOptimizeLambdaVCurve(t *testing.T) {
+	rng := rand.New(rand.NewSource(27))
+	n := 400
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/30) + rng.NormFloat64()*0.3
+	}
+	cv, err := OptimizeLambda(y, 2, 0.1, 1e7, CV)
+	if err != nil {
+		t.Fatalf("Failed to apply OptimizeLambda: %v", err)
+	}
+	search, err := OptimizeLambda(y, 2, 0.1, 1e7, VCurve)
+	if err != nil {
+		t.Fatalf("Failed to apply OptimizeLambda: %v", err)
+	}
+
+	// 80 steps of 0.1 decade, and the final solve
+	if len(search.Curve) != 80 || search.Evaluations != 82 {
+		t.Errorf("%d scores in %d evaluations, want 80 in 82", len(search.Curve), search.Evaluations)
+	}
+	for k, p := range search.Curve {
+		if k > 0 && p.Lambda <= search.Curve[k-1].Lambda {
+			t.Fatalf("curve not ordered by lambda at %d", k)
+		}
+		if p.Score < search.Score {
+			t.Errorf("curve point %v below the reported minimum %f", p, search.Score)
+		}
+	}
+	if math.Abs(math.Log10(search.Lambda/cv.Lambda)) > 1 {
+		t.Errorf("lambda %g more than a decade from the cross-validated %g", search.Lambda, cv.Lambda)
+	}
+	want, err := WESmoother(y, search.Lambda, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	for i := range want {
+		if math.Abs(search.Smooth[i]-want[i]) > 1e-8 {
+			t.Fatalf("index %d: smooth %f, want %f", i, search.Smooth[i], want[i])
+		}
+	}
+}
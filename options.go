@@ -0,0 +1,171 @@
+package smoother
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// smootherConfig holds the settings of New.
+type smootherConfig struct {
+	lambda  float64
+	order   int
+	weights []float64
+	robust  bool
+	tuning  float64
+	nan     NaNPolicy
+	penalty mat.Symmetric
+	cache   int
+	inPlace bool
+	// lazy skips the factorizations New computes up front, for a Smoother that is given one.
+	lazy bool
+}
+
+// Option configures a Smoother created by New.
+type Option func(*smootherConfig)
+
+// WithLambda sets the smoothing parameter, 1 by default.
+func WithLambda(lambda float64) Option {
+	return func(c *smootherConfig) { c.lambda = lambda }
+}
+
+// WithOrder sets the order of differences of the penalty, 2 by default.
+func WithOrder(d int) Option {
+	return func(c *smootherConfig) { c.order = d }
+}
+
+// WithWeights sets a non-negative weight for every value, a zero weight marking a missing value that the smooth
+// interpolates. The Smoother then only accepts series as long as w, and factorizes the weighted system once in
+// New.
+func WithWeights(w []float64) Option {
+	return func(c *smootherConfig) { c.weights = append([]float64(nil), w...) }
+}
+
+// WithRobust makes the Smoother reweight the values with Tukey's bisquare function like WESmootherRobust, so
+// outliers do not pull the smooth towards them. Values further than tuning robust standard deviations from the
+// smooth get zero weight; a tuning of zero uses 4.685. The reweighting refactorizes the system on every step, so
+// robust smoothing allocates.
+func WithRobust(tuning float64) Option {
+	return func(c *smootherConfig) {
+		c.robust = true
+		c.tuning = tuning
+	}
+}
+
+// WithNaNPolicy sets how NaN and infinite values in a series are treated, NaNError by default. NaNInterpolate and
+// NaNZeroWeight smooth such series as long as more values than the order are finite, but allocate on every call
+// that meets one.
+func WithNaNPolicy(p NaNPolicy) Option {
+	return func(c *smootherConfig) { c.nan = p }
+}
+
+// WithPenalty replaces the difference penalty D'D by the symmetric positive semi-definite matrix P, such as a
+// graph Laplacian or a harmonic penalty, turning the Smoother into a general penalized least squares solver of
+// (W + λP) z = W y. The Smoother then only accepts series of the dimension of P and ignores the order, except
+// that more values than the order must have a weight. The factorization exploits the bandwidth of P: a
+// mat.SymBanded matrix keeps its bandwidth, for any other matrix it is found from the non-zero entries, so a
+// dense matrix costs O(n³). A custom penalty cannot be combined with NaNInterpolate, which needs the penalty of a
+// shorter series.
+func WithPenalty(P mat.Symmetric) Option {
+	return func(c *smootherConfig) { c.penalty = P }
+}
+
+// WithPenaltyCache makes the Smoother keep the penalties D'D of the last entries series lengths instead of
+// rebuilding one for every weighted, robust or NaN-handling smooth, for streams of frames in a few fixed shapes.
+// An unweighted smooth only needs the factorization, which the Smoother caches for the last length anyway. The
+// cache is ignored with a custom penalty and is not part of the encoding of the Smoother.
+func WithPenaltyCache(entries int) Option {
+	return func(c *smootherConfig) { c.cache = entries }
+}
+
+// WithInPlace makes Smooth overwrite its input with the smooth and return it, halving the peak memory of
+// smoothing huge series whose raw values are no longer needed. It is short for calling SmoothTo(y, y).
+func WithInPlace() Option {
+	return func(c *smootherConfig) { c.inPlace = true }
+}
+
+// withoutFactorization makes New leave the factorization to the first series, or to the caller restoring one.
+func withoutFactorization() Option {
+	return func(c *smootherConfig) { c.lazy = true }
+}
+
+// New returns a Smoother configured by opts, by default with a lambda of 1 and an order of 2.
+func New(opts ...Option) (*Smoother, error) {
+	cfg := smootherConfig{lambda: 1, order: 2}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if err := checkLength(cfg.order+1, cfg.order); err != nil {
+		return nil, err
+	}
+	if err := checkLambda(cfg.lambda); err != nil {
+		return nil, err
+	}
+	if cfg.penalty == nil {
+		if err := checkConditioning(cfg.lambda, cfg.order, maxConditionedLambda); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.tuning == 0 {
+		cfg.tuning = robustTuning
+	}
+	if !(cfg.tuning > 0) || math.IsInf(cfg.tuning, 1) {
+		return nil, fmt.Errorf("robust tuning %f must be positive and finite", cfg.tuning)
+	}
+
+	if cfg.nan != NaNError && cfg.nan != NaNInterpolate && cfg.nan != NaNZeroWeight {
+		return nil, fmt.Errorf("unknown NaN policy %d", cfg.nan)
+	}
+
+	if cfg.cache < 0 {
+		return nil, fmt.Errorf("penalty cache size %d must not be negative", cfg.cache)
+	}
+
+	s := &Smoother{
+		lambda:    cfg.lambda,
+		d:         cfg.order,
+		robust:    cfg.robust,
+		tuning:    cfg.tuning,
+		nanPolicy: cfg.nan,
+		inPlace:   cfg.inPlace,
+	}
+	if cfg.cache > 0 {
+		s.cache = newPenaltyCache(cfg.cache)
+	}
+	if cfg.penalty != nil {
+		if cfg.nan == NaNInterpolate {
+			return nil, errors.New("a custom penalty cannot be combined with NaNInterpolate")
+		}
+		P, err := customPenaltyBand(cfg.penalty)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.weights != nil && len(cfg.weights) != P.n {
+			return nil, fmt.Errorf("%w: %d weights for a %d x %d penalty", ErrLengthMismatch, len(cfg.weights), P.n, P.n)
+		}
+		s.penalty = P
+		if cfg.weights == nil && !cfg.lazy {
+			if _, err := s.factorization(P.n); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if cfg.weights != nil {
+		if err := checkLength(len(cfg.weights), cfg.order); err != nil {
+			return nil, err
+		}
+		if err := checkWeights(cfg.weights); err != nil {
+			return nil, err
+		}
+		s.weights = cfg.weights
+		if cfg.lazy {
+			return s, nil
+		}
+		if _, err := s.factorization(len(s.weights)); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
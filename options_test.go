@@ -0,0 +1,113 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	rng := rand.New(rand.NewSource(32))
+	n := 200
+	y := make([]float64, n)
+	w := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/15) + rng.NormFloat64()*0.1
+		w[i] = 0.5 + rng.Float64()
+	}
+	w[50], w[51] = 0, 0
+
+	s, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create Smoother: %v", err)
+	}
+	if s.Lambda() != 1 || s.Order() != 2 {
+		t.Errorf("got lambda %f and order %d, want the defaults 1 and 2", s.Lambda(), s.Order())
+	}
+
+	// weighted smoothing matches the dense weighted solve
+	s, err = New(WithLambda(10), WithOrder(3), WithWeights(w))
+	if err != nil {
+		t.Fatalf("Failed to create weighted Smoother: %v", err)
+	}
+	z, err := s.Smooth(y)
+	if err != nil {
+		t.Fatalf("Failed to smooth: %v", err)
+	}
+	want, err := solvePenalized(y, w, penaltyMatrix(n, 10, 3), nil)
+	if err != nil {
+		t.Fatalf("Failed to solve: %v", err)
+	}
+	for i := range want {
+		if math.Abs(z[i]-want[i]) > 1e-8 {
+			t.Fatalf("weighted index %d: got %f, want %f", i, z[i], want[i])
+		}
+	}
+	if _, err := s.Smooth(y[:100]); err == nil {
+		t.Errorf("expected an error for a series shorter than the weights")
+	}
+
+	// robust smoothing matches WESmootherRobust and ignores a spike
+	y[120] += 20
+	s, err = New(WithLambda(100), WithRobust(0))
+	if err != nil {
+		t.Fatalf("Failed to create robust Smoother: %v", err)
+	}
+	if z, err = s.Smooth(y); err != nil {
+		t.Fatalf("Failed to smooth robustly: %v", err)
+	}
+	want, _, err = WESmootherRobust(y, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherRobust: %v", err)
+	}
+	for i := range want {
+		if math.Abs(z[i]-want[i]) > 1e-6 {
+			t.Fatalf("robust index %d: got %f, want %f", i, z[i], want[i])
+		}
+	}
+	if math.Abs(z[120]-math.Sin(120.0/15)) > 0.2 {
+		t.Errorf("robust smooth pulled to the spike: %f", z[120])
+	}
+
+	bad := append([]float64(nil), w...)
+	bad[3] = -1
+	for name, opts := range map[string][]Option{
+		"negative lambda": {WithLambda(-1)},
+		"negative order":  {WithOrder(-1)},
+		"negative weight": {WithWeights(bad)},
+		"short weights":   {WithWeights([]float64{1, 1})},
+		"zero weights":    {WithWeights(make([]float64, n))},
+		"negative tuning": {WithRobust(-1)},
+	} {
+		if _, err := New(opts...); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestWithInPlace(t *testing.T) {
+	y := make([]float64, 100)
+	for i := range y {
+		y[i] = math.Sin(float64(i) / 9)
+	}
+	want, err := WESmoother(y, 5, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	s, err := New(WithLambda(5), WithInPlace())
+	if err != nil {
+		t.Fatalf("Failed to create Smoother: %v", err)
+	}
+	z, err := s.Smooth(y)
+	if err != nil {
+		t.Fatalf("Failed to smooth: %v", err)
+	}
+	if &z[0] != &y[0] {
+		t.Errorf("expected the smooth to overwrite y")
+	}
+	for i := range want {
+		if math.Abs(y[i]-want[i]) > 1e-9 {
+			t.Fatalf("index %d: got %f, want %f", i, y[i], want[i])
+		}
+	}
+}
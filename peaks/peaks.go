@@ -0,0 +1,148 @@
+// Package peaks finds the peaks of a series after Whittaker-Eilers smoothing, the usual workflow for spectra:
+// smoothing removes the noise that would otherwise produce spurious local maxima, and the prominence and width
+// filters keep only the peaks of interest.
+package peaks
+
+import (
+	"fmt"
+	"math"
+
+	smoother "github.com/grutz/go-whittaker-eilers"
+)
+
+// Default settings used for the zero fields of Config.
+const (
+	DefaultLambda = 10
+	DefaultOrder  = 2
+)
+
+// Config controls Find. Zero fields take the defaults above; zero filters accept every peak.
+type Config struct {
+	// Lambda is the smoothing parameter, larger values suppress more noise but also broaden and lower the peaks.
+	Lambda float64
+	// Order is the order of differences of the smoothing penalty.
+	Order int
+	// MinProminence is the smallest prominence of a peak that is kept.
+	MinProminence float64
+	// MinWidth and MaxWidth bound the width of a peak, in samples, at half its prominence.
+	MinWidth, MaxWidth float64
+}
+
+// withDefaults returns c with its zero fields replaced by the defaults.
+func (c Config) withDefaults() Config {
+	if c.Lambda == 0 {
+		c.Lambda = DefaultLambda
+	}
+	if c.Order == 0 {
+		c.Order = DefaultOrder
+	}
+	return c
+}
+
+// Peak is a local maximum of the smooth.
+type Peak struct {
+	// Index is the position of the maximum, the middle of a flat top.
+	Index int
+	// Height is the value of the smooth at Index.
+	Height float64
+	// Prominence is how far the peak rises above the higher of the lowest points separating it from a higher
+	// peak, or from the end of the series, on either side.
+	Prominence float64
+	// Left and Right are the interpolated positions where the smooth crosses half the prominence below the
+	// peak, and Width is the distance between them.
+	Left, Right, Width float64
+}
+
+// Result holds the smooth computed by Find and the peaks found in it, in order of position.
+type Result struct {
+	Smooth []float64
+	Peaks  []Peak
+}
+
+// Find smooths y with the Whittaker-Eilers smoother and returns the local maxima of the smooth that pass the
+// prominence and width filters of cfg.
+func Find(y []float64, cfg Config) (*Result, error) {
+	cfg = cfg.withDefaults()
+	if cfg.MinProminence < 0 || cfg.MinWidth < 0 || cfg.MaxWidth < 0 {
+		return nil, fmt.Errorf("peak filters must not be negative")
+	}
+	if cfg.MaxWidth > 0 && cfg.MaxWidth < cfg.MinWidth {
+		return nil, fmt.Errorf("maximum width %f below minimum width %f", cfg.MaxWidth, cfg.MinWidth)
+	}
+
+	z, err := smoother.WESmoother(y, cfg.Lambda, cfg.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []Peak
+	for _, i := range localMaxima(z) {
+		p := measure(z, i)
+		if p.Prominence < cfg.MinProminence || p.Width < cfg.MinWidth {
+			continue
+		}
+		if cfg.MaxWidth > 0 && p.Width > cfg.MaxWidth {
+			continue
+		}
+		found = append(found, p)
+	}
+	return &Result{Smooth: z, Peaks: found}, nil
+}
+
+// localMaxima returns the indices of the values of z that are higher than both neighbours. A flat top counts as
+// a single maximum at its middle; the ends of the series are never maxima.
+func localMaxima(z []float64) []int {
+	var maxima []int
+	for i := 1; i < len(z)-1; i++ {
+		if z[i] <= z[i-1] {
+			continue
+		}
+		// skip over a flat top and check that it falls off afterwards
+		j := i
+		for j+1 < len(z)-1 && z[j+1] == z[i] {
+			j++
+		}
+		if z[j+1] < z[i] {
+			maxima = append(maxima, (i+j)/2)
+		}
+		i = j
+	}
+	return maxima
+}
+
+// measure returns the prominence and width of the peak of z at i.
+func measure(z []float64, i int) Peak {
+	p := Peak{Index: i, Height: z[i]}
+
+	// Lowest point on either side before the series rises above the peak
+	leftBase, rightBase := z[i], z[i]
+	left, right := i, i
+	for left > 0 && z[left-1] <= z[i] {
+		left--
+		leftBase = math.Min(leftBase, z[left])
+	}
+	for right < len(z)-1 && z[right+1] <= z[i] {
+		right++
+		rightBase = math.Min(rightBase, z[right])
+	}
+	p.Prominence = z[i] - math.Max(leftBase, rightBase)
+
+	// Interpolated crossings of half the prominence, bounded by the extent searched above
+	ref := z[i] - p.Prominence/2
+	p.Left = float64(left)
+	for j := i; j > left; j-- {
+		if z[j-1] < ref {
+			p.Left = float64(j-1) + (ref-z[j-1])/(z[j]-z[j-1])
+			break
+		}
+	}
+	p.Right = float64(right)
+	for j := i; j < right; j++ {
+		if z[j+1] < ref {
+			p.Right = float64(j) + (z[j]-ref)/(z[j]-z[j+1])
+			break
+		}
+	}
+	p.Width = p.Right - p.Left
+	return p
+}
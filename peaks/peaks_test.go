@@ -0,0 +1,71 @@
+package peaks
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	n := 600
+	y := make([]float64, n)
+	gauss := func(x, center, sigma float64) float64 {
+		return math.Exp(-(x - center) * (x - center) / (2 * sigma * sigma))
+	}
+	for i := range y {
+		x := float64(i)
+		// a broad and a narrow tall peak, plus a small one
+		y[i] = 2*gauss(x, 150, 20) + 3*gauss(x, 350, 5) + 0.3*gauss(x, 500, 8) + rng.NormFloat64()*0.05
+	}
+
+	res, err := Find(y, Config{MinProminence: 0.5})
+	if err != nil {
+		t.Fatalf("Failed to apply Find: %v", err)
+	}
+	if len(res.Peaks) != 2 {
+		t.Fatalf("got %d peaks, want 2: %v", len(res.Peaks), res.Peaks)
+	}
+	// The width at half height of a gaussian is 2.355 sigma
+	for k, want := range []struct{ index, width float64 }{{150, 47}, {350, 11.8}} {
+		p := res.Peaks[k]
+		if math.Abs(float64(p.Index)-want.index) > 3 {
+			t.Errorf("peak %d at %d, want about %.0f", k, p.Index, want.index)
+		}
+		if math.Abs(p.Width-want.width) > 0.15*want.width {
+			t.Errorf("peak %d: width %f, want about %f", k, p.Width, want.width)
+		}
+		if p.Left >= float64(p.Index) || p.Right <= float64(p.Index) {
+			t.Errorf("peak %d: crossings %f and %f do not bracket the peak", k, p.Left, p.Right)
+		}
+	}
+
+	if res, err = Find(y, Config{MinProminence: 0.5, MaxWidth: 20}); err != nil {
+		t.Fatalf("Failed to apply Find: %v", err)
+	}
+	if len(res.Peaks) != 1 || math.Abs(float64(res.Peaks[0].Index)-350) > 3 {
+		t.Errorf("expected only the narrow peak, got %v", res.Peaks)
+	}
+
+	if res, err = Find(y, Config{MinProminence: 0.2, MinWidth: 15}); err != nil {
+		t.Fatalf("Failed to apply Find: %v", err)
+	}
+	if len(res.Peaks) != 2 || res.Peaks[1].Index < 490 {
+		t.Errorf("expected the broad and the small peak, got %v", res.Peaks)
+	}
+
+	if _, err := Find(y, Config{MinWidth: 10, MaxWidth: 5}); err == nil {
+		t.Errorf("expected an error for a maximum width below the minimum")
+	}
+	if _, err := Find(y, Config{MinProminence: -1}); err == nil {
+		t.Errorf("expected an error for a negative prominence")
+	}
+}
+
+func TestLocalMaxima(t *testing.T) {
+	z := []float64{0, 1, 0, 2, 2, 2, 1, 3, 3, 4}
+	got := localMaxima(z)
+	if len(got) != 2 || got[0] != 1 || got[1] != 4 {
+		t.Errorf("got maxima %v, want [1 4]", got)
+	}
+}
@@ -0,0 +1,42 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// customPenaltyBand copies the symmetric penalty P into a band matrix. The bandwidth is that of a mat.SymBanded
+// P, or the largest distance from the diagonal of a non-zero entry otherwise.
+func customPenaltyBand(P mat.Symmetric) (*symBand, error) {
+	n := P.SymmetricDim()
+	if n == 0 {
+		return nil, fmt.Errorf("%w: the penalty is empty", ErrSeriesTooShort)
+	}
+	bw := 0
+	if b, ok := P.(mat.SymBanded); ok {
+		_, bw = b.SymBand()
+	} else {
+		for i := 0; i < n; i++ {
+			for j := n - 1; j > i+bw; j-- {
+				if P.At(i, j) != 0 {
+					bw = j - i
+					break
+				}
+			}
+		}
+	}
+
+	B := newSymBand(n, bw)
+	for i := 0; i < n; i++ {
+		for j := i; j <= min(n-1, i+bw); j++ {
+			v := P.At(i, j)
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("penalty entry (%d, %d) is %f", i, j, v)
+			}
+			B.add(i, j, v)
+		}
+	}
+	return B, nil
+}
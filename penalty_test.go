@@ -0,0 +1,92 @@
+package smoother
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestWithPenalty(t *testing.T) {
+	n := 60
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(2*math.Pi*float64(i)/float64(n)) + 0.2*math.Cos(float64(i)*2.9)
+	}
+	want, err := WESmoother(y, 5, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+
+	// D'D as a band matrix and as a dense matrix reproduce the usual smoother
+	band, err := PenaltyMatrix(n, 1, 2)
+	if err != nil {
+		t.Fatalf("Failed to create penalty matrix: %v", err)
+	}
+	dense := mat.NewSymDense(n, nil)
+	dense.CopySym(band)
+	for name, P := range map[string]mat.Symmetric{"band": band, "dense": dense} {
+		s, err := New(WithLambda(5), WithPenalty(P))
+		if err != nil {
+			t.Fatalf("%s: Failed to create Smoother: %v", name, err)
+		}
+		z, err := s.Smooth(y)
+		if err != nil {
+			t.Fatalf("%s: Failed to smooth: %v", name, err)
+		}
+		for i := range want {
+			if math.Abs(z[i]-want[i]) > 1e-9 {
+				t.Fatalf("%s, index %d: got %f, want %f", name, i, z[i], want[i])
+			}
+		}
+	}
+
+	// the Laplacian of a ring penalizes the jump between the last and the first value as well
+	L := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		L.SetSym(i, i, L.At(i, i)+1)
+		L.SetSym(j, j, L.At(j, j)+1)
+		L.SetSym(i, j, L.At(i, j)-1)
+	}
+	s, err := New(WithLambda(5), WithPenalty(L))
+	if err != nil {
+		t.Fatalf("Failed to create Smoother: %v", err)
+	}
+	z, err := s.Smooth(y)
+	if err != nil {
+		t.Fatalf("Failed to smooth on the ring: %v", err)
+	}
+	A := mat.NewSymDense(n, nil)
+	A.ScaleSym(5, L)
+	for i := 0; i < n; i++ {
+		A.SetSym(i, i, A.At(i, i)+1)
+	}
+	var chol mat.Cholesky
+	if !chol.Factorize(A) {
+		t.Fatalf("Failed to factorize the ring system")
+	}
+	var ref mat.VecDense
+	if err := chol.SolveVecTo(&ref, mat.NewVecDense(n, y)); err != nil {
+		t.Fatalf("Failed to solve the ring system: %v", err)
+	}
+	for i := range z {
+		if math.Abs(z[i]-ref.AtVec(i)) > 1e-9 {
+			t.Fatalf("ring index %d: got %f, want %f", i, z[i], ref.AtVec(i))
+		}
+	}
+
+	if _, err := s.Smooth(y[:30]); err == nil {
+		t.Errorf("expected an error for a series of another length")
+	}
+	if _, err := New(WithPenalty(L), WithNaNPolicy(NaNInterpolate)); err == nil {
+		t.Errorf("expected an error for a custom penalty with interpolation")
+	}
+	if _, err := New(WithPenalty(L), WithWeights(make([]float64, 10))); err == nil {
+		t.Errorf("expected an error for weights of another length")
+	}
+	L.SetSym(3, 4, math.NaN())
+	if _, err := New(WithPenalty(L)); err == nil {
+		t.Errorf("expected an error for a NaN penalty entry")
+	}
+}
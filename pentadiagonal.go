@@ -0,0 +1,80 @@
+package smoother
+
+// secondDifferencePenalty returns the entries D'D(i, i), D'D(i, i+1) and D'D(i, i+2) of the second order penalty
+// for a series of length n >= 3 in closed form. Every row r of D holds 1, -2, 1 in columns r through r+2, so
+// D'D(i, j) sums the products of these coefficients over the rows covering both columns, which away from the
+// ends gives 6, -4 and 1.
+func secondDifferencePenalty(n, i int) (diag, off1, off2 float64) {
+	if i >= 2 && i <= n-3 {
+		return 6, -4, 1
+	}
+	coeffs := [3]float64{1, -2, 1}
+	for r := max(0, i-2); r <= min(i, n-3); r++ {
+		k := i - r
+		diag += coeffs[k] * coeffs[k]
+		if k < 2 {
+			off1 += coeffs[k] * coeffs[k+1]
+		}
+		if k == 0 {
+			off2++
+		}
+	}
+	return diag, off1, off2
+}
+
+// smoothPentadiagonalTo sets z to the solution of (I + λD'D) z = y for second order differences. I + λD'D is
+// pentadiagonal, so it is factorized as L·D·L' with a unit lower triangular L with two subdiagonals l1 and l2 and
+// a diagonal D, straight from the closed form coefficients without building a matrix. It takes O(n) time and,
+// besides z, only pooled workspace. z may be y, since every step only reads y at the index it writes.
+func smoothPentadiagonalTo(z, y []float64, lambda float64) error {
+	n := len(y)
+	buf := getFloats(3 * n)
+	defer putFloats(buf)
+	diag, l1, l2 := (*buf)[:n], (*buf)[n:2*n], (*buf)[2*n:]
+
+	// Factorize, A(i, i) = d_i + l1_{i-1}² d_{i-1} + l2_{i-2}² d_{i-2} and so on for the off diagonals
+	for i := 0; i < n; i++ {
+		a, b, c := secondDifferencePenalty(n, i)
+		di := 1 + lambda*a
+		if i > 0 {
+			di -= l1[i-1] * l1[i-1] * diag[i-1]
+		}
+		if i > 1 {
+			di -= l2[i-2] * l2[i-2] * diag[i-2]
+		}
+		if !(di > 0) {
+			return ErrNotPositiveDefinite
+		}
+		diag[i] = di
+		b *= lambda
+		if i > 0 {
+			b -= l1[i-1] * l2[i-1] * diag[i-1]
+		}
+		l1[i] = b / di
+		l2[i] = lambda * c / di
+	}
+
+	// Solve L·w = y, D·v = w and L'·z = v
+	for i := 0; i < n; i++ {
+		w := y[i]
+		if i > 0 {
+			w -= l1[i-1] * z[i-1]
+		}
+		if i > 1 {
+			w -= l2[i-2] * z[i-2]
+		}
+		z[i] = w
+	}
+	for i := range z {
+		z[i] /= diag[i]
+	}
+	for i := n - 1; i >= 0; i-- {
+		if i+1 < n {
+			z[i] -= l1[i] * z[i+1]
+		}
+		if i+2 < n {
+			z[i] -= l2[i] * z[i+2]
+		}
+	}
+	return nil
+}
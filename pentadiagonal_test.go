@@ -0,0 +1,47 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSmoothPentadiagonal(t *testing.T) {
+	rng := rand.New(rand.NewSource(33))
+	for _, n := range []int{3, 4, 5, 6, 250} {
+		y := make([]float64, n)
+		for i := range y {
+			y[i] = math.Sin(float64(i)/9) + rng.NormFloat64()*0.3
+		}
+		for _, lambda := range []float64{0.1, 10, 1e6} {
+			z := make([]float64, n)
+			if err := smoothPentadiagonalTo(z, y, lambda); err != nil {
+				t.Fatalf("n %d, lambda %g: Failed to smooth: %v", n, lambda, err)
+			}
+			want, err := solvePenalized(y, nil, penaltyMatrix(n, lambda, 2), nil)
+			if err != nil {
+				t.Fatalf("n %d, lambda %g: Failed to solve: %v", n, lambda, err)
+			}
+			for i := range want {
+				if math.Abs(z[i]-want[i]) > 1e-8 {
+					t.Fatalf("n %d, lambda %g, index %d: got %f, want %f", n, lambda, i, z[i], want[i])
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkSmoothPentadiagonal(b *testing.B) {
+	y := make([]float64, 1024)
+	for i := range y {
+		y[i] = math.Sin(float64(i) / 25)
+	}
+	z := make([]float64, len(y))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := smoothPentadiagonalTo(z, y, 100); err != nil {
+			b.Fatalf("Failed to smooth: %v", err)
+		}
+	}
+}
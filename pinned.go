@@ -0,0 +1,28 @@
+package smoother
+
+import (
+	"fmt"
+)
+
+// WESmootherPinned applies the Whittaker-Eilers smoothing function to y like WESmoother, but holds the values at
+// the indices in pins fixed so they are preserved exactly in the smoothed series. This is useful for known
+// calibration or anchor points that must not be moved by the smoothing.
+//
+// The pinned values are treated as equality constraints: they are removed from the linear system and their
+// contribution to the penalty is moved to the right hand side, so the rest of the series is smoothed as if
+// the anchors were exact.
+func WESmootherPinned(y []float64, lambda float64, d int, pins []int) ([]float64, error) {
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, err
+	}
+
+	pinned := make([]bool, len(y))
+	for _, p := range pins {
+		if p < 0 || p >= len(y) {
+			return nil, fmt.Errorf("pinned index %d out of range [0, %d)", p, len(y))
+		}
+		pinned[p] = true
+	}
+
+	return solvePenalized(y, nil, penaltyMatrix(len(y), lambda, d), pinned)
+}
@@ -0,0 +1,41 @@
+package smoother
+
+import (
+	"testing"
+
+	"github.com/grutz/go-whittaker-eilers/datasets"
+)
+
+func TestWESmootherPinned(t *testing.T) {
+	data, err := datasets.Load("wood")
+	if err != nil {
+		t.Fatalf("Failed to load dataset: %v", err)
+	}
+
+	pins := []int{0, 50, 100, len(data) - 1}
+	clean, err := WESmootherPinned(data, 100, 2, pins)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherPinned: %v", err)
+	}
+
+	for _, p := range pins {
+		if clean[p] != data[p] {
+			t.Errorf("pinned index %d: got %f, want %f", p, clean[p], data[p])
+		}
+	}
+
+	free, err := WESmoother(data, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	if clean[51] == free[51] {
+		t.Errorf("pinning had no effect on neighbouring values")
+	}
+
+	if _, err := WESmootherPinned(data, 100, 2, []int{len(data)}); err == nil {
+		t.Errorf("expected an error for an out of range pin")
+	}
+	if _, err := WESmootherPinned([]float64{1, 2}, 10, 2, nil); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
@@ -0,0 +1,113 @@
+package smoother
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// maxIRLSIterations caps the number of reweighting steps of the penalized likelihood smoothers.
+const maxIRLSIterations = 100
+
+// irlsTolerance is the largest change of the linear predictor at which the penalized likelihood smoothers stop.
+const irlsTolerance = 1e-9
+
+// minIRLSWeight keeps the working weights of the penalized likelihood smoothers positive where the fitted mean
+// underflows, so the system stays positive definite.
+const minIRLSWeight = 1e-12
+
+// family describes a generalized linear model for penalized iteratively reweighted least squares: the starting
+// linear predictor for an observation, and the working response and weight at a linear predictor.
+type family struct {
+	start   func(i int, y float64) float64
+	working func(i int, y, eta float64) (z, w float64)
+}
+
+// penalizedIRLS fits the linear predictor eta of a generalized linear model for y under the difference penalty
+// lambda * D'D of order d, following the P-IRLS scheme of Eilers and Marx: the penalized weighted least squares
+// problem for the working response and weights is solved repeatedly until eta settles. It returns the error of ctx
+// if it is done before a step and reports every step to progress.
+func penalizedIRLS(ctx context.Context, y []float64, lambda float64, d int, fam family, progress *progressReporter) ([]float64, error) {
+	P := penaltyMatrix(len(y), lambda, d)
+	eta := make([]float64, len(y))
+	for i, v := range y {
+		eta[i] = fam.start(i, v)
+	}
+
+	z := make([]float64, len(y))
+	w := make([]float64, len(y))
+	for iter := 0; iter < maxIRLSIterations; iter++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for i, v := range y {
+			z[i], w[i] = fam.working(i, v, eta[i])
+			w[i] = math.Max(w[i], minIRLSWeight)
+		}
+		next, err := solvePenalized(z, w, P, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var change float64
+		for i := range eta {
+			change = math.Max(change, math.Abs(next[i]-eta[i]))
+		}
+		eta = next
+		if err := progress.report(change); err != nil {
+			return nil, err
+		}
+		if change < irlsTolerance*math.Max(1, maxAbs(eta)) {
+			return eta, nil
+		}
+	}
+	return nil, fmt.Errorf("penalized likelihood fit did not converge in %d iterations", maxIRLSIterations)
+}
+
+// maxAbs returns the largest absolute value of v.
+func maxAbs(v []float64) float64 {
+	var m float64
+	for _, x := range v {
+		m = math.Max(m, math.Abs(x))
+	}
+	return m
+}
+
+// WESmootherPoisson smooths the counts y, such as photon counts or events per bin, by penalized Poisson
+// regression instead of least squares: the logarithm of the expected count is penalized with the usual difference
+// penalty and fitted by penalized iteratively reweighted least squares. The result is the smooth expected count,
+// positive everywhere, and bins with few counts are weighed according to their Poisson variance.
+//
+// The counts must be finite and not negative but need not be integers. Since the penalty acts on the logarithm,
+// a suitable lambda differs from the one for WESmoother.
+func WESmootherPoisson(y []float64, lambda float64, d int) ([]float64, error) {
+	return WESmootherPoissonCtx(context.Background(), y, lambda, d)
+}
+
+// WESmootherPoissonCtx is WESmootherPoisson, giving up with the error of ctx once ctx is done. ctx is checked
+// before every reweighting step, and every step is reported to the ProgressFunc set by WithProgress.
+func WESmootherPoissonCtx(ctx context.Context, y []float64, lambda float64, d int, opts ...FitOption) ([]float64, error) {
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, err
+	}
+	for i, v := range y {
+		if v < 0 {
+			return nil, fmt.Errorf("count %f at index %d is negative", v, i)
+		}
+	}
+
+	eta, err := penalizedIRLS(ctx, y, lambda, d, family{
+		start: func(_ int, y float64) float64 { return math.Log(y + 1) },
+		working: func(_ int, y, eta float64) (float64, float64) {
+			mu := math.Exp(eta)
+			return eta + (y-mu)/mu, mu
+		},
+	}, newProgressReporter(opts))
+	if err != nil {
+		return nil, err
+	}
+	for i := range eta {
+		eta[i] = math.Exp(eta[i])
+	}
+	return eta, nil
+}
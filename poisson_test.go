@@ -0,0 +1,64 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// poissonSample draws a Poisson distributed count with the given mean.
+func poissonSample(rng *rand.Rand, mean float64) float64 {
+	limit, k, p := math.Exp(-mean), 0.0, 1.0
+	for {
+		p *= rng.Float64()
+		if p <= limit {
+			return k
+		}
+		k++
+	}
+}
+
+func TestWESmootherPoisson(t *testing.T) {
+	rng := rand.New(rand.NewSource(16))
+	n := 400
+	rate := make([]float64, n)
+	y := make([]float64, n)
+	for i := range y {
+		// a faint background with a bright line, many bins count zero
+		rate[i] = 0.3 + 20*math.Exp(-math.Pow(float64(i-200)/15, 2))
+		y[i] = poissonSample(rng, rate[i])
+	}
+
+	mu, err := WESmootherPoisson(y, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherPoisson: %v", err)
+	}
+	var total, fitted float64
+	for i := range y {
+		if mu[i] <= 0 {
+			t.Fatalf("index %d: expected count %f not positive", i, mu[i])
+		}
+		total += y[i]
+		fitted += mu[i]
+	}
+	if math.Abs(mu[200]-rate[200]) > 0.1*rate[200] {
+		t.Errorf("line peak: got %f, want about %f", mu[200], rate[200])
+	}
+	var background float64
+	for i := 0; i < 100; i++ {
+		background += mu[i] / 100
+	}
+	if math.Abs(background-0.3) > 0.1 {
+		t.Errorf("background: got %f, want about 0.3", background)
+	}
+
+	// the score equations of the log link preserve the total count
+	if math.Abs(fitted-total) > 1e-6*total {
+		t.Errorf("fitted total %f, want the observed %f", fitted, total)
+	}
+
+	y[5] = -1
+	if _, err := WESmootherPoisson(y, 100, 2); err == nil {
+		t.Errorf("expected an error for a negative count")
+	}
+}
@@ -0,0 +1,69 @@
+package smoother
+
+import (
+	"context"
+	"sync"
+)
+
+// floatPool holds *[]float64 workspaces, the band matrices, factors and scratch vectors of the band solver, so
+// services smoothing thousands of series a second reuse them instead of handing them to the garbage collector.
+var floatPool sync.Pool
+
+// getFloats returns a buffer holding a zeroed slice of n values, reusing a pooled one if it is large enough.
+// Handing the same pointer back to putFloats avoids allocating even the slice header.
+func getFloats(n int) *[]float64 {
+	if p, _ := floatPool.Get().(*[]float64); p != nil && cap(*p) >= n {
+		*p = (*p)[:n]
+		clear(*p)
+		return p
+	}
+	b := make([]float64, n)
+	return &b
+}
+
+// putFloats returns the buffer *p to the pool, after which it must no longer be used.
+func putFloats(p *[]float64) {
+	floatPool.Put(p)
+}
+
+// pooledPenaltyBand is differencePenaltyBand with the band taken from the pool, see putBand.
+func pooledPenaltyBand(n, d int) *symBand {
+	P := &symBand{n: n, bw: d, data: *getFloats(n * (d + 1))}
+	addDifferencePenaltyBand(P)
+	return P
+}
+
+// putBand returns the data of the band matrix b to the pool.
+func putBand(b *symBand) {
+	putFloats(&b.data)
+}
+
+// pooledFactor returns the Cholesky factorization of W + lambda * P for the band penalty P, where W is the
+// diagonal matrix of the weights w or the identity when w is nil. The system and the factor are taken from the
+// pool, the factor is returned to it by putFactor.
+func pooledFactor(ctx context.Context, P *symBand, lambda float64, w []float64) (*bandCholesky, error) {
+	A := &symBand{n: P.n, bw: P.bw, data: *getFloats(len(P.data))}
+	for i, v := range P.data {
+		A.data[i] = lambda * v
+	}
+	for i := 0; i < A.n; i++ {
+		wi := 1.0
+		if w != nil {
+			wi = w[i]
+		}
+		A.data[i*(A.bw+1)] += wi
+	}
+	c := &bandCholesky{n: P.n, bw: P.bw, data: *getFloats(len(P.data))}
+	err := factorizeBandInto(ctx, c, A)
+	putBand(A)
+	if err != nil {
+		putFactor(c)
+		return nil, err
+	}
+	return c, nil
+}
+
+// putFactor returns the data of the factor c to the pool.
+func putFactor(c *bandCholesky) {
+	putFloats(&c.data)
+}
@@ -0,0 +1,54 @@
+package smoother
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestGetFloats(t *testing.T) {
+	buf := getFloats(100)
+	for i := range *buf {
+		(*buf)[i] = 1
+	}
+	putFloats(buf)
+	for k := 0; k < 3; k++ {
+		buf := getFloats(50 + 25*k)
+		if len(*buf) != 50+25*k {
+			t.Fatalf("got %d values, want %d", len(*buf), 50+25*k)
+		}
+		for i, v := range *buf {
+			if v != 0 {
+				t.Fatalf("value %d of a reused buffer is %f, want 0", i, v)
+			}
+		}
+		putFloats(buf)
+	}
+}
+
+func TestPooledWorkspaces(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random under the race detector")
+	}
+	n := 20000
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = float64(i % 7)
+	}
+	// With pooled band matrices only the returned smooth is allocated, instead of three bands of 3n values
+	smooth := func() {
+		if _, err := WESmootherCtx(context.Background(), y, 100, 2); err != nil {
+			t.Fatalf("Failed to smooth: %v", err)
+		}
+	}
+	smooth()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < 20; i++ {
+		smooth()
+	}
+	runtime.ReadMemStats(&after)
+	if got, limit := (after.TotalAlloc-before.TotalAlloc)/20, uint64(2*8*n); got > limit {
+		t.Errorf("allocated %d bytes per smooth, want at most %d", got, limit)
+	}
+}
@@ -0,0 +1,62 @@
+package smoother
+
+import (
+	"time"
+)
+
+// Progress describes the state of an iterative fit after one of its iterations.
+type Progress struct {
+	// Iteration counts the completed iterations, starting at 1.
+	Iteration int
+	// Change is the largest change of the quantity the fit iterates on: the robust weights of a robust fit, the
+	// linear predictor of a penalized likelihood fit, the smoothed positions of a constrained trajectory, the local
+	// variances of a heteroscedastic fit relative to their previous values and the fitted values of a calibration
+	// curve.
+	Change float64
+	// Elapsed is the time since the fit started.
+	Elapsed time.Duration
+}
+
+// ProgressFunc receives the progress of an iterative fit after every iteration. A non-nil error aborts the fit,
+// which then returns this error, so a caller can give up on a fit that converges too slowly.
+type ProgressFunc func(Progress) error
+
+// fitConfig holds the settings of an iterative fit.
+type fitConfig struct {
+	progress ProgressFunc
+}
+
+// FitOption configures an iterative fit: WESmootherRobustCtx, WESmootherPoissonCtx, SmoothTrajectoryCtx,
+// WESmootherBinomial and WESmootherHeteroscedastic.
+type FitOption func(*fitConfig)
+
+// WithProgress makes an iterative fit call fn after every iteration. FitCalibrationCurve takes
+// WithCalibrationProgress instead.
+func WithProgress(fn ProgressFunc) FitOption {
+	return func(c *fitConfig) { c.progress = fn }
+}
+
+// progressReporter counts the iterations of a fit and reports them to its ProgressFunc, if any.
+type progressReporter struct {
+	fn    ProgressFunc
+	start time.Time
+	iter  int
+}
+
+// newProgressReporter returns a reporter for a fit starting now with the ProgressFunc set by opts.
+func newProgressReporter(opts []FitOption) *progressReporter {
+	var cfg fitConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &progressReporter{fn: cfg.progress, start: time.Now()}
+}
+
+// report records a completed iteration with the given change, returning the error of the ProgressFunc.
+func (r *progressReporter) report(change float64) error {
+	r.iter++
+	if r.fn == nil {
+		return nil
+	}
+	return r.fn(Progress{Iteration: r.iter, Change: change, Elapsed: time.Since(r.start)})
+}
@@ -0,0 +1,99 @@
+package smoother
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWithProgress(t *testing.T) {
+	n := 120
+	y := make([]float64, n)
+	counts := make([]float64, n)
+	x := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i) / 10)
+		counts[i] = math.Round(5 + 4*math.Sin(float64(i)/15))
+		x[i] = float64(i) * 0.5
+	}
+	y[40] = 8
+
+	noisy := make([]float64, n)
+	proportions := make([]float64, n)
+	for i := range noisy {
+		noisy[i] = math.Sin(float64(i)/10) + float64(1+i%5)*float64(i%2*2-1)*float64(i)/float64(4*n)
+		proportions[i] = 0.5 + 0.4*math.Sin(float64(i)/12)
+	}
+	calibrationX := []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	calibrationY := []float64{0, 1, 2, 3, 8, 1, 6, 7, 8, 9}
+
+	fits := map[string]func(ctx context.Context, fn ProgressFunc) error{
+		"robust": func(ctx context.Context, fn ProgressFunc) error {
+			_, _, err := WESmootherRobustCtx(ctx, y, 10, 2, WithProgress(fn))
+			return err
+		},
+		"poisson": func(ctx context.Context, fn ProgressFunc) error {
+			_, err := WESmootherPoissonCtx(ctx, counts, 10, 2, WithProgress(fn))
+			return err
+		},
+		"trajectory": func(ctx context.Context, fn ProgressFunc) error {
+			_, err := SmoothTrajectoryCtx(ctx, [][]float64{x, y}, 1, 2, TrajectoryLimits{MaxAccel: 0.05}, WithProgress(fn))
+			return err
+		},
+		"binomial": func(_ context.Context, fn ProgressFunc) error {
+			_, err := WESmootherBinomial(proportions, nil, 10, 2, WithProgress(fn))
+			return err
+		},
+		"heteroscedastic": func(_ context.Context, fn ProgressFunc) error {
+			_, _, err := WESmootherHeteroscedastic(noisy, 10, 1e3, 2, WithProgress(fn))
+			return err
+		},
+		"calibration": func(_ context.Context, fn ProgressFunc) error {
+			_, err := FitCalibrationCurve(calibrationX, calibrationY, WithCalibrationProgress(fn))
+			return err
+		},
+	}
+	for name, fit := range fits {
+		var reports []Progress
+		record := func(p Progress) error {
+			reports = append(reports, p)
+			return nil
+		}
+		if err := fit(context.Background(), record); err != nil {
+			t.Fatalf("%s: Failed to fit: %v", name, err)
+		}
+		if len(reports) < 2 {
+			t.Fatalf("%s: got %d progress reports, want several", name, len(reports))
+		}
+		for i, p := range reports {
+			if p.Iteration != i+1 || p.Change < 0 || p.Elapsed < 0 || (i > 0 && p.Elapsed < reports[i-1].Elapsed) {
+				t.Errorf("%s: unexpected report %d: %+v", name, i, p)
+			}
+		}
+
+		// the callback aborts the fit with its own error
+		stop := errors.New("stop")
+		calls := 0
+		abort := func(p Progress) error {
+			calls++
+			return stop
+		}
+		if err := fit(context.Background(), abort); !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("%s: got %v after %d calls, want the callback error after one", name, err, calls)
+		}
+
+		if err := fit(context.Background(), nil); err != nil {
+			t.Errorf("%s: got %v without a progress function", name, err)
+		}
+	}
+
+	// the fits that take a context give up once it is done
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, name := range []string{"robust", "poisson", "trajectory"} {
+		if err := fits[name](cancelled, nil); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: got %v, want context.Canceled", name, err)
+		}
+	}
+}
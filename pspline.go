@@ -0,0 +1,99 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// WESmootherPSpline approximates WESmoother with a P-spline: the smooth is a cubic B-spline with equally spaced
+// knots dividing the series into the given number of segments, and the difference penalty of order d acts on
+// the spline coefficients instead of on every smoothed value. The linear system has segments+3 unknowns instead
+// of one per sample, so a long series can be smoothed with a system a fraction of its size. The basis is never
+// stored as a matrix; each sample only touches four coefficients.
+//
+// lambda has the same meaning as for WESmoother: since neighbouring coefficients are h = (n-1)/segments samples
+// apart, the coefficient penalty is weighted by lambda / h^(2d-1), which gives a smooth close to that of WESmoother
+// as long as the segments are short compared to the features of the smooth.
+func WESmootherPSpline(y []float64, lambda float64, d int, segments int) ([]float64, error) {
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, err
+	}
+	n := len(y)
+	if segments < 1 {
+		return nil, fmt.Errorf("number of segments %d must be positive", segments)
+	}
+	if n < 2 {
+		return nil, fmt.Errorf("%w: a P-spline needs at least 2 samples", ErrSeriesTooShort)
+	}
+	k := segments + 3
+	if k <= d {
+		return nil, fmt.Errorf("%d segments give too few coefficients for order %d", segments, d)
+	}
+
+	h := float64(n-1) / float64(segments)
+	basis := func(i int) (int, [4]float64) {
+		return cubicBSpline(float64(i)/h, segments)
+	}
+
+	// Accumulate B'B and B'y from the four non-zero basis values of every sample
+	A := mat.NewDense(k, k, nil)
+	b := make([]float64, k)
+	for i, v := range y {
+		first, values := basis(i)
+		for a, va := range values {
+			b[first+a] += va * v
+			for c, vc := range values {
+				A.Set(first+a, first+c, A.At(first+a, first+c)+va*vc)
+			}
+		}
+	}
+
+	// B'B is the weight part of the system, the penalty is scaled to the coefficient spacing
+	P := mat.NewDense(k, k, nil)
+	v := make([]float64, k-d)
+	for i := range v {
+		v[i] = lambda / math.Pow(h, float64(2*d-1))
+	}
+	addDifferencePenalty(P, d, v)
+	P.Add(P, A)
+
+	sym := mat.NewSymDense(k, nil)
+	for r := 0; r < k; r++ {
+		for c := r; c < k; c++ {
+			sym.SetSym(r, c, P.At(r, c))
+		}
+	}
+	var chol mat.Cholesky
+	if ok := chol.Factorize(sym); !ok {
+		return nil, ErrNotPositiveDefinite
+	}
+	coeffs := mat.NewVecDense(k, nil)
+	if err := chol.SolveVecTo(coeffs, mat.NewVecDense(k, b)); err != nil {
+		return nil, err
+	}
+
+	z := make([]float64, n)
+	for i := range z {
+		first, values := basis(i)
+		for a, va := range values {
+			z[i] += va * coeffs.AtVec(first+a)
+		}
+	}
+	return z, nil
+}
+
+// cubicBSpline returns the index of the first of the four cubic B-splines that are non-zero at position t, in
+// units of the knot spacing from the first knot, together with their values. t ranges over [0, segments].
+func cubicBSpline(t float64, segments int) (int, [4]float64) {
+	s := min(int(t), segments-1)
+	u := t - float64(s)
+	w := 1 - u
+	return s, [4]float64{
+		w * w * w / 6,
+		(3*u*u*u - 6*u*u + 4) / 6,
+		(-3*u*u*u + 3*u*u + 3*u + 1) / 6,
+		u * u * u / 6,
+	}
+}
@@ -0,0 +1,53 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherPSpline(t *testing.T) {
+	rng := rand.New(rand.NewSource(24))
+	n := 1000
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/80) + 0.5*math.Cos(float64(i)/33) + rng.NormFloat64()*0.2
+	}
+
+	want, err := WESmoother(y, 1e4, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	z, err := WESmootherPSpline(y, 1e4, 2, 100)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherPSpline: %v", err)
+	}
+	for i := range z {
+		if math.Abs(z[i]-want[i]) > 0.01 {
+			t.Fatalf("index %d: P-spline %f, WESmoother %f", i, z[i], want[i])
+		}
+	}
+
+	if _, err := WESmootherPSpline(y, 1e4, 2, 0); err == nil {
+		t.Errorf("expected an error for no segments")
+	}
+	if _, err := WESmootherPSpline(y, 1e4, 5, 1); err == nil {
+		t.Errorf("expected an error for too few coefficients")
+	}
+}
+
+func TestCubicBSpline(t *testing.T) {
+	for _, pos := range []float64{0, 0.3, 1, 2.5, 4} {
+		_, values := cubicBSpline(pos, 4)
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		if math.Abs(sum-1) > 1e-12 {
+			t.Errorf("basis at %f sums to %f, want 1", pos, sum)
+		}
+	}
+	if first, _ := cubicBSpline(4, 4); first != 3 {
+		t.Errorf("the last knot belongs to the last segment, got first index %d", first)
+	}
+}
@@ -0,0 +1,85 @@
+package smoother
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// WESmootherRefined applies the Whittaker-Eilers smoothing function to y like WESmoother, followed by the given
+// number of steps of iterative refinement of the solution. For long series with large lambdas and high orders
+// the system is ill-conditioned and the plain solve loses digits; one or two refinement steps recover most of
+// them at the cost of a matrix-vector product and a solve with the existing factorization per step.
+//
+// The system is factorized once with the band Cholesky factorization, and every step computes the residual with a
+// band product in O(n·d) and solves for the correction with the same factor, so the whole smooth stays O(n·d²)
+// in time and O(n·d) in memory like WESmoother.
+func WESmootherRefined(y []float64, lambda float64, d int, steps int) ([]float64, error) {
+	if steps < 0 {
+		return nil, fmt.Errorf("refinement steps %d must not be negative", steps)
+	}
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, err
+	}
+
+	n := len(y)
+	P := pooledPenaltyBand(n, d)
+	defer putBand(P)
+	A := &symBand{n: n, bw: d, data: *getFloats(len(P.data))}
+	defer putBand(A)
+	for i, v := range P.data {
+		A.data[i] = lambda * v
+	}
+	for i := 0; i < n; i++ {
+		A.add(i, i, 1)
+	}
+	chol := &bandCholesky{n: n, bw: d, data: *getFloats(len(A.data))}
+	defer putFactor(chol)
+	if err := factorizeBandInto(context.Background(), chol, A); err != nil {
+		return nil, err
+	}
+
+	z := chol.solve(y)
+	if steps == 0 {
+		return z, nil
+	}
+	corr := make([]float64, n)
+	for k := 0; k < steps; k++ {
+		bandResidualTo(corr, A, y, z)
+		chol.solveTo(corr, corr)
+		for i, c := range corr {
+			z[i] += c
+		}
+	}
+	return z, nil
+}
+
+// bandResidualTo sets res to b - A * x for the band matrix A, accumulating every row with the compensated dot
+// product of Ogita, Rump and Oishi so the residual is about as accurate as if computed in twice the precision.
+// Every row only touches the 2·bw+1 entries of the band, so this takes O(n·bw).
+func bandResidualTo(res []float64, A *symBand, b, x []float64) {
+	n, bw := A.n, A.bw
+	for i := 0; i < n; i++ {
+		sum, comp := b[i], 0.0
+		for j := max(0, i-bw); j <= min(n-1, i+bw); j++ {
+			p, pe := twoProduct(-A.at(i, j), x[j])
+			var e float64
+			sum, e = twoSum(sum, p)
+			comp += e + pe
+		}
+		res[i] = sum + comp
+	}
+}
+
+// twoSum returns a + b and the rounding error of the sum.
+func twoSum(a, b float64) (float64, float64) {
+	s := a + b
+	bb := s - a
+	return s, (a - (s - bb)) + (b - bb)
+}
+
+// twoProduct returns a * b and the rounding error of the product.
+func twoProduct(a, b float64) (float64, float64) {
+	p := a * b
+	return p, math.FMA(a, b, -p)
+}
@@ -0,0 +1,84 @@
+package smoother
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/grutz/go-whittaker-eilers/datasets"
+)
+
+// systemResidual returns the largest absolute residual of (I + λD'D) z = y.
+func systemResidual(y, z []float64, lambda float64, d int) float64 {
+	P := penaltyMatrix(len(y), lambda, d)
+	var Pz mat.VecDense
+	Pz.MulVec(P, mat.NewVecDense(len(z), z))
+
+	var worst float64
+	for i := range y {
+		worst = math.Max(worst, math.Abs(y[i]-z[i]-Pz.AtVec(i)))
+	}
+	return worst
+}
+
+func TestWESmootherRefined(t *testing.T) {
+	data, err := datasets.Load("wood")
+	if err != nil {
+		t.Fatalf("Failed to load dataset: %v", err)
+	}
+
+	lambda, d := 1e8, 3
+	plain, err := WESmoother(data, lambda, d)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	refined, err := WESmootherRefined(data, lambda, d, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherRefined: %v", err)
+	}
+
+	before, after := systemResidual(data, plain, lambda, d), systemResidual(data, refined, lambda, d)
+	if after >= before {
+		t.Errorf("refinement did not reduce the residual: %g before, %g after", before, after)
+	}
+
+	if _, err := WESmootherRefined(data, lambda, d, -1); err == nil {
+		t.Errorf("expected an error for negative refinement steps")
+	}
+}
+
+func TestWESmootherRefinedLong(t *testing.T) {
+	n, d, lambda := 200000, 3, 1e10
+	truth := make([]float64, n)
+	for i := range truth {
+		truth[i] = math.Sin(float64(i)/5000) + 0.1*math.Sin(float64(i)*1.7)
+	}
+	// y = (I + λD'D) truth, rounded once, so truth solves the system to within the rounding of y
+	A := scaledBand(differencePenaltyBand(n, d), lambda)
+	y := make([]float64, n)
+	bandResidualTo(y, A, make([]float64, n), truth)
+	for i := range y {
+		y[i] = -y[i]
+	}
+
+	maxError := func(z []float64) float64 {
+		var worst float64
+		for i := range z {
+			worst = math.Max(worst, math.Abs(z[i]-truth[i]))
+		}
+		return worst
+	}
+	plain, err := WESmoother(y, lambda, d)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	refined, err := WESmootherRefined(y, lambda, d, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherRefined: %v", err)
+	}
+	before, after := maxError(plain), maxError(refined)
+	if !(after < before/10) {
+		t.Errorf("refinement did not reduce the error: %g before, %g after", before, after)
+	}
+}
@@ -0,0 +1,56 @@
+package smoother
+
+import (
+	"math"
+)
+
+// SmoothResult holds a smoothed series together with the diagnostics of the fit, so downstream code does not
+// have to recompute them.
+type SmoothResult struct {
+	// Smooth is the smoothed series.
+	Smooth []float64
+	// Residuals holds the data minus the smooth.
+	Residuals []float64
+	// RMSE is the root mean square of the residuals.
+	RMSE float64
+	// Roughness is the sum of squared differences of order Order of the smooth.
+	Roughness float64
+	// EffectiveDF is the trace of the hat matrix of the fit.
+	EffectiveDF float64
+	// Lambda and Order are the smoothing parameter and order of differences used.
+	Lambda float64
+	Order  int
+}
+
+// WESmootherDiagnostics applies the Whittaker-Eilers smoothing function to y like WESmoother, and returns the
+// smooth with its residuals, RMSE, roughness and effective degrees of freedom. The effective degrees of freedom
+// are the trace of the band of the inverse of the band Cholesky factor, so the diagnostics take O(n·d²) like the
+// smooth.
+func WESmootherDiagnostics(y []float64, lambda float64, d int) (*SmoothResult, error) {
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, err
+	}
+
+	P := pooledPenaltyBand(len(y), d)
+	defer putBand(P)
+	z, h, err := bandSmooth(y, P, lambda)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &SmoothResult{
+		Smooth:    z,
+		Residuals: make([]float64, len(y)),
+		Roughness: Roughness(z, d),
+		Lambda:    lambda,
+		Order:     d,
+	}
+	var ss float64
+	for i := range y {
+		r.Residuals[i] = y[i] - z[i]
+		ss += r.Residuals[i] * r.Residuals[i]
+		r.EffectiveDF += h[i]
+	}
+	r.RMSE = math.Sqrt(ss / float64(len(y)))
+	return r, nil
+}
@@ -0,0 +1,56 @@
+package smoother
+
+import (
+	"math"
+	"testing"
+
+	"github.com/grutz/go-whittaker-eilers/datasets"
+)
+
+func TestWESmootherDiagnostics(t *testing.T) {
+	data, err := datasets.Load("wood")
+	if err != nil {
+		t.Fatalf("Failed to load dataset: %v", err)
+	}
+
+	r, err := WESmootherDiagnostics(data, 50, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherDiagnostics: %v", err)
+	}
+
+	clean, err := WESmoother(data, 50, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	for i := range clean {
+		if math.Abs(r.Smooth[i]-clean[i]) > 1e-9 || math.Abs(r.Residuals[i]-(data[i]-clean[i])) > 1e-9 {
+			t.Fatalf("index %d: diagnostics disagree with WESmoother", i)
+		}
+	}
+
+	df, err := EffectiveDF(50, 2, len(data))
+	if err != nil {
+		t.Fatalf("Failed to compute EffectiveDF: %v", err)
+	}
+	if math.Abs(r.EffectiveDF-df) > 1e-9 {
+		t.Errorf("EffectiveDF: got %f, want %f", r.EffectiveDF, df)
+	}
+	if r.RMSE <= 0 || math.Abs(r.Roughness-Roughness(clean, 2)) > 1e-9 || r.Lambda != 50 || r.Order != 2 {
+		t.Errorf("unexpected diagnostics: %+v", *r)
+	}
+}
+
+func TestWESmootherDiagnosticsLong(t *testing.T) {
+	n := 200000
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/1000) + 0.1*math.Sin(float64(i)*2.3)
+	}
+	r, err := WESmootherDiagnostics(y, 1e4, 3)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherDiagnostics to %d values: %v", n, err)
+	}
+	if !(r.EffectiveDF > 3) || !(r.EffectiveDF < float64(n)) || !(r.RMSE > 0) {
+		t.Errorf("unexpected diagnostics: effective df %g, RMSE %g", r.EffectiveDF, r.RMSE)
+	}
+}
@@ -0,0 +1,106 @@
+package smoother
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/grutz/go-whittaker-eilers/internal/robuststat"
+)
+
+// robustTuning is the tuning constant of the Tukey bisquare weights, in units of the robust noise scale. It gives
+// 95% efficiency for normally distributed noise.
+const robustTuning = 4.685
+
+// maxRobustIterations caps the number of reweighting steps of WESmootherRobust.
+const maxRobustIterations = 50
+
+// robustTolerance is the largest change of any weight at which the reweighting of WESmootherRobust stops.
+const robustTolerance = 1e-6
+
+// WESmootherRobust applies the Whittaker-Eilers smoothing function to y with iteratively reweighted least
+// squares, so that outliers such as spikes do not pull the smooth towards them. After every fit the residuals are
+// standardized by their median absolute deviation and the values are reweighted with Tukey's bisquare function;
+// values further than about 4.7 robust standard deviations from the smooth get zero weight.
+//
+// It returns the smooth and the final weight of every value, zero for rejected values.
+func WESmootherRobust(y []float64, lambda float64, d int) (smooth, weights []float64, err error) {
+	return robustSmooth(context.Background(), y, lambda, d, newProgressReporter(nil))
+}
+
+// robustSmooth implements WESmootherRobust, returning the error of ctx if it is done before a reweighting step
+// and reporting every step to progress.
+func robustSmooth(ctx context.Context, y []float64, lambda float64, d int, progress *progressReporter) (smooth, weights []float64, err error) {
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, nil, err
+	}
+
+	P := penaltyMatrix(len(y), lambda, d)
+	weights = make([]float64, len(y))
+	for i := range weights {
+		weights[i] = 1
+	}
+	residuals := make([]float64, len(y))
+
+	for iter := 0; iter < maxRobustIterations; iter++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		if smooth, err = solvePenalized(y, weights, P, nil); err != nil {
+			return nil, nil, err
+		}
+		for i := range y {
+			residuals[i] = y[i] - smooth[i]
+		}
+		scale := robuststat.Scale(residuals)
+		if scale == 0 {
+			// the fit is exact for more than half of the values, there is nothing left to downweight
+			break
+		}
+
+		change := 0.0
+		for i, r := range residuals {
+			w := bisquare(r / (robustTuning * scale))
+			change = math.Max(change, math.Abs(w-weights[i]))
+			weights[i] = w
+		}
+		if err := progress.report(change); err != nil {
+			return nil, nil, err
+		}
+		if change < robustTolerance {
+			break
+		}
+	}
+	return smooth, weights, nil
+}
+
+// FlagOutliers returns a mask of the values of y whose residual from smooth, standardized by the robust noise
+// scale of all residuals, exceeds threshold in absolute value. The scale is 1.4826 times the median absolute
+// deviation of the residuals, so a threshold of 3.5 flags values that are implausible for normal noise. smooth is
+// typically the result of WESmootherRobust, which is not pulled towards the outliers.
+func FlagOutliers(y, smooth []float64, threshold float64) ([]bool, error) {
+	if len(y) != len(smooth) {
+		return nil, fmt.Errorf("%w: y has %d values, smooth has %d", ErrLengthMismatch, len(y), len(smooth))
+	}
+	if !(threshold > 0) {
+		return nil, fmt.Errorf("threshold %f must be positive", threshold)
+	}
+
+	residuals := vecDiff(y, smooth)
+	scale := robuststat.Scale(residuals)
+	flags := make([]bool, len(y))
+	for i, r := range residuals {
+		// with a zero scale any residual that is not exactly zero is an outlier
+		flags[i] = math.Abs(r) > threshold*scale
+	}
+	return flags, nil
+}
+
+// bisquare returns the Tukey bisquare weight of the scaled residual u, zero for |u| >= 1.
+func bisquare(u float64) float64 {
+	if math.Abs(u) >= 1 {
+		return 0
+	}
+	v := 1 - u*u
+	return v * v
+}
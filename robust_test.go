@@ -0,0 +1,72 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherRobust(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	n := 300
+	truth := make([]float64, n)
+	y := make([]float64, n)
+	for i := range y {
+		truth[i] = math.Sin(float64(i) / 30)
+		y[i] = truth[i] + rng.NormFloat64()*0.1
+	}
+	spikes := []int{40, 41, 150, 220}
+	for _, i := range spikes {
+		y[i] += 5
+	}
+
+	plain, err := WESmoother(y, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	robust, weights, err := WESmootherRobust(y, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherRobust: %v", err)
+	}
+
+	for _, i := range spikes {
+		if weights[i] != 0 {
+			t.Errorf("index %d: spike kept weight %f", i, weights[i])
+		}
+		if math.Abs(robust[i]-truth[i]) > 0.1 {
+			t.Errorf("index %d: robust smooth %f pulled away from %f", i, robust[i], truth[i])
+		}
+		if math.Abs(plain[i]-truth[i]) < math.Abs(robust[i]-truth[i]) {
+			t.Errorf("index %d: robust smooth is no better than the plain one", i)
+		}
+	}
+
+	flags, err := FlagOutliers(y, robust, 3.5)
+	if err != nil {
+		t.Fatalf("Failed to apply FlagOutliers: %v", err)
+	}
+	flagged := 0
+	for _, f := range flags {
+		if f {
+			flagged++
+		}
+	}
+	for _, i := range spikes {
+		if !flags[i] {
+			t.Errorf("index %d: spike not flagged", i)
+		}
+	}
+	if flagged > len(spikes)+3 {
+		t.Errorf("flagged %d values, want about %d", flagged, len(spikes))
+	}
+
+	if _, err := FlagOutliers(y, robust[1:], 3.5); err == nil {
+		t.Errorf("expected an error for mismatched lengths")
+	}
+	if _, err := FlagOutliers(y, robust, 0); err == nil {
+		t.Errorf("expected an error for a zero threshold")
+	}
+	if _, _, err := WESmootherRobust([]float64{1, 2}, 100, 2); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
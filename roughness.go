@@ -0,0 +1,48 @@
+package smoother
+
+import (
+	"math"
+)
+
+// Roughness returns the sum of squared differences of order d of y, the quantity the Whittaker-Eilers penalty
+// weighs against the fit. The order d must be at least zero, NaN is returned otherwise. A series shorter than d+1
+// values has no differences and a roughness of zero.
+func Roughness(y []float64, d int) float64 {
+	if d < 0 {
+		return math.NaN()
+	}
+	coeffs := differenceCoefficients(d)
+	var sum float64
+	for i := 0; i+d < len(y); i++ {
+		var diff float64
+		for j, c := range coeffs {
+			diff += c * y[i+j]
+		}
+		sum += diff * diff
+	}
+	return sum
+}
+
+// RoughnessRatio returns how many times rougher a is than b, as the ratio of their roughness with differences of
+// order d. RoughnessRatio(input, smooth, 2) >= 10 asserts that a smooth is at least ten times smoother than its
+// input. The ratio is +Inf when b has no roughness and a does, and 1 when neither has any. Like Roughness, it is
+// NaN for a negative d.
+func RoughnessRatio(a, b []float64, d int) float64 {
+	if d < 0 {
+		return math.NaN()
+	}
+	ra, rb := Roughness(a, d), Roughness(b, d)
+	if rb == 0 {
+		if ra == 0 {
+			return 1
+		}
+		return math.Inf(1)
+	}
+	return ra / rb
+}
+
+// SmootherBy reports whether smooth is at least factor times smoother than rough, measured with differences of
+// order d. It is meant as a quantitative acceptance criterion in tests and pipelines, and is false for a negative d.
+func SmootherBy(smooth, rough []float64, d int, factor float64) bool {
+	return RoughnessRatio(rough, smooth, d) >= factor
+}
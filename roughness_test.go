@@ -0,0 +1,45 @@
+package smoother
+
+import (
+	"math"
+	"testing"
+
+	"github.com/grutz/go-whittaker-eilers/datasets"
+)
+
+func TestRoughnessRatio(t *testing.T) {
+	data, err := datasets.Load("nmr")
+	if err != nil {
+		t.Fatalf("Failed to load dataset: %v", err)
+	}
+
+	clean, err := WESmoother(data, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+
+	ratio := RoughnessRatio(data, clean, 2)
+	if ratio <= 10 {
+		t.Errorf("smooth is only %f times smoother than the input", ratio)
+	}
+	if !SmootherBy(clean, data, 2, 10) {
+		t.Errorf("SmootherBy: smooth not 10 times smoother than the input")
+	}
+	if SmootherBy(data, clean, 2, 1) {
+		t.Errorf("SmootherBy: input reported smoother than its smooth")
+	}
+
+	line := []float64{1, 2, 3, 4}
+	if r := Roughness(line, 2); r != 0 {
+		t.Errorf("Roughness of a line: got %f, want 0", r)
+	}
+	if r := RoughnessRatio(data, line, 2); !math.IsInf(r, 1) {
+		t.Errorf("RoughnessRatio against a line: got %f, want +Inf", r)
+	}
+	if r := RoughnessRatio(data, clean, -1); !math.IsNaN(r) {
+		t.Errorf("RoughnessRatio with a negative order: got %f, want NaN", r)
+	}
+	if SmootherBy(clean, data, -1, 1) {
+		t.Errorf("SmootherBy with a negative order: got true")
+	}
+}
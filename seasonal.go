@@ -0,0 +1,128 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// maxSeasonalIterations caps the number of backfitting passes of SeasonalTrend.
+const maxSeasonalIterations = 200
+
+// seasonalTolerance is the largest change of the components, relative to the scale of y, at which the
+// backfitting of SeasonalTrend stops.
+const seasonalTolerance = 1e-9
+
+// Decomposition splits a series into a slowly varying trend, a periodic seasonal component and the remainder,
+// so that Trend + Seasonal + Remainder reproduces the series.
+type Decomposition struct {
+	Trend     []float64
+	Seasonal  []float64
+	Remainder []float64
+}
+
+// SeasonalTrend decomposes y into trend, seasonal component and remainder with Whittaker-Eilers penalties only,
+// an alternative to STL. The trend is penalized by the given difference penalty. The seasonal component is
+// penalized by seasonalLambda times the squared differences between values one period apart, so a large
+// seasonalLambda gives a seasonal pattern that repeats exactly and a small one lets it evolve from cycle to
+// cycle. Every full period of the seasonal component sums to about zero so that the level stays in the trend.
+//
+// The two components are fitted by backfitting: each is smoothed in turn from y minus the other, and the seasonal
+// component is recentered after every pass, until neither changes anymore. An error is returned if they still
+// change after 200 passes.
+func SeasonalTrend(y []float64, period int, trend Penalty, seasonalLambda float64) (*Decomposition, error) {
+	n := len(y)
+	if period < 2 {
+		return nil, fmt.Errorf("period %d must span at least 2 samples", period)
+	}
+	if n < 2*period {
+		return nil, fmt.Errorf("%w: series of length %d shorter than two periods of %d", ErrSeriesTooShort, n, period)
+	}
+	if err := Validate(y, trend.Lambda, trend.Order); err != nil {
+		return nil, err
+	}
+	if err := checkLambda(seasonalLambda); err != nil {
+		return nil, err
+	}
+
+	trendSys, err := factorizePenalized(nil, penaltyMatrix(n, trend.Lambda, trend.Order), nil)
+	if err != nil {
+		return nil, err
+	}
+	seasonalSys, err := factorizePenalized(nil, seasonalPenaltyMatrix(n, period, seasonalLambda), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var scale float64
+	for _, v := range y {
+		scale = math.Max(scale, math.Abs(v))
+	}
+
+	t := make([]float64, n)
+	s := make([]float64, n)
+	partial := make([]float64, n)
+	converged := false
+	for iter := 0; iter < maxSeasonalIterations && !converged; iter++ {
+		for i := range y {
+			partial[i] = y[i] - s[i]
+		}
+		nextT, err := trendSys.solve(partial)
+		if err != nil {
+			return nil, err
+		}
+		for i := range y {
+			partial[i] = y[i] - nextT[i]
+		}
+		nextS, err := seasonalSys.solve(partial)
+		if err != nil {
+			return nil, err
+		}
+		centerSeasonal(nextS, period)
+
+		var change float64
+		for i := range y {
+			change = math.Max(change, math.Max(math.Abs(nextT[i]-t[i]), math.Abs(nextS[i]-s[i])))
+		}
+		t, s = nextT, nextS
+		converged = change <= seasonalTolerance*scale
+	}
+	if !converged {
+		return nil, fmt.Errorf("backfitting did not converge in %d iterations", maxSeasonalIterations)
+	}
+
+	r := make([]float64, n)
+	for i := range y {
+		r[i] = y[i] - t[i] - s[i]
+	}
+	return &Decomposition{Trend: t, Seasonal: s, Remainder: r}, nil
+}
+
+// seasonalPenaltyMatrix returns lambda * D' * D as a dense n x n matrix, where D takes the differences between
+// values one period apart.
+func seasonalPenaltyMatrix(n, period int, lambda float64) *mat.Dense {
+	P := mat.NewDense(n, n, nil)
+	for i := 0; i+period < n; i++ {
+		j := i + period
+		P.Set(i, i, P.At(i, i)+lambda)
+		P.Set(j, j, P.At(j, j)+lambda)
+		P.Set(i, j, P.At(i, j)-lambda)
+		P.Set(j, i, P.At(j, i)-lambda)
+	}
+	return P
+}
+
+// centerSeasonal subtracts from every value of s the mean of s over one period around it, in place. Near the
+// ends of the series the window is shifted inwards so it always spans a full period.
+func centerSeasonal(s []float64, period int) {
+	n := len(s)
+	sum := make([]float64, n+1)
+	for i, v := range s {
+		sum[i+1] = sum[i] + v
+	}
+	for i := range s {
+		lo := max(0, min(i-period/2, n-period))
+		s[i] -= (sum[lo+period] - sum[lo]) / float64(period)
+	}
+}
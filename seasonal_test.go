@@ -0,0 +1,50 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSeasonalTrend(t *testing.T) {
+	rng := rand.New(rand.NewSource(12))
+	n, period := 240, 12
+	trend := make([]float64, n)
+	season := make([]float64, n)
+	y := make([]float64, n)
+	for i := range y {
+		x := float64(i)
+		trend[i] = 5 + 0.02*x + math.Sin(x/60)
+		season[i] = math.Sin(2*math.Pi*x/float64(period)) + 0.5*math.Cos(4*math.Pi*x/float64(period))
+		y[i] = trend[i] + season[i] + rng.NormFloat64()*0.1
+	}
+
+	dec, err := SeasonalTrend(y, period, Penalty{Lambda: 1e4, Order: 2}, 100)
+	if err != nil {
+		t.Fatalf("Failed to apply SeasonalTrend: %v", err)
+	}
+
+	for i := range y {
+		if math.Abs(dec.Trend[i]+dec.Seasonal[i]+dec.Remainder[i]-y[i]) > 1e-9 {
+			t.Fatalf("index %d: components do not add up to the input", i)
+		}
+	}
+	for i := period; i < n-period; i++ {
+		if math.Abs(dec.Trend[i]-trend[i]) > 0.1 {
+			t.Errorf("index %d: trend %f, want %f", i, dec.Trend[i], trend[i])
+		}
+		if math.Abs(dec.Seasonal[i]-season[i]) > 0.1 {
+			t.Errorf("index %d: seasonal %f, want %f", i, dec.Seasonal[i], season[i])
+		}
+	}
+
+	if _, err := SeasonalTrend(y, 1, Penalty{Lambda: 1e4, Order: 2}, 100); err == nil {
+		t.Errorf("expected an error for a period of 1")
+	}
+	if _, err := SeasonalTrend(y[:20], period, Penalty{Lambda: 1e4, Order: 2}, 100); err == nil {
+		t.Errorf("expected an error for a series shorter than two periods")
+	}
+	if _, err := SeasonalTrend(y, period, Penalty{Lambda: 1e4, Order: 2}, -1); err == nil {
+		t.Errorf("expected an error for a negative seasonal lambda")
+	}
+}
@@ -0,0 +1,74 @@
+package smoother
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ActivityLambda assigns Lambda to every sample whose rolling variance is at most MaxVariance. Use math.Inf(1) as
+// MaxVariance for a level that catches every remaining sample.
+type ActivityLambda struct {
+	MaxVariance float64
+	Lambda      float64
+}
+
+// WESmootherSegmented applies the Whittaker-Eilers smoothing function to signals with quiet and active regions.
+// In a first pass the activity of y is measured as the variance in a centered rolling window of the given size,
+// and every sample is assigned the lambda of the first level, ordered by MaxVariance, whose threshold it does not
+// exceed; samples above every threshold get the lambda of the highest level. The second pass smooths y with the
+// resulting lambda for every sample, so quiet regions can be smoothed hard while transients are preserved.
+func WESmootherSegmented(y []float64, d int, window int, levels []ActivityLambda) ([]float64, error) {
+	if len(levels) == 0 {
+		return nil, errors.New("no activity levels given")
+	}
+	if window < 2 {
+		return nil, fmt.Errorf("rolling window %d must span at least 2 samples", window)
+	}
+	if err := checkLength(len(y), d); err != nil {
+		return nil, err
+	}
+	if err := checkFinite(y); err != nil {
+		return nil, err
+	}
+	for _, level := range levels {
+		if err := checkLambda(level.Lambda); err != nil {
+			return nil, fmt.Errorf("activity level %g: %w", level.MaxVariance, err)
+		}
+	}
+
+	sorted := make([]ActivityLambda, len(levels))
+	copy(sorted, levels)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].MaxVariance < sorted[j].MaxVariance })
+
+	variance := rollingVariance(y, window)
+	lambdas := make([]float64, len(y))
+	for i, v := range variance {
+		k := sort.Search(len(sorted), func(k int) bool { return v <= sorted[k].MaxVariance })
+		lambdas[i] = sorted[min(k, len(sorted)-1)].Lambda
+	}
+
+	return solvePenalized(y, nil, adaptivePenaltyMatrix(lambdas, d), nil)
+}
+
+// rollingVariance returns the variance of y in a window of the given size centered on every sample. The window
+// is truncated at the ends of the series.
+func rollingVariance(y []float64, window int) []float64 {
+	m := len(y)
+	sum := make([]float64, m+1)
+	sumSq := make([]float64, m+1)
+	for i, v := range y {
+		sum[i+1] = sum[i] + v
+		sumSq[i+1] = sumSq[i] + v*v
+	}
+
+	half := window / 2
+	variance := make([]float64, m)
+	for i := range variance {
+		lo, hi := max(0, i-half), min(m, i-half+window)
+		k := float64(hi - lo)
+		mean := (sum[hi] - sum[lo]) / k
+		variance[i] = max(0, (sumSq[hi]-sumSq[lo])/k-mean*mean)
+	}
+	return variance
+}
@@ -0,0 +1,45 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherSegmented(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	n := 300
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = rng.NormFloat64() * 0.1
+		if i >= 150 && i < 200 {
+			// an active burst in the middle of a quiet signal
+			y[i] += 5 * math.Sin(float64(i-150)/4)
+		}
+	}
+
+	levels := []ActivityLambda{
+		{MaxVariance: math.Inf(1), Lambda: 1},
+		{MaxVariance: 0.5, Lambda: 1e5},
+	}
+	clean, err := WESmootherSegmented(y, 2, 15, levels)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherSegmented: %v", err)
+	}
+
+	hard, err := WESmoother(y, 1e5, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	peak := 150 + 6
+	if math.Abs(clean[peak]-y[peak]) >= math.Abs(hard[peak]-y[peak]) {
+		t.Errorf("burst not preserved: segmented %f, uniform %f, raw %f", clean[peak], hard[peak], y[peak])
+	}
+	if math.Abs(clean[50]-hard[50]) > 0.05 {
+		t.Errorf("quiet region not smoothed hard: segmented %f, uniform %f", clean[50], hard[50])
+	}
+
+	if _, err := WESmootherSegmented(y, 2, 15, nil); err == nil {
+		t.Errorf("expected an error without activity levels")
+	}
+}
@@ -0,0 +1,63 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+)
+
+// WESmootherSigma applies the Whittaker-Eilers smoothing function to measurements y with known per-sample
+// standard deviations sigma, as reported by many instruments, and returns the smooth with a confidence band at
+// the given level. Every sample is weighted by 1/sigma², so precise samples pull harder on the smooth and a
+// sample with an infinite sigma is ignored.
+//
+// Since the weights carry the noise level, the standard errors of the band are the square roots of the diagonal
+// of (W + λD'D)⁻¹ without a noise variance estimated from the residuals. Sigma of the band instead holds the
+// square root of the reduced chi-square of the fit, about one when the reported uncertainties are right; a value
+// well above one means they are too optimistic.
+func WESmootherSigma(y, sigma []float64, lambda float64, d int, level float64) (*Band, error) {
+	if len(sigma) != len(y) {
+		return nil, fmt.Errorf("%w: sigma has %d values, y has %d", ErrLengthMismatch, len(sigma), len(y))
+	}
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, err
+	}
+	if level <= 0 || level >= 1 {
+		return nil, fmt.Errorf("confidence level %f not in (0, 1)", level)
+	}
+	w := make([]float64, len(y))
+	for i, s := range sigma {
+		if !(s > 0) {
+			return nil, fmt.Errorf("sigma %f at index %d must be positive", s, i)
+		}
+		w[i] = 1 / (s * s)
+	}
+
+	sys, err := factorizePenalized(w, penaltyMatrix(len(y), lambda, d), nil)
+	if err != nil {
+		return nil, err
+	}
+	z, err := sys.solve(y)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := sys.inverseDiagonal()
+	if err != nil {
+		return nil, err
+	}
+
+	band := newBand(z, inv, 1, level)
+	chi2, edf := weightedChiSquare(y, z, w, inv)
+	band.Sigma = math.Sqrt(chi2 / math.Max(float64(len(y))-edf, 1))
+	return band, nil
+}
+
+// weightedChiSquare returns the chi-square Σ w (y - z)² of the smooth z and its effective degrees of freedom
+// Σ w inv, the trace of the hat matrix.
+func weightedChiSquare(y, z, w, inv []float64) (chi2, edf float64) {
+	for i := range y {
+		r := y[i] - z[i]
+		chi2 += w[i] * r * r
+		edf += w[i] * inv[i]
+	}
+	return chi2, edf
+}
@@ -0,0 +1,64 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWESmootherSigma(t *testing.T) {
+	rng := rand.New(rand.NewSource(22))
+	n := 400
+	truth := make([]float64, n)
+	y := make([]float64, n)
+	sigma := make([]float64, n)
+	for i := range y {
+		// the instrument gets ten times noisier halfway through
+		truth[i] = math.Sin(float64(i) / 40)
+		sigma[i] = 0.05
+		if i >= n/2 {
+			sigma[i] = 0.5
+		}
+		y[i] = truth[i] + rng.NormFloat64()*sigma[i]
+	}
+
+	band, err := WESmootherSigma(y, sigma, 1000, 2, 0.95)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherSigma: %v", err)
+	}
+	if math.Abs(band.Sigma-1) > 0.15 {
+		t.Errorf("reduced chi-square root %f, want about 1 for correct uncertainties", band.Sigma)
+	}
+	if band.StdErr[n/4] >= band.StdErr[3*n/4] {
+		t.Errorf("precise half should have the narrower band: %f and %f", band.StdErr[n/4], band.StdErr[3*n/4])
+	}
+	covered := 0
+	for i := range truth {
+		if band.Lower[i] <= truth[i] && truth[i] <= band.Upper[i] {
+			covered++
+		}
+	}
+	if coverage := float64(covered) / float64(n); coverage < 0.85 {
+		t.Errorf("band covers only %.0f%% of the true curve", coverage*100)
+	}
+
+	// understated uncertainties show in the reduced chi-square
+	small := make([]float64, n)
+	for i := range small {
+		small[i] = sigma[i] / 3
+	}
+	if band, err = WESmootherSigma(y, small, 1000, 2, 0.95); err != nil {
+		t.Fatalf("Failed to apply WESmootherSigma: %v", err)
+	}
+	if band.Sigma < 2 {
+		t.Errorf("reduced chi-square root %f, want about 3 for understated uncertainties", band.Sigma)
+	}
+
+	sigma[3] = 0
+	if _, err := WESmootherSigma(y, sigma, 1000, 2, 0.95); err == nil {
+		t.Errorf("expected an error for a zero sigma")
+	}
+	if _, err := WESmootherSigma(y, sigma[1:], 1000, 2, 0.95); err == nil {
+		t.Errorf("expected an error for mismatched lengths")
+	}
+}
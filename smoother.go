@@ -0,0 +1,243 @@
+package smoother
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"github.com/grutz/go-whittaker-eilers/internal/robuststat"
+)
+
+// Smoother applies the Whittaker-Eilers smoothing function with a fixed lambda and order to many series, as in a
+// hot loop smoothing frames of the same size. The system I + λD'D is a band matrix that only depends on the
+// length of the series, so its band Cholesky factorization is computed on the first series of a length and
+// reused until a series of another length arrives. Smoothing then takes O(n·d) and SmoothTo allocates nothing.
+// Weights and robust reweighting are configured with the options of New.
+//
+// A Smoother is safe for concurrent use, so one instance can serve many requests. A factorization is never
+// modified once computed, callers only swap which one is cached, and every call takes its workspace from a pool.
+// Concurrent series of different lengths are smoothed correctly but keep replacing each other's factorization,
+// use one Smoother per length for those.
+type Smoother struct {
+	lambda float64
+	d      int
+	// weights holds the weight of every value, nil for unit weights.
+	weights []float64
+	// robust reweights the values with the bisquare function with the tuning constant tuning.
+	robust bool
+	tuning float64
+	// nanPolicy selects how NaN and infinite values are treated.
+	nanPolicy NaNPolicy
+	// penalty replaces D'D when it is not nil, fixing the length of the series.
+	penalty *symBand
+	// cache holds the penalties D'D of recent series lengths, nil when they come from the pool.
+	cache *penaltyCache
+	// inPlace makes Smooth overwrite its input.
+	inPlace bool
+
+	chol atomic.Pointer[bandCholesky]
+}
+
+// NewSmoother returns a Smoother for the smoothing parameter lambda and order d. It is short for
+// New(WithLambda(lambda), WithOrder(d)).
+func NewSmoother(lambda float64, d int) (*Smoother, error) {
+	return New(WithLambda(lambda), WithOrder(d))
+}
+
+// Lambda returns the smoothing parameter of the Smoother.
+func (s *Smoother) Lambda() float64 {
+	return s.lambda
+}
+
+// Order returns the order of differences of the Smoother.
+func (s *Smoother) Order() int {
+	return s.d
+}
+
+// Smooth returns the smooth of y, like WESmoother. With WithInPlace the smooth overwrites y and y is returned.
+func (s *Smoother) Smooth(y []float64) ([]float64, error) {
+	if s.inPlace {
+		if err := s.SmoothTo(y, y); err != nil {
+			return nil, err
+		}
+		return y, nil
+	}
+	z := make([]float64, len(y))
+	if err := s.SmoothTo(z, y); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// SmoothTo writes the smooth of y into dst, which must be as long as y and may be y itself. Once the
+// factorization for the length of y is cached it does not allocate, unless the Smoother is robust.
+func (s *Smoother) SmoothTo(dst, y []float64) error {
+	if len(dst) != len(y) {
+		return fmt.Errorf("%w: dst has %d values, y has %d", ErrLengthMismatch, len(dst), len(y))
+	}
+	if s.weights != nil && len(y) != len(s.weights) {
+		return fmt.Errorf("%w: y has %d values, the Smoother has %d weights", ErrLengthMismatch, len(y), len(s.weights))
+	}
+	if s.penalty != nil && len(y) != s.penalty.n {
+		return fmt.Errorf("%w: y has %d values, the penalty is %d x %d", ErrLengthMismatch, len(y), s.penalty.n, s.penalty.n)
+	}
+	if err := checkLength(len(y), s.d); err != nil {
+		return err
+	}
+	if err := checkFinite(y); err != nil {
+		if s.nanPolicy == NaNError {
+			return err
+		}
+		return s.nonFiniteTo(dst, y, err)
+	}
+	if s.robust {
+		return s.robustTo(dst, y, s.weights)
+	}
+	chol, err := s.factorization(len(y))
+	if err != nil {
+		return err
+	}
+	if s.weights == nil {
+		chol.solveTo(dst, y)
+		return nil
+	}
+	for i, w := range s.weights {
+		dst[i] = w * y[i]
+	}
+	chol.solveTo(dst, dst)
+	return nil
+}
+
+// weightedTo writes the smooth of y with the weights w, nil for unit weights, into dst. Unlike SmoothTo it
+// ignores the weights of the Smoother, so it serves series derived from the input such as the finite values.
+func (s *Smoother) weightedTo(dst, y, w []float64) error {
+	if s.robust {
+		return s.robustTo(dst, y, w)
+	}
+	if w == nil && s.weights == nil {
+		chol, err := s.factorization(len(y))
+		if err != nil {
+			return err
+		}
+		chol.solveTo(dst, y)
+		return nil
+	}
+	P := s.penaltyBand(len(y))
+	defer s.releasePenalty(P)
+	chol, err := pooledFactor(context.Background(), P, s.lambda, w)
+	if err != nil {
+		return err
+	}
+	defer putFactor(chol)
+	buf := getFloats(len(y))
+	defer putFloats(buf)
+	rhs := *buf
+	for i := range y {
+		rhs[i] = y[i]
+		if w != nil {
+			rhs[i] *= w[i]
+		}
+	}
+	chol.solveTo(dst, rhs)
+	return nil
+}
+
+// robustTo writes the robust smooth of y with the prior weights base, nil for unit weights, into dst. The values
+// are reweighted with the bisquare function until the weights settle as WESmootherRobust does; values with a zero
+// prior weight do not take part in the robust scale.
+func (s *Smoother) robustTo(dst, y, base []float64) error {
+	n := len(y)
+	P := s.penaltyBand(n)
+	defer s.releasePenalty(P)
+	bufs := [5]*[]float64{getFloats(n), getFloats(n), getFloats(n), getFloats(n), getFloats(n)}
+	defer func() {
+		for _, b := range bufs {
+			putFloats(b)
+		}
+	}()
+	robust, w, rhs, z, residuals := *bufs[0], *bufs[1], *bufs[2], *bufs[3], (*bufs[4])[:0]
+	for i := range robust {
+		robust[i] = 1
+	}
+
+	for iter := 0; iter < maxRobustIterations; iter++ {
+		for i := range w {
+			w[i] = robust[i]
+			if base != nil {
+				w[i] *= base[i]
+			}
+			rhs[i] = w[i] * y[i]
+		}
+		chol, err := pooledFactor(context.Background(), P, s.lambda, w)
+		if err != nil {
+			return err
+		}
+		chol.solveTo(z, rhs)
+		putFactor(chol)
+
+		residuals = residuals[:0]
+		for i := range y {
+			if base == nil || base[i] > 0 {
+				residuals = append(residuals, y[i]-z[i])
+			}
+		}
+		scale := robuststat.Scale(residuals)
+		if scale == 0 {
+			// the fit is exact for more than half of the values, there is nothing left to downweight
+			break
+		}
+		change := 0.0
+		for i := range y {
+			v := bisquare((y[i] - z[i]) / (s.tuning * scale))
+			change = math.Max(change, math.Abs(v-robust[i]))
+			robust[i] = v
+		}
+		if change < robustTolerance {
+			break
+		}
+	}
+	copy(dst, z)
+	return nil
+}
+
+// factorization returns the band Cholesky factorization for series of length n, computing and caching it if the
+// cached one is for another length.
+func (s *Smoother) factorization(n int) (*bandCholesky, error) {
+	if chol := s.chol.Load(); chol != nil && chol.n == n {
+		return chol, nil
+	}
+	P := s.penaltyBand(n)
+	defer s.releasePenalty(P)
+	var chol *bandCholesky
+	var err error
+	if s.weights != nil {
+		chol, err = factorizeBand(weightedBand(P, s.lambda, s.weights))
+	} else {
+		chol, err = factorizeScaledBand(P, s.lambda)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.chol.Store(chol)
+	return chol, nil
+}
+
+// penaltyBand returns the penalty for series of length n, the custom one if the Smoother has one and D'D of its
+// order from the cache or the pool otherwise. Hand it back with releasePenalty.
+func (s *Smoother) penaltyBand(n int) *symBand {
+	switch {
+	case s.penalty != nil:
+		return s.penalty
+	case s.cache != nil:
+		return s.cache.get(n, s.d)
+	}
+	return pooledPenaltyBand(n, s.d)
+}
+
+// releasePenalty returns a penalty obtained from penaltyBand to the pool unless it is the custom or a cached one.
+func (s *Smoother) releasePenalty(P *symBand) {
+	if P != s.penalty && s.cache == nil {
+		putBand(P)
+	}
+}
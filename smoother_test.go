@@ -0,0 +1,156 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSmoother(t *testing.T) {
+	rng := rand.New(rand.NewSource(30))
+	s, err := NewSmoother(100, 2)
+	if err != nil {
+		t.Fatalf("Failed to create Smoother: %v", err)
+	}
+	if s.Lambda() != 100 || s.Order() != 2 {
+		t.Errorf("got lambda %f and order %d, want 100 and 2", s.Lambda(), s.Order())
+	}
+
+	// series of alternating lengths refactorize, the results match WESmoother
+	for _, n := range []int{300, 300, 120, 300} {
+		y := make([]float64, n)
+		for i := range y {
+			y[i] = math.Sin(float64(i)/25) + rng.NormFloat64()*0.2
+		}
+		z, err := s.Smooth(y)
+		if err != nil {
+			t.Fatalf("Failed to smooth: %v", err)
+		}
+		want, err := WESmoother(y, 100, 2)
+		if err != nil {
+			t.Fatalf("Failed to apply WESmoother: %v", err)
+		}
+		for i := range want {
+			if math.Abs(z[i]-want[i]) > 1e-9 {
+				t.Fatalf("length %d, index %d: got %f, want %f", n, i, z[i], want[i])
+			}
+		}
+
+		// in place gives the same result
+		if err := s.SmoothTo(y, y); err != nil {
+			t.Fatalf("Failed to smooth in place: %v", err)
+		}
+		for i := range z {
+			if y[i] != z[i] {
+				t.Fatalf("length %d, index %d: in place %f, want %f", n, i, y[i], z[i])
+			}
+		}
+	}
+
+	if err := s.SmoothTo(make([]float64, 3), make([]float64, 4)); err == nil {
+		t.Errorf("expected an error for mismatched lengths")
+	}
+	if _, err := s.Smooth([]float64{1, 2}); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+	if _, err := NewSmoother(-1, 2); err == nil {
+		t.Errorf("expected an error for a negative lambda")
+	}
+	if _, err := NewSmoother(1, -1); err == nil {
+		t.Errorf("expected an error for a negative order")
+	}
+}
+
+func TestSmootherConcurrent(t *testing.T) {
+	s, err := NewSmoother(100, 2)
+	if err != nil {
+		t.Fatalf("Failed to create Smoother: %v", err)
+	}
+	// goroutines with two lengths keep swapping the cached factorization
+	series := make([][]float64, 2)
+	wants := make([][]float64, 2)
+	for k, n := range []int{250, 400} {
+		series[k] = make([]float64, n)
+		for i := range series[k] {
+			series[k][i] = math.Sin(float64(i)/(20+float64(k))) + 0.3*math.Cos(float64(i)*1.3)
+		}
+		if wants[k], err = WESmoother(series[k], 100, 2); err != nil {
+			t.Fatalf("Failed to apply WESmoother: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 16)
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			y, want := series[k], wants[k]
+			m := mat.NewDense(len(y), 2, nil)
+			dst := m.ColView(1).(*mat.VecDense)
+			for rep := 0; rep < 50; rep++ {
+				if err := s.SmoothVecTo(dst, mat.NewVecDense(len(y), y)); err != nil {
+					errs <- err.Error()
+					return
+				}
+				for i := range want {
+					if math.Abs(dst.AtVec(i)-want[i]) > 1e-9 {
+						errs <- "wrong smooth"
+						return
+					}
+				}
+			}
+		}(g % 2)
+	}
+	wg.Wait()
+	close(errs)
+	for e := range errs {
+		t.Errorf("concurrent smoothing: %s", e)
+	}
+}
+
+func TestSmoothToAllocations(t *testing.T) {
+	s, err := NewSmoother(100, 2)
+	if err != nil {
+		t.Fatalf("Failed to create Smoother: %v", err)
+	}
+	y := make([]float64, 1000)
+	for i := range y {
+		y[i] = math.Sin(float64(i) / 25)
+	}
+	dst := make([]float64, len(y))
+	if err := s.SmoothTo(dst, y); err != nil {
+		t.Fatalf("Failed to smooth: %v", err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := s.SmoothTo(dst, y); err != nil {
+			t.Fatalf("Failed to smooth: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("SmoothTo allocated %f times per call, want 0", allocs)
+	}
+}
+
+func BenchmarkSmoothTo(b *testing.B) {
+	s, err := NewSmoother(100, 2)
+	if err != nil {
+		b.Fatalf("Failed to create Smoother: %v", err)
+	}
+	y := make([]float64, 1024)
+	for i := range y {
+		y[i] = math.Sin(float64(i) / 25)
+	}
+	dst := make([]float64, len(y))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := s.SmoothTo(dst, y); err != nil {
+			b.Fatalf("Failed to smooth: %v", err)
+		}
+	}
+}
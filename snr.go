@@ -0,0 +1,72 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+)
+
+// SNR applies the Whittaker-Eilers smoothing function to y and estimates the signal-to-noise ratio of y as the
+// ratio of the power of the smooth to the power of the noise. The signal power is the mean square of the smooth,
+// so a constant offset counts as signal; remove a baseline first if it should not. The noise power is the sum of
+// squared residuals divided by the residual degrees of freedom, n minus the trace of the hat matrix, since the
+// residuals alone underestimate the noise the smooth has absorbed.
+//
+// The ratio is linear; 10·log10 of it gives decibels. It is infinite when the smooth fits y exactly.
+func SNR(y []float64, lambda float64, d int) (float64, error) {
+	z, h, err := smoothWithHat(y, lambda, d)
+	if err != nil {
+		return 0, err
+	}
+	return powerRatio(y, z, h), nil
+}
+
+// WindowedSNR is like SNR, but estimates the signal-to-noise ratio separately in consecutive windows of the given
+// number of samples, so changes in acquisition quality along the series show up. The series is smoothed as a
+// whole; the last window is shorter when the length of y is not a multiple of window.
+func WindowedSNR(y []float64, lambda float64, d int, window int) ([]float64, error) {
+	if window < 1 {
+		return nil, fmt.Errorf("window %d must hold at least one sample", window)
+	}
+	z, h, err := smoothWithHat(y, lambda, d)
+	if err != nil {
+		return nil, err
+	}
+
+	ratios := make([]float64, 0, (len(y)+window-1)/window)
+	for lo := 0; lo < len(y); lo += window {
+		hi := min(lo+window, len(y))
+		ratios = append(ratios, powerRatio(y[lo:hi], z[lo:hi], h[lo:hi]))
+	}
+	return ratios, nil
+}
+
+// smoothWithHat returns the smooth of y together with the diagonal of the hat matrix of the smoother, both from
+// the band Cholesky factor in O(n·d²).
+func smoothWithHat(y []float64, lambda float64, d int) (z, h []float64, err error) {
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, nil, err
+	}
+	P := pooledPenaltyBand(len(y), d)
+	defer putBand(P)
+	return bandSmooth(y, P, lambda)
+}
+
+// powerRatio returns the mean square of z divided by the noise variance estimated from the residuals y - z and
+// the hat diagonal h. It is infinite when the residuals vanish and NaN when no residual degrees of freedom are
+// left to estimate the noise from.
+func powerRatio(y, z, h []float64) float64 {
+	var signal, rss, edf float64
+	for i := range y {
+		r := y[i] - z[i]
+		signal += z[i] * z[i]
+		rss += r * r
+		edf += h[i]
+	}
+	switch {
+	case rss == 0:
+		return math.Inf(1)
+	case float64(len(y)) <= edf:
+		return math.NaN()
+	}
+	return (signal / float64(len(y))) / (rss / (float64(len(y)) - edf))
+}
@@ -0,0 +1,67 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSNR(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	n := 1000
+	y := make([]float64, n)
+	for i := range y {
+		// the noise is four times as strong in the second half
+		sigma := 0.1
+		if i >= n/2 {
+			sigma = 0.4
+		}
+		y[i] = math.Sin(float64(i)/40) + rng.NormFloat64()*sigma
+	}
+
+	// A unit sine has a power of 0.5
+	snr, err := SNR(y, 1000, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply SNR: %v", err)
+	}
+	want := 0.5 / ((0.1*0.1 + 0.4*0.4) / 2)
+	if math.Abs(snr-want) > 0.15*want {
+		t.Errorf("SNR: got %f, want about %f", snr, want)
+	}
+
+	ratios, err := WindowedSNR(y, 1000, 2, 300)
+	if err != nil {
+		t.Fatalf("Failed to apply WindowedSNR: %v", err)
+	}
+	if len(ratios) != 4 {
+		t.Fatalf("got %d windows, want 4", len(ratios))
+	}
+	if ratios[0] < 10*ratios[2] {
+		t.Errorf("expected the quiet window to have a much higher SNR: %v", ratios)
+	}
+
+	if _, err := WindowedSNR(y, 1000, 2, 0); err == nil {
+		t.Errorf("expected an error for an empty window")
+	}
+	if _, err := SNR([]float64{1, 2}, 1000, 2); err == nil {
+		t.Errorf("expected an error for a too short series")
+	}
+}
+
+func TestWindowedSNRLong(t *testing.T) {
+	n := 200000
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/1000) + 0.1*math.Sin(float64(i)*2.3)
+	}
+	ratios, err := WindowedSNR(y, 1e4, 2, n/4)
+	if err != nil {
+		t.Fatalf("Failed to apply WindowedSNR to %d values: %v", n, err)
+	}
+	if len(ratios) != 4 || !(ratios[0] > 1) {
+		t.Errorf("got ratios %v", ratios)
+	}
+	if _, _, err := NoiseVariance(y, 1e4, 2); err != nil {
+		t.Errorf("Failed to apply NoiseVariance to %d values: %v", n, err)
+	}
+}
@@ -0,0 +1,89 @@
+package smoother
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+// Solver selects how WESmoother solves the system (I + λD'D) z = y.
+type Solver int
+
+const (
+	// SolverAuto solves first and second order penalties with the tridiagonal and pentadiagonal fast paths and
+	// higher orders with SolverLAPACK. It is the default.
+	SolverAuto Solver = iota
+	// SolverBand solves every order with the band Cholesky factorization of this package, in pure Go and without
+	// going through BLAS or LAPACK.
+	SolverBand
+	// SolverLAPACK solves every order with the band Cholesky routines of gonum's lapack64, which call the LAPACK
+	// implementation installed with lapack64.Use, such as a cgo binding of an optimized library. Such an
+	// implementation usually has its own threading settings, for example OPENBLAS_NUM_THREADS.
+	SolverLAPACK
+)
+
+// solver holds the Solver set by SetSolver.
+var solver atomic.Int64
+
+// parallelism holds the worker count set by SetParallelism, zero for GOMAXPROCS.
+var parallelism atomic.Int64
+
+// SetSolver selects the solver WESmoother uses from now on, for operators trading the latency of the fast paths
+// against an optimized LAPACK on many-core hosts. It is safe to call concurrently with smoothing.
+func SetSolver(s Solver) error {
+	if s != SolverAuto && s != SolverBand && s != SolverLAPACK {
+		return fmt.Errorf("unknown solver %d", s)
+	}
+	solver.Store(int64(s))
+	return nil
+}
+
+// CurrentSolver returns the solver set by SetSolver.
+func CurrentSolver() Solver {
+	return Solver(solver.Load())
+}
+
+// SetParallelism sets the number of goroutines SmoothSweepParallel and SmoothAll use when they are given a
+// worker count of zero or less, GOMAXPROCS when n is zero or less. Every series is solved on a single goroutine;
+// the dense matrix products of gonum's own BLAS size their worker count by GOMAXPROCS, which only the runtime
+// controls.
+func SetParallelism(n int) {
+	parallelism.Store(int64(max(n, 0)))
+}
+
+// defaultWorkers returns the worker count set by SetParallelism.
+func defaultWorkers() int {
+	if n := parallelism.Load(); n > 0 {
+		return int(n)
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// smoothTo writes the smooth of the validated series y with the given solver into z, which may be y. z is only
+// written once the system has been factorized.
+func smoothTo(s Solver, z, y []float64, lambda float64, d int) error {
+	switch {
+	case s == SolverAuto && d == 1:
+		return smoothTridiagonalTo(z, y, lambda)
+	case s == SolverAuto && d == 2:
+		return smoothPentadiagonalTo(z, y, lambda)
+	case s == SolverBand:
+		return smoothBandTo(z, y, lambda, d)
+	}
+	return smoothBandCholeskyTo(z, y, lambda, d)
+}
+
+// smoothBandTo sets z, which may be y, to the solution of (I + λD'D) z = y with the band Cholesky factorization of
+// this package.
+func smoothBandTo(z, y []float64, lambda float64, d int) error {
+	P := pooledPenaltyBand(len(y), d)
+	defer putBand(P)
+	chol, err := pooledFactor(context.Background(), P, lambda, nil)
+	if err != nil {
+		return err
+	}
+	defer putFactor(chol)
+	chol.solveTo(z, y)
+	return nil
+}
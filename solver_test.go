@@ -0,0 +1,54 @@
+package smoother
+
+import (
+	"math"
+	"testing"
+
+	"github.com/grutz/go-whittaker-eilers/datasets"
+)
+
+func TestSetSolver(t *testing.T) {
+	defer SetSolver(SolverAuto)
+	data, err := datasets.Load("wood")
+	if err != nil {
+		t.Fatalf("Failed to load dataset: %v", err)
+	}
+	for _, d := range []int{1, 2, 3} {
+		want, err := solvePenalized(data, nil, penaltyMatrix(len(data), 10, d), nil)
+		if err != nil {
+			t.Fatalf("order %d: Failed to solve: %v", d, err)
+		}
+		for _, s := range []Solver{SolverAuto, SolverBand, SolverLAPACK} {
+			if err := SetSolver(s); err != nil {
+				t.Fatalf("Failed to set solver %d: %v", s, err)
+			}
+			if CurrentSolver() != s {
+				t.Errorf("got solver %d, want %d", CurrentSolver(), s)
+			}
+			z, err := WESmoother(data, 10, d)
+			if err != nil {
+				t.Fatalf("solver %d, order %d: Failed to smooth: %v", s, d, err)
+			}
+			for i := range want {
+				if math.Abs(z[i]-want[i]) > 1e-8 {
+					t.Fatalf("solver %d, order %d, index %d: got %f, want %f", s, d, i, z[i], want[i])
+				}
+			}
+		}
+	}
+	if err := SetSolver(Solver(7)); err == nil {
+		t.Errorf("expected an error for an unknown solver")
+	}
+}
+
+func TestSetParallelism(t *testing.T) {
+	defer SetParallelism(0)
+	SetParallelism(3)
+	if got := defaultWorkers(); got != 3 {
+		t.Errorf("got %d workers, want 3", got)
+	}
+	SetParallelism(-2)
+	if got := defaultWorkers(); got < 1 {
+		t.Errorf("got %d workers, want GOMAXPROCS", got)
+	}
+}
@@ -0,0 +1,212 @@
+package smoother
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// RelearnConfig controls how a StreamSmoother retunes its lambda in the background.
+type RelearnConfig struct {
+	// Every is the number of pushed samples between relearning runs.
+	Every int
+	// Buffer is the number of trailing samples cross-validated on each run, the window size when zero.
+	Buffer int
+	// Lambdas is the grid of candidate lambdas.
+	Lambdas []float64
+	// Hysteresis is the relative improvement of the cross-validation error needed before lambda is swapped,
+	// 0.05 requires the new lambda to predict at least 5% better than the current one.
+	Hysteresis float64
+	// MinRatio is the factor by which the optimum has to differ from the current lambda before it is swapped,
+	// so small drifts along a flat error curve do not cause constant swapping. Ignored when at most 1.
+	MinRatio float64
+	// OnSwap is called from the relearning goroutine whenever lambda is swapped, if not nil.
+	OnSwap func(old, new float64)
+}
+
+// StreamSmoother smooths an unbounded stream of samples, such as the readings of a long-running service, over a
+// trailing window. Every pushed sample is smoothed together with the samples before it and the smoothed value of
+// the newest sample is returned. The band factorization of the window is kept while its length and lambda stay the
+// same, so once the window is full a push takes O(window·d). It is safe for concurrent use.
+type StreamSmoother struct {
+	mu     sync.Mutex
+	size   int
+	d      int
+	lambda float64
+	window []float64
+	pushed int
+	// chol is the factorization of the full or filling window, nil after lambda was swapped, and z the smooth
+	// of the window.
+	chol *bandCholesky
+	z    []float64
+
+	relearn *RelearnConfig
+	jobs    chan []float64
+	wg      sync.WaitGroup
+}
+
+// NewStreamSmoother returns a StreamSmoother smoothing over the last window samples with lambda and order d.
+func NewStreamSmoother(window int, lambda float64, d int) (*StreamSmoother, error) {
+	if err := checkLength(d+1, d); err != nil {
+		return nil, err
+	}
+	if err := checkLambda(lambda); err != nil {
+		return nil, err
+	}
+	if window <= d {
+		return nil, fmt.Errorf("window of %d samples too short for order %d", window, d)
+	}
+	return &StreamSmoother{size: window, d: d, lambda: lambda, window: make([]float64, 0, window)}, nil
+}
+
+// Relearn starts a background goroutine that periodically cross-validates the candidate lambdas on the trailing
+// samples and hot-swaps lambda when the optimum shifts materially, so the stream stays well-tuned as the signal
+// drifts. A run is skipped if the previous one is still busy. Call Close to stop it.
+func (s *StreamSmoother) Relearn(cfg RelearnConfig) error {
+	if cfg.Every <= 0 {
+		return fmt.Errorf("relearning interval %d must be positive", cfg.Every)
+	}
+	if len(cfg.Lambdas) == 0 {
+		return errors.New("no candidate lambdas given")
+	}
+	if cfg.Buffer == 0 {
+		cfg.Buffer = s.size
+	}
+	if cfg.Hysteresis < 0 {
+		return fmt.Errorf("hysteresis %f must not be negative", cfg.Hysteresis)
+	}
+	if cfg.Buffer <= s.d {
+		return fmt.Errorf("relearning buffer of %d samples too short for order %d", cfg.Buffer, s.d)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.relearn != nil {
+		return errors.New("relearning already started")
+	}
+	s.relearn = &cfg
+	s.jobs = make(chan []float64, 1)
+	s.wg.Add(1)
+	go s.relearnLoop(cfg, s.jobs)
+	return nil
+}
+
+// Close stops relearning, waiting for a pending run to finish. Relearning can be started again afterwards.
+func (s *StreamSmoother) Close() {
+	s.mu.Lock()
+	jobs := s.jobs
+	s.jobs = nil
+	s.relearn = nil
+	s.mu.Unlock()
+
+	if jobs != nil {
+		close(jobs)
+		s.wg.Wait()
+	}
+}
+
+// Lambda returns the lambda currently used to smooth the stream.
+func (s *StreamSmoother) Lambda() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lambda
+}
+
+// Push adds a sample to the stream and returns the smoothed value of that sample. Until more than d samples have
+// been pushed there is nothing to smooth and the sample is returned unchanged. A NaN or infinite sample is
+// rejected with an *InputError and left out of the window.
+func (s *StreamSmoother) Push(v float64) (float64, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, &InputError{
+			Field:      "y",
+			Problem:    fmt.Sprintf("pushed sample %g is not finite", v),
+			Suggestion: "skip missing samples instead of pushing them",
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.window) == s.size {
+		copy(s.window, s.window[1:])
+		s.window = s.window[:s.size-1]
+	}
+	s.window = append(s.window, v)
+	s.pushed++
+
+	if s.relearn != nil && s.jobs != nil && s.pushed%s.relearn.Every == 0 {
+		s.enqueueRelearn()
+	}
+
+	m := len(s.window)
+	if m <= s.d {
+		return v, nil
+	}
+
+	// The factorization only changes while the window fills up or when lambda is swapped
+	if s.chol == nil || s.chol.n != m {
+		P := pooledPenaltyBand(m, s.d)
+		chol, err := factorizeScaledBand(P, s.lambda)
+		putBand(P)
+		if err != nil {
+			return 0, err
+		}
+		s.chol = chol
+	}
+	if cap(s.z) < m {
+		s.z = make([]float64, s.size)
+	}
+	s.z = s.z[:m]
+	s.chol.solveTo(s.z, s.window)
+	return s.z[m-1], nil
+}
+
+// enqueueRelearn hands a copy of the trailing samples to the relearning goroutine unless it is still busy.
+func (s *StreamSmoother) enqueueRelearn() {
+	n := min(s.relearn.Buffer, len(s.window))
+	if n <= s.d {
+		return
+	}
+	buf := make([]float64, n)
+	copy(buf, s.window[len(s.window)-n:])
+	select {
+	case s.jobs <- buf:
+	default:
+	}
+}
+
+// relearnLoop cross-validates every buffer it receives and swaps lambda when a candidate wins by enough.
+func (s *StreamSmoother) relearnLoop(cfg RelearnConfig, jobs <-chan []float64) {
+	defer s.wg.Done()
+	for buf := range jobs {
+		current := s.Lambda()
+		currentCV, err := CrossValidationError(buf, current, s.d)
+		if err != nil {
+			continue
+		}
+
+		best, bestCV := current, currentCV
+		for _, lambda := range cfg.Lambdas {
+			cv, err := CrossValidationError(buf, lambda, s.d)
+			if err == nil && cv < bestCV {
+				best, bestCV = lambda, cv
+			}
+		}
+
+		if bestCV >= currentCV*(1-cfg.Hysteresis) {
+			continue
+		}
+		if cfg.MinRatio > 1 && math.Max(best/current, current/best) < cfg.MinRatio {
+			continue
+		}
+
+		s.mu.Lock()
+		old := s.lambda
+		s.lambda = best
+		s.chol = nil
+		s.mu.Unlock()
+		if cfg.OnSwap != nil {
+			cfg.OnSwap(old, best)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package smoother
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestStreamSmoother(t *testing.T) {
+	s, err := NewStreamSmoother(100, 1e-3, 2)
+	if err != nil {
+		t.Fatalf("Failed to create StreamSmoother: %v", err)
+	}
+
+	swaps := 0
+	err = s.Relearn(RelearnConfig{
+		Every:      200,
+		Lambdas:    []float64{1e-3, 1e-1, 10, 1e3, 1e5},
+		Hysteresis: 0.05,
+		OnSwap:     func(old, new float64) { swaps++ },
+	})
+	if err != nil {
+		t.Fatalf("Failed to start relearning: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(13))
+	for i := 0; i < 1000; i++ {
+		v := math.Sin(float64(i)/50) + rng.NormFloat64()*0.5
+		z, err := s.Push(v)
+		if err != nil {
+			t.Fatalf("Failed to push sample %d: %v", i, err)
+		}
+		if math.IsNaN(z) {
+			t.Fatalf("sample %d: smoothed value is NaN", i)
+		}
+	}
+	s.Close()
+
+	if s.Lambda() <= 1 {
+		t.Errorf("lambda not relearned for noisy data: got %g", s.Lambda())
+	}
+	if swaps == 0 {
+		t.Errorf("OnSwap never called")
+	}
+
+	if err := s.Relearn(RelearnConfig{Every: 10, Lambdas: []float64{1}, Hysteresis: -0.1}); err == nil {
+		t.Errorf("expected an error for a negative hysteresis")
+	}
+	if err := s.Relearn(RelearnConfig{Every: 10, Lambdas: []float64{1}}); err != nil {
+		t.Errorf("Failed to restart relearning after Close: %v", err)
+	}
+	if err := s.Relearn(RelearnConfig{Every: 10, Lambdas: []float64{1}}); err == nil {
+		t.Errorf("expected an error when starting relearning twice")
+	}
+	s.Close()
+}
+
+func TestStreamSmootherPush(t *testing.T) {
+	window := 1000
+	s, err := NewStreamSmoother(window, 1e3, 3)
+	if err != nil {
+		t.Fatalf("Failed to create StreamSmoother: %v", err)
+	}
+	y := make([]float64, 3*window)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/300) + 0.1*float64(i%7)
+	}
+	var last float64
+	for i, v := range y {
+		if last, err = s.Push(v); err != nil {
+			t.Fatalf("Failed to push sample %d: %v", i, err)
+		}
+		if i == window/2 || i == len(y)-1 {
+			want, err := WESmoother(y[max(0, i+1-window):i+1], 1e3, 3)
+			if err != nil {
+				t.Fatalf("Failed to apply WESmoother: %v", err)
+			}
+			if math.Abs(last-want[len(want)-1]) > 1e-9 {
+				t.Errorf("sample %d: got %g, want %g of the window", i, last, want[len(want)-1])
+			}
+		}
+	}
+
+	// a sample that is not finite is rejected and left out of the window
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		var inputErr *InputError
+		if _, err := s.Push(v); !errors.As(err, &inputErr) {
+			t.Errorf("got %v for pushing %g, want an *InputError", err, v)
+		}
+	}
+	if z, err := s.Push(y[len(y)-1]); err != nil || math.IsNaN(z) {
+		t.Errorf("got %g and %v after rejected samples", z, err)
+	}
+}
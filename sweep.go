@@ -0,0 +1,113 @@
+package smoother
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// SweepResult is the smooth of a series for one lambda of a sweep, with its cross-validation error.
+type SweepResult struct {
+	Lambda float64
+	Smooth []float64
+	// CVError is the root mean square leave-one-out prediction error, as returned by CrossValidationError.
+	CVError float64
+}
+
+// SmoothSweep smooths y with every lambda in lambdas and order d, returning the smooths and their cross-validation
+// errors in the order of lambdas, as needed for picking a lambda from a grid.
+//
+// The penalty D'D is built once as a band matrix of bandwidth d and reused for every lambda. Each lambda then
+// takes a band Cholesky factorization of I + λD'D, a solve, and the hat diagonal from the band of the inverse,
+// each O(n·d²), instead of building the penalty anew for every call of WESmoother and CrossValidationError.
+func SmoothSweep(y []float64, lambdas []float64, d int) ([]SweepResult, error) {
+	return SmoothSweepParallel(y, lambdas, d, 1)
+}
+
+// SmoothSweepParallel is like SmoothSweep, but spreads the lambdas over the given number of goroutines, since
+// every lambda is solved independently. A worker count of zero or less uses the workers set by SetParallelism,
+// GOMAXPROCS by default. The results are in the order of lambdas whatever the number of workers.
+func SmoothSweepParallel(y []float64, lambdas []float64, d int, workers int) ([]SweepResult, error) {
+	if len(lambdas) == 0 {
+		return nil, errors.New("no lambdas given")
+	}
+	if err := checkLength(len(y), d); err != nil {
+		return nil, err
+	}
+	if err := checkFinite(y); err != nil {
+		return nil, err
+	}
+	for i, lambda := range lambdas {
+		if err := checkLambda(lambda); err != nil {
+			return nil, fmt.Errorf("lambda at index %d: %w", i, err)
+		}
+	}
+
+	if workers <= 0 {
+		workers = defaultWorkers()
+	}
+	workers = min(workers, len(lambdas))
+
+	// The penalty is only read by the workers, so they share it
+	P := differencePenaltyBand(len(y), d)
+	results := make([]SweepResult, len(lambdas))
+	errs := make([]error, len(lambdas))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				z, cv, err := sweepSmooth(y, P, lambdas[k])
+				results[k] = SweepResult{Lambda: lambdas[k], Smooth: z, CVError: cv}
+				errs[k] = err
+			}
+		}()
+	}
+	for k := range lambdas {
+		jobs <- k
+	}
+	close(jobs)
+	wg.Wait()
+
+	for k, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("lambda %g: %w", lambdas[k], err)
+		}
+	}
+	return results, nil
+}
+
+// sweepSmooth returns the smooth of y for the band penalty P scaled by lambda, and its root mean square
+// leave-one-out prediction error.
+func sweepSmooth(y []float64, P *symBand, lambda float64) ([]float64, float64, error) {
+	z, h, err := bandSmooth(y, P, lambda)
+	if err != nil {
+		return nil, 0, err
+	}
+	return z, leaveOneOutError(y, z, h), nil
+}
+
+// bandSmooth returns the smooth of y for the band penalty P scaled by lambda, and the diagonal of its hat matrix.
+func bandSmooth(y []float64, P *symBand, lambda float64) (z, h []float64, err error) {
+	chol, err := pooledFactor(context.Background(), P, lambda, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer putFactor(chol)
+	return chol.solve(y), chol.inverseDiagonal(), nil
+}
+
+// leaveOneOutError returns the root mean square leave-one-out prediction error of the smooth z of y with hat
+// diagonal h.
+func leaveOneOutError(y, z, h []float64) float64 {
+	var sum float64
+	for i := range y {
+		r := (y[i] - z[i]) / (1 - h[i])
+		sum += r * r
+	}
+	return math.Sqrt(sum / float64(len(y)))
+}
@@ -0,0 +1,101 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSmoothSweep(t *testing.T) {
+	rng := rand.New(rand.NewSource(25))
+	n := 300
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/20) + rng.NormFloat64()*0.2
+	}
+	lambdas := []float64{0.1, 1, 10, 100, 1e4}
+
+	results, err := SmoothSweep(y, lambdas, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply SmoothSweep: %v", err)
+	}
+	if len(results) != len(lambdas) {
+		t.Fatalf("got %d results, want %d", len(results), len(lambdas))
+	}
+	for k, res := range results {
+		if res.Lambda != lambdas[k] {
+			t.Errorf("result %d: lambda %f, want %f", k, res.Lambda, lambdas[k])
+		}
+		want, err := WESmoother(y, lambdas[k], 2)
+		if err != nil {
+			t.Fatalf("Failed to apply WESmoother: %v", err)
+		}
+		for i := range want {
+			if math.Abs(res.Smooth[i]-want[i]) > 1e-8 {
+				t.Fatalf("lambda %f, index %d: got %f, want %f", lambdas[k], i, res.Smooth[i], want[i])
+			}
+		}
+		cv, err := CrossValidationError(y, lambdas[k], 2)
+		if err != nil {
+			t.Fatalf("Failed to apply CrossValidationError: %v", err)
+		}
+		if math.Abs(res.CVError-cv) > 1e-8*cv {
+			t.Errorf("lambda %f: CV error %f, want %f", lambdas[k], res.CVError, cv)
+		}
+	}
+
+	if _, err := SmoothSweep(y, nil, 2); err == nil {
+		t.Errorf("expected an error for no lambdas")
+	}
+	if _, err := SmoothSweep(y, []float64{1, -1}, 2); err == nil {
+		t.Errorf("expected an error for a negative lambda")
+	}
+}
+
+func TestSmoothSweepParallel(t *testing.T) {
+	rng := rand.New(rand.NewSource(26))
+	y := make([]float64, 200)
+	for i := range y {
+		y[i] = math.Cos(float64(i)/15) + rng.NormFloat64()*0.3
+	}
+	lambdas := make([]float64, 25)
+	for k := range lambdas {
+		lambdas[k] = math.Pow(10, float64(k)/4-1)
+	}
+
+	want, err := SmoothSweep(y, lambdas, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply SmoothSweep: %v", err)
+	}
+	for _, workers := range []int{0, 1, 4, 100} {
+		got, err := SmoothSweepParallel(y, lambdas, 2, workers)
+		if err != nil {
+			t.Fatalf("Failed to apply SmoothSweepParallel with %d workers: %v", workers, err)
+		}
+		for k := range want {
+			if got[k].Lambda != want[k].Lambda || got[k].CVError != want[k].CVError {
+				t.Fatalf("%d workers, result %d: got %v, want %v", workers, k, got[k].CVError, want[k].CVError)
+			}
+			for i := range want[k].Smooth {
+				if got[k].Smooth[i] != want[k].Smooth[i] {
+					t.Fatalf("%d workers, result %d, index %d: smooth differs", workers, k, i)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkSmoothSweep(b *testing.B) {
+	rng := rand.New(rand.NewSource(25))
+	y := make([]float64, 500)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/20) + rng.NormFloat64()*0.2
+	}
+	lambdas := []float64{1, 3, 10, 30, 100, 300, 1000, 3000, 1e4, 3e4}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := SmoothSweep(y, lambdas, 2); err != nil {
+			b.Fatalf("Failed to apply SmoothSweep: %v", err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package smoother
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// defaultTrajectoryIterations is the number of penalty updates SmoothTrajectory makes when
+// TrajectoryLimits.MaxIterations is not positive.
+const defaultTrajectoryIterations = 50
+
+// TrajectoryLimits bounds the motion of a smoothed trajectory. Speed and acceleration are measured per sample as
+// the magnitude of the first and second difference of the position vectors, so they share the units of the
+// positions. A limit of zero is not enforced.
+type TrajectoryLimits struct {
+	MaxSpeed float64
+	MaxAccel float64
+
+	// MaxIterations caps the number of penalty updates, 50 when zero or negative.
+	MaxIterations int
+}
+
+// SmoothTrajectory applies the Whittaker-Eilers smoothing function to a multi-dimensional trajectory, such as a
+// series of noisy pose estimates, while keeping its speed and acceleration within limits. The path is indexed
+// by dimension and then by sample, so path[0] holds every x position, path[1] every y position and so on.
+//
+// Every dimension is smoothed with lambda and order d. Wherever the smoothed trajectory exceeds a limit, an
+// additional first or second difference penalty is applied at that sample and grown tenfold on each iteration
+// until the limits hold. An error is returned if they still do not hold after MaxIterations updates. Every update
+// factorizes the band system once, shared by all dimensions, in O(n·d²).
+func SmoothTrajectory(path [][]float64, lambda float64, d int, limits TrajectoryLimits) ([][]float64, error) {
+	return SmoothTrajectoryCtx(context.Background(), path, lambda, d, limits)
+}
+
+// SmoothTrajectoryCtx is SmoothTrajectory, giving up with the error of ctx once ctx is done. ctx is checked before
+// every penalty update, and every update is reported to the ProgressFunc set by WithProgress with the largest
+// change of any position, from the raw path in the first update.
+func SmoothTrajectoryCtx(ctx context.Context, path [][]float64, lambda float64, d int, limits TrajectoryLimits, opts ...FitOption) ([][]float64, error) {
+	if len(path) == 0 {
+		return nil, errors.New("trajectory has no dimensions")
+	}
+	n := len(path[0])
+	for k := range path {
+		if len(path[k]) != n {
+			return nil, fmt.Errorf("%w: trajectory dimension %d has %d samples, want %d", ErrLengthMismatch, k, len(path[k]), n)
+		}
+	}
+
+	for k := range path {
+		if err := Validate(path[k], lambda, d); err != nil {
+			return nil, fmt.Errorf("trajectory dimension %d: %w", k, err)
+		}
+	}
+
+	iterations := limits.MaxIterations
+	if iterations <= 0 {
+		iterations = defaultTrajectoryIterations
+	}
+
+	// The limits add first and second difference penalties, so the band is wide enough for those as well
+	bw := max(d, 2)
+	penalty := pooledPenaltyBand(n, d)
+	base := newSymBand(n, bw)
+	for i := 0; i < n; i++ {
+		base.add(i, i, 1)
+		for j := i; j <= min(n-1, i+d); j++ {
+			base.add(i, j, lambda*penalty.at(i, j))
+		}
+	}
+	putBand(penalty)
+	A := newSymBand(n, bw)
+	chol := &bandCholesky{n: n, bw: bw, data: make([]float64, len(A.data))}
+	speed := make([]float64, max(n-1, 0))
+	accel := make([]float64, max(n-2, 0))
+	start := math.Max(lambda, 1)
+	progress := newProgressReporter(opts)
+	prev := path
+
+	for iter := 0; iter < iterations; iter++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		copy(A.data, base.data)
+		addDifferencePenaltyWeights(A, 1, speed)
+		addDifferencePenaltyWeights(A, 2, accel)
+		if err := factorizeBandInto(ctx, chol, A); err != nil {
+			return nil, err
+		}
+
+		// every dimension shares the system, so it is factorized once per update
+		smooth := make([][]float64, len(path))
+		for k := range path {
+			smooth[k] = chol.solve(path[k])
+		}
+		var change float64
+		for k := range smooth {
+			for i, v := range smooth[k] {
+				change = math.Max(change, math.Abs(v-prev[k][i]))
+			}
+		}
+		prev = smooth
+		if err := progress.report(change); err != nil {
+			return nil, err
+		}
+
+		violated := false
+		if limits.MaxSpeed > 0 {
+			violated = tightenLimit(smooth, 1, limits.MaxSpeed, speed, start) || violated
+		}
+		if limits.MaxAccel > 0 {
+			violated = tightenLimit(smooth, 2, limits.MaxAccel, accel, start) || violated
+		}
+		if !violated {
+			return smooth, nil
+		}
+	}
+
+	return nil, fmt.Errorf("trajectory limits not met after %d iterations", iterations)
+}
+
+// addDifferencePenaltyWeights adds D' * V * D to the band matrix P, where D is the difference matrix of the given
+// order, at most the bandwidth of P, and V is the diagonal matrix of the per-row penalty weights v, like
+// addDifferencePenalty does for a dense matrix.
+func addDifferencePenaltyWeights(P *symBand, order int, v []float64) {
+	coeffs := differenceCoefficients(order)
+	for i, vi := range v {
+		if vi == 0 {
+			continue
+		}
+		for a, ca := range coeffs {
+			for b := a; b < len(coeffs); b++ {
+				P.add(i+a, i+b, vi*ca*coeffs[b])
+			}
+		}
+	}
+}
+
+// tightenLimit measures the magnitude of the difference of the given order at every sample of the trajectory and
+// grows the matching penalty weight wherever it exceeds limit. It reports whether any sample exceeded the limit.
+func tightenLimit(path [][]float64, order int, limit float64, weights []float64, start float64) bool {
+	coeffs := differenceCoefficients(order)
+	violated := false
+	for i := range weights {
+		var sum float64
+		for k := range path {
+			var diff float64
+			for j, c := range coeffs {
+				diff += c * path[k][i+j]
+			}
+			sum += diff * diff
+		}
+		if math.Sqrt(sum) > limit*(1+1e-9) {
+			violated = true
+			if weights[i] == 0 {
+				weights[i] = start
+			} else {
+				weights[i] *= 10
+			}
+		}
+	}
+	return violated
+}
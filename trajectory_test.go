@@ -0,0 +1,117 @@
+package smoother
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSmoothTrajectory(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	n := 200
+	path := [][]float64{make([]float64, n), make([]float64, n)}
+	for i := 0; i < n; i++ {
+		// a jump half way through the x positions forces the speed limit to be enforced
+		x := float64(i) * 0.1
+		if i >= n/2 {
+			x += 5
+		}
+		path[0][i] = x + rng.NormFloat64()*0.2
+		path[1][i] = math.Sin(float64(i)/20) + rng.NormFloat64()*0.2
+	}
+
+	limits := TrajectoryLimits{MaxSpeed: 0.25, MaxAccel: 0.05}
+	smooth, err := SmoothTrajectory(path, 10, 2, limits)
+	if err != nil {
+		t.Fatalf("Failed to apply SmoothTrajectory: %v", err)
+	}
+
+	for i := 0; i+2 < n; i++ {
+		var speed, accel float64
+		for k := range smooth {
+			v := smooth[k][i+1] - smooth[k][i]
+			a := smooth[k][i+2] - 2*smooth[k][i+1] + smooth[k][i]
+			speed += v * v
+			accel += a * a
+		}
+		if math.Sqrt(speed) > limits.MaxSpeed*(1+1e-6) {
+			t.Errorf("sample %d: speed %f exceeds %f", i, math.Sqrt(speed), limits.MaxSpeed)
+		}
+		if math.Sqrt(accel) > limits.MaxAccel*(1+1e-6) {
+			t.Errorf("sample %d: acceleration %f exceeds %f", i, math.Sqrt(accel), limits.MaxAccel)
+		}
+	}
+
+	if _, err := SmoothTrajectory([][]float64{{1, 2, 3}, {1, 2}}, 10, 2, limits); err == nil {
+		t.Errorf("expected an error for mismatched dimensions")
+	}
+	for _, short := range [][][]float64{{{}}, {{1, 2}}} {
+		if _, err := SmoothTrajectory(short, 10, 2, limits); err == nil {
+			t.Errorf("expected an error for the too short trajectory %v", short)
+		}
+	}
+	if _, err := SmoothTrajectory(path, 10, 2, TrajectoryLimits{MaxSpeed: 0.25, MaxIterations: -1}); err != nil {
+		t.Errorf("negative MaxIterations not treated as the default: %v", err)
+	}
+}
+
+func TestSmoothTrajectoryLong(t *testing.T) {
+	// without a violated limit every dimension is the smooth of WESmoother
+	n := 300
+	path := [][]float64{make([]float64, n), make([]float64, n)}
+	for i := 0; i < n; i++ {
+		path[0][i] = math.Sin(float64(i) / 30)
+		path[1][i] = math.Cos(float64(i)/25) + 0.05*float64(i%4)
+	}
+	smooth, err := SmoothTrajectory(path, 50, 3, TrajectoryLimits{MaxSpeed: 10})
+	if err != nil {
+		t.Fatalf("Failed to apply SmoothTrajectory: %v", err)
+	}
+	for k := range path {
+		want, err := WESmoother(path[k], 50, 3)
+		if err != nil {
+			t.Fatalf("Failed to apply WESmoother: %v", err)
+		}
+		for i := range want {
+			if math.Abs(smooth[k][i]-want[i]) > 1e-9 {
+				t.Fatalf("dimension %d, index %d: got %g, want %g", k, i, smooth[k][i], want[i])
+			}
+		}
+	}
+
+	// a dense system of this length would take 80 GB
+	n = 100_000
+	path = [][]float64{make([]float64, n), make([]float64, n), make([]float64, n)}
+	for i := 0; i < n; i++ {
+		// a jump half way through forces the speed limit to be enforced
+		path[0][i] = float64(i) * 0.01
+		if i >= n/2 {
+			path[0][i] += 5
+		}
+		path[1][i] = math.Sin(float64(i) / 500)
+		path[2][i] = 0.2 * float64(i%2)
+	}
+	limits := TrajectoryLimits{MaxSpeed: 0.25}
+	updates := 0
+	count := WithProgress(func(Progress) error {
+		updates++
+		return nil
+	})
+	if smooth, err = SmoothTrajectoryCtx(context.Background(), path, 10, 2, limits, count); err != nil {
+		t.Fatalf("Failed to smooth a long trajectory: %v", err)
+	}
+	if updates < 2 {
+		t.Errorf("got %d penalty updates, want the speed limit to be enforced", updates)
+	}
+	for i := 0; i+1 < n; i++ {
+		var speed float64
+		for k := range smooth {
+			v := smooth[k][i+1] - smooth[k][i]
+			speed += v * v
+		}
+		if math.Sqrt(speed) > limits.MaxSpeed*(1+1e-6) {
+			t.Fatalf("sample %d: speed %f exceeds %f", i, math.Sqrt(speed), limits.MaxSpeed)
+		}
+	}
+}
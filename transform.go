@@ -0,0 +1,108 @@
+package smoother
+
+import (
+	"fmt"
+	"math"
+)
+
+// Transform is a variance-stabilizing transform applied to the data before smoothing and undone afterwards, so
+// that heteroscedastic data reaches the penalized fit with roughly constant noise.
+type Transform interface {
+	// Forward transforms a data value, returning an error if the value is outside the domain of the transform.
+	Forward(y float64) (float64, error)
+	// Inverse maps a smoothed value back to the scale of the data.
+	Inverse(v float64) float64
+}
+
+// SqrtTransform returns the square root transform, which stabilizes the variance of counts. Smoothed values
+// below zero map back to zero.
+func SqrtTransform() Transform {
+	return sqrtTransform{}
+}
+
+// LogTransform returns the natural logarithm transform for strictly positive data with multiplicative noise.
+// Unlike WESmootherLog it applies no bias correction, so the result estimates the median.
+func LogTransform() Transform {
+	return BoxCoxTransform(0)
+}
+
+// BoxCoxTransform returns the Box-Cox transform with parameter lambda, (y^lambda - 1) / lambda, or log(y) for a
+// lambda of zero, for strictly positive data. A lambda of 0.5 behaves like the square root and 1 leaves the data
+// as is up to a shift. Smoothed values outside the range of the transform map back to zero for a positive lambda
+// and to +Inf for a negative one.
+func BoxCoxTransform(lambda float64) Transform {
+	return boxCoxTransform{lambda: lambda}
+}
+
+// sqrtTransform is the square root transform.
+type sqrtTransform struct{}
+
+// Forward implements Transform.
+func (sqrtTransform) Forward(y float64) (float64, error) {
+	if !(y >= 0) {
+		return 0, fmt.Errorf("square root transform of %f: value must not be negative", y)
+	}
+	return math.Sqrt(y), nil
+}
+
+// Inverse implements Transform.
+func (sqrtTransform) Inverse(v float64) float64 {
+	v = math.Max(v, 0)
+	return v * v
+}
+
+// boxCoxTransform is the Box-Cox transform with parameter lambda.
+type boxCoxTransform struct {
+	lambda float64
+}
+
+// Forward implements Transform.
+func (t boxCoxTransform) Forward(y float64) (float64, error) {
+	if !(y > 0) {
+		return 0, fmt.Errorf("box-cox transform of %f: value must be positive", y)
+	}
+	if t.lambda == 0 {
+		return math.Log(y), nil
+	}
+	return (math.Pow(y, t.lambda) - 1) / t.lambda, nil
+}
+
+// Inverse implements Transform.
+func (t boxCoxTransform) Inverse(v float64) float64 {
+	if t.lambda == 0 {
+		return math.Exp(v)
+	}
+	base := t.lambda*v + 1
+	if base <= 0 {
+		if t.lambda > 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return math.Pow(base, 1/t.lambda)
+}
+
+// WESmootherTransformed applies the Whittaker-Eilers smoothing function to y on the scale of the transform t and
+// maps the smooth back to the scale of y, so the penalized fit sees data with stabilized variance.
+func WESmootherTransformed(y []float64, lambda float64, d int, t Transform) ([]float64, error) {
+	if t == nil {
+		return WESmoother(y, lambda, d)
+	}
+	ty := make([]float64, len(y))
+	for i, v := range y {
+		tv, err := t.Forward(v)
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %w", i, err)
+		}
+		ty[i] = tv
+	}
+
+	z, err := WESmoother(ty, lambda, d)
+	if err != nil {
+		return nil, err
+	}
+	for i := range z {
+		z[i] = t.Inverse(z[i])
+	}
+	return z, nil
+}
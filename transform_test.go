@@ -0,0 +1,81 @@
+package smoother
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTransforms(t *testing.T) {
+	transforms := map[string]Transform{
+		"sqrt":     SqrtTransform(),
+		"log":      LogTransform(),
+		"box-cox":  BoxCoxTransform(0.3),
+		"negative": BoxCoxTransform(-0.5),
+	}
+	for name, tr := range transforms {
+		for _, y := range []float64{0.01, 1, 7.5, 1e4} {
+			v, err := tr.Forward(y)
+			if err != nil {
+				t.Fatalf("%s: Failed to transform %f: %v", name, y, err)
+			}
+			if got := tr.Inverse(v); math.Abs(got-y) > 1e-9*y {
+				t.Errorf("%s: round trip of %f gave %f", name, y, got)
+			}
+		}
+	}
+
+	if _, err := SqrtTransform().Forward(-1); err == nil {
+		t.Errorf("expected an error for the square root of a negative value")
+	}
+	if _, err := LogTransform().Forward(0); err == nil {
+		t.Errorf("expected an error for the logarithm of zero")
+	}
+	if got := SqrtTransform().Inverse(-2); got != 0 {
+		t.Errorf("negative smoothed value should map back to zero, got %f", got)
+	}
+	if got := BoxCoxTransform(0.5).Inverse(-3); got != 0 {
+		t.Errorf("smoothed value below the range should map back to zero, got %f", got)
+	}
+}
+
+func TestWESmootherTransformed(t *testing.T) {
+	rng := rand.New(rand.NewSource(15))
+	n := 400
+	rate := make([]float64, n)
+	y := make([]float64, n)
+	for i := range y {
+		// counts whose variance grows with their mean
+		rate[i] = 2 + 50*math.Exp(-math.Pow(float64(i-200)/60, 2))
+		y[i] = math.Max(0, rate[i]+math.Sqrt(rate[i])*rng.NormFloat64())
+	}
+
+	z, err := WESmootherTransformed(y, 1000, 2, SqrtTransform())
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherTransformed: %v", err)
+	}
+	for i := 20; i < n-20; i++ {
+		if math.Abs(z[i]-rate[i]) > 0.1*rate[i]+1 {
+			t.Fatalf("index %d: got %f, want about %f", i, z[i], rate[i])
+		}
+	}
+
+	plain, err := WESmootherTransformed(y, 1000, 2, nil)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmootherTransformed: %v", err)
+	}
+	want, err := WESmoother(y, 1000, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	for i := range plain {
+		if plain[i] != want[i] {
+			t.Fatalf("index %d: without a transform got %f, want %f", i, plain[i], want[i])
+		}
+	}
+
+	y[3] = -1
+	if _, err := WESmootherTransformed(y, 1000, 2, LogTransform()); err == nil {
+		t.Errorf("expected an error for a value outside the domain")
+	}
+}
@@ -0,0 +1,43 @@
+package smoother
+
+// smoothTridiagonalTo sets z to the solution of (I + λD'D) z = y for first order differences with the Thomas
+// algorithm. D'D has 1, 2, ..., 2, 1 on its diagonal and -1 on both off diagonals, so I + λD'D is tridiagonal
+// and diagonally dominant, and the elimination needs no pivoting. It takes O(n) time and, besides z, only pooled
+// workspace, which suits very long streams where a first order penalty is enough. z may be y, since every step
+// only reads y at the index it writes.
+func smoothTridiagonalTo(z, y []float64, lambda float64) error {
+	n := len(y)
+	buf := getFloats(2 * n)
+	defer putFloats(buf)
+	upper, pivots := (*buf)[:n], (*buf)[n:]
+
+	// Eliminate the subdiagonal, upper holds the off diagonal of the eliminated row divided by its pivot
+	for i := 0; i < n; i++ {
+		a := 2.0
+		if i == 0 || i == n-1 {
+			a = 1
+		}
+		pivot := 1 + lambda*a
+		if i > 0 {
+			pivot += lambda * upper[i-1]
+		}
+		if !(pivot > 0) {
+			return ErrNotPositiveDefinite
+		}
+		upper[i] = -lambda / pivot
+		pivots[i] = pivot
+	}
+
+	// Apply the elimination to y, then substitute back
+	for i := 0; i < n; i++ {
+		rhs := y[i]
+		if i > 0 {
+			rhs += lambda * z[i-1]
+		}
+		z[i] = rhs / pivots[i]
+	}
+	for i := n - 2; i >= 0; i-- {
+		z[i] -= upper[i] * z[i+1]
+	}
+	return nil
+}
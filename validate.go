@@ -0,0 +1,162 @@
+package smoother
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxConditionedLambda bounds lambda * 4^d, the largest eigenvalue of the penalty, above which the system solved
+// by the smoother is too ill-conditioned for float64 and the result can no longer be trusted.
+const maxConditionedLambda = 1e14
+
+// maxConditionedLambda32 is maxConditionedLambda for a factorization stored in float32, which carries about
+// seven digits instead of sixteen.
+const maxConditionedLambda32 = 1e6
+
+// Sentinel errors for the kinds of failure callers commonly branch on. Errors returned by the package wrap them,
+// so they are tested with errors.Is, and an *InputError unwraps to the sentinel of its problem.
+var (
+	// ErrNotPositiveDefinite reports that the Cholesky factorization of a penalized system failed because the
+	// system is not numerically positive definite, for example when too many weights are zero.
+	ErrNotPositiveDefinite = errors.New("cholesky decomposition failed")
+	// ErrSeriesTooShort reports a series with too few values for the requested order or method.
+	ErrSeriesTooShort = errors.New("series too short")
+	// ErrInvalidLambda reports a smoothing parameter that is not positive, not finite or too large to solve reliably.
+	ErrInvalidLambda = errors.New("invalid lambda")
+	// ErrLengthMismatch reports arguments that must be equally long but are not.
+	ErrLengthMismatch = errors.New("length mismatch")
+	// ErrMemoryLimit reports a request whose estimated memory exceeds the limit given to CheckMemory.
+	ErrMemoryLimit = errors.New("memory limit exceeded")
+)
+
+// InputError describes input that defeats the smoother, such as a series that is too short or a lambda that makes
+// the system numerically singular. It carries a diagnostic and a suggested fix so that callers, for example a
+// web service answering with 422 Unprocessable Entity instead of 500, can report something actionable.
+type InputError struct {
+	// Field names the offending argument: "y", "lambda", "d" or "w" for weights.
+	Field string
+	// Problem describes what is wrong with the argument.
+	Problem string
+	// Suggestion describes how to fix it.
+	Suggestion string
+	// Err is the sentinel error of the problem, such as ErrSeriesTooShort, or nil if there is none.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *InputError) Error() string {
+	return fmt.Sprintf("invalid %s: %s (%s)", e.Field, e.Problem, e.Suggestion)
+}
+
+// Unwrap returns the sentinel error of the problem, so errors.Is(err, ErrSeriesTooShort) holds for an
+// *InputError about a series that is too short.
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks that y, lambda and d can be smoothed, returning an *InputError describing the first problem
+// found. It rejects series too short for the order, series with NaN or infinite values, lambdas that are not
+// positive and finite, orders below 1, and lambdas so large that the system cannot be solved reliably in float64.
+func Validate(y []float64, lambda float64, d int) error {
+	if err := checkLength(len(y), d); err != nil {
+		return err
+	}
+	if err := checkLambda(lambda); err != nil {
+		return err
+	}
+	if err := checkFinite(y); err != nil {
+		return err
+	}
+	return checkConditioning(lambda, d, maxConditionedLambda)
+}
+
+// checkConditioning returns an *InputError if lambda * 4^d, the largest eigenvalue of the penalty, exceeds bound.
+func checkConditioning(lambda float64, d int, bound float64) error {
+	if lambda*math.Pow(4, float64(d)) > bound {
+		return &InputError{
+			Field:      "lambda",
+			Problem:    fmt.Sprintf("lambda %g is too large for order %d to solve reliably", lambda, d),
+			Suggestion: fmt.Sprintf("use a lambda of at most %g", bound/math.Pow(4, float64(d))),
+			Err:        ErrInvalidLambda,
+		}
+	}
+	return nil
+}
+
+// checkLength returns an *InputError unless d is at least 1 and a series of length m is long enough for
+// differences of order d.
+func checkLength(m, d int) error {
+	if d < 1 {
+		return &InputError{
+			Field:      "d",
+			Problem:    fmt.Sprintf("order %d must be at least 1", d),
+			Suggestion: "use an order of 1 or more, 2 is the usual choice",
+		}
+	}
+	if m <= d {
+		return &InputError{
+			Field:      "y",
+			Problem:    fmt.Sprintf("series of length %d too short for order %d", m, d),
+			Suggestion: fmt.Sprintf("provide at least %d values or lower the order", d+1),
+			Err:        ErrSeriesTooShort,
+		}
+	}
+	return nil
+}
+
+// checkLambda returns an *InputError unless lambda is a finite, positive smoothing parameter.
+func checkLambda(lambda float64) error {
+	if !(lambda > 0) || math.IsInf(lambda, 1) {
+		return &InputError{
+			Field:      "lambda",
+			Problem:    fmt.Sprintf("lambda %f must be positive and finite", lambda),
+			Suggestion: "use a positive lambda, larger values smooth more",
+			Err:        ErrInvalidLambda,
+		}
+	}
+	return nil
+}
+
+// checkWeights returns an *InputError unless every weight of w is non-negative and finite.
+func checkWeights(w []float64) error {
+	for i, wi := range w {
+		if !(wi >= 0) || math.IsInf(wi, 1) {
+			return &InputError{
+				Field:      "w",
+				Problem:    fmt.Sprintf("weight %f at index %d must be non-negative and finite", wi, i),
+				Suggestion: "give missing values a weight of zero rather than NaN",
+			}
+		}
+	}
+	return nil
+}
+
+// checkFinite returns an *InputError if y contains NaN or infinite values.
+func checkFinite[T Float](y []T) error {
+	bad := 0
+	first := -1
+	for i, v := range y {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			if first < 0 {
+				first = i
+			}
+			bad++
+		}
+	}
+	switch {
+	case bad == 0:
+		return nil
+	case bad == len(y):
+		return &InputError{
+			Field:      "y",
+			Problem:    "every value is NaN or infinite",
+			Suggestion: "check the data source, there is nothing to smooth",
+		}
+	}
+	return &InputError{
+		Field:      "y",
+		Problem:    fmt.Sprintf("%d of %d values are NaN or infinite, the first at index %d", bad, len(y), first),
+		Suggestion: "remove the missing values or give them zero weight",
+	}
+}
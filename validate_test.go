@@ -0,0 +1,107 @@
+package smoother
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name   string
+		y      []float64
+		lambda float64
+		d      int
+		field  string
+		target error
+	}{
+		{"length 1", []float64{1}, 10, 2, "y", ErrSeriesTooShort},
+		{"all NaN", []float64{nan, nan, nan, nan}, 10, 2, "y", nil},
+		{"some Inf", []float64{1, 2, math.Inf(1), 4}, 10, 2, "y", nil},
+		{"negative lambda", []float64{1, 2, 3, 4}, -1, 2, "lambda", ErrInvalidLambda},
+		{"NaN lambda", []float64{1, 2, 3, 4}, nan, 2, "lambda", ErrInvalidLambda},
+		{"absurd lambda", []float64{1, 2, 3, 4}, 1e20, 2, "lambda", ErrInvalidLambda},
+		{"zero lambda", []float64{1, 2, 3, 4}, 0, 2, "lambda", ErrInvalidLambda},
+		{"negative order", []float64{1, 2, 3, 4}, 10, -1, "d", nil},
+		{"zero order", []float64{1, 2, 3, 4}, 10, 0, "d", nil},
+	}
+
+	for _, tt := range tests {
+		err := Validate(tt.y, tt.lambda, tt.d)
+		var inputErr *InputError
+		if !errors.As(err, &inputErr) {
+			t.Errorf("%s: got %v, want an *InputError", tt.name, err)
+			continue
+		}
+		if inputErr.Field != tt.field || inputErr.Suggestion == "" {
+			t.Errorf("%s: got field %q and suggestion %q", tt.name, inputErr.Field, inputErr.Suggestion)
+		}
+		if tt.target != nil && !errors.Is(err, tt.target) {
+			t.Errorf("%s: got %v, want it to match %v", tt.name, err, tt.target)
+		}
+		if _, err := WESmoother(tt.y, tt.lambda, tt.d); !errors.As(err, &inputErr) {
+			t.Errorf("%s: WESmoother returned %v, want an *InputError", tt.name, err)
+		}
+	}
+
+	if err := Validate([]float64{1, 2, 3, 4}, 10, 2); err != nil {
+		t.Errorf("valid input rejected: %v", err)
+	}
+}
+
+func TestDegenerateInputs(t *testing.T) {
+	y := []float64{1, 2, 3, math.NaN(), 5, 6}
+	clean := []float64{1, 2, 3, 4, 5, 6}
+	calls := map[string]func() error{
+		"mixed NaN": func() error { _, err := WESmootherMixed(y, Penalty{Lambda: 1, Order: 2}); return err },
+		"mixed zero lambda": func() error {
+			_, err := WESmootherMixed(clean, Penalty{Lambda: 0, Order: 2})
+			return err
+		},
+		"pinned NaN lambda": func() error { _, err := WESmootherPinned(clean, math.NaN(), 2, nil); return err },
+		"l1 NaN":            func() error { _, err := WESmootherL1(y, 1, 2); return err },
+		"trajectory NaN":    func() error { _, err := SmoothTrajectory([][]float64{clean, y}, 1, 2, TrajectoryLimits{}); return err },
+		"segmented NaN": func() error {
+			_, err := WESmootherSegmented(y, 2, 3, []ActivityLambda{{MaxVariance: 1, Lambda: 1}})
+			return err
+		},
+		"stream zero order": func() error { _, err := NewStreamSmoother(10, 1, 0); return err },
+		"stream Inf lambda": func() error { _, err := NewStreamSmoother(10, math.Inf(1), 2); return err },
+		"calibration zero lambda": func() error {
+			_, err := FitCalibrationCurve(clean, clean, WithCalibrationLambda(0))
+			return err
+		},
+		"calibration NaN": func() error { _, err := FitCalibrationCurve(clean, y); return err },
+	}
+	for name, call := range calls {
+		var inputErr *InputError
+		if err := call(); !errors.As(err, &inputErr) {
+			t.Errorf("%s: got %v, want an *InputError", name, err)
+		}
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	y := []float64{1, 2, 3, 4, 5}
+	if _, err := FlagOutliers(y, y[:4], 3); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("FlagOutliers: got %v, want ErrLengthMismatch", err)
+	}
+	if _, err := WESmootherSigma(y, []float64{1}, 1, 2, 0.95); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("WESmootherSigma: got %v, want ErrLengthMismatch", err)
+	}
+	if _, err := Derivative(y[:2], 1, 1, 1); !errors.Is(err, ErrSeriesTooShort) {
+		t.Errorf("Derivative: got %v, want ErrSeriesTooShort", err)
+	}
+	if _, err := OptimizeLambda(y, 2, 10, 1, GCV); !errors.Is(err, ErrInvalidLambda) {
+		t.Errorf("OptimizeLambda: got %v, want ErrInvalidLambda", err)
+	}
+
+	// with every weight zero the penalized system is singular
+	if _, err := New(WithWeights(make([]float64, 5))); !errors.Is(err, ErrNotPositiveDefinite) {
+		t.Errorf("New: got %v, want ErrNotPositiveDefinite", err)
+	}
+	if _, err := solvePenalized(y, make([]float64, 5), penaltyMatrix(5, 1, 2), nil); !errors.Is(err, ErrNotPositiveDefinite) {
+		t.Errorf("solvePenalized: got %v, want ErrNotPositiveDefinite", err)
+	}
+}
@@ -0,0 +1,70 @@
+package smoother
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// WESmootherVec applies the Whittaker-Eilers smoothing function to the gonum vector y, like WESmoother.
+func WESmootherVec(y mat.Vector, lambda float64, d int) (*mat.VecDense, error) {
+	s, err := NewSmoother(lambda, d)
+	if err != nil {
+		return nil, err
+	}
+	return s.SmoothVec(y)
+}
+
+// SmoothVec returns the smooth of the gonum vector y as a new vector.
+func (s *Smoother) SmoothVec(y mat.Vector) (*mat.VecDense, error) {
+	if y.Len() == 0 {
+		return nil, checkLength(0, s.d)
+	}
+	z := mat.NewVecDense(y.Len(), nil)
+	if err := s.SmoothVecTo(z, y); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// SmoothVecTo writes the smooth of the gonum vector y into dst, which must be as long as y and may be y itself.
+// Both vectors may be views with any stride, such as a column of a matrix. A dst with unit stride is solved in
+// place, any other dst goes through a pooled workspace, so neither allocates once the factorization and a
+// workspace for the length of y exist.
+func (s *Smoother) SmoothVecTo(dst *mat.VecDense, y mat.Vector) error {
+	n := y.Len()
+	if dst.Len() != n {
+		return fmt.Errorf("%w: dst has %d values, y has %d", ErrLengthMismatch, dst.Len(), n)
+	}
+	if err := checkLength(n, s.d); err != nil {
+		return err
+	}
+
+	raw := dst.RawVector()
+	work := raw.Data[:n]
+	if raw.Inc != 1 {
+		buf := getFloats(n)
+		defer putFloats(buf)
+		work = *buf
+	}
+	if yv, ok := y.(*mat.VecDense); ok {
+		yr := yv.RawVector()
+		for i := range work {
+			work[i] = yr.Data[i*yr.Inc]
+		}
+	} else {
+		for i := range work {
+			work[i] = y.AtVec(i)
+		}
+	}
+
+	if err := s.SmoothTo(work, work); err != nil {
+		return err
+	}
+	if raw.Inc != 1 {
+		for i, v := range work {
+			raw.Data[i*raw.Inc] = v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package smoother
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSmoothVec(t *testing.T) {
+	n := 200
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Sin(float64(i)/15) + 0.3*math.Cos(float64(i)*1.7)
+	}
+	want, err := WESmoother(y, 50, 2)
+	if err != nil {
+		t.Fatalf("Failed to apply WESmoother: %v", err)
+	}
+	check := func(name string, z mat.Vector) {
+		t.Helper()
+		for i := range want {
+			if math.Abs(z.AtVec(i)-want[i]) > 1e-9 {
+				t.Fatalf("%s, index %d: got %f, want %f", name, i, z.AtVec(i), want[i])
+			}
+		}
+	}
+
+	z, err := WESmootherVec(mat.NewVecDense(n, append([]float64(nil), y...)), 50, 2)
+	if err != nil {
+		t.Fatalf("Failed to smooth vector: %v", err)
+	}
+	check("contiguous", z)
+
+	// columns of a matrix are strided views, smooth the first column into the second
+	m := mat.NewDense(n, 3, nil)
+	m.SetCol(0, y)
+	s, err := NewSmoother(50, 2)
+	if err != nil {
+		t.Fatalf("Failed to create Smoother: %v", err)
+	}
+	src, dst := m.ColView(0).(*mat.VecDense), m.ColView(1).(*mat.VecDense)
+	if err := s.SmoothVecTo(dst, src); err != nil {
+		t.Fatalf("Failed to smooth column: %v", err)
+	}
+	check("strided", dst)
+	check("matrix column", m.ColView(1))
+	for i := 0; i < n; i++ {
+		if m.At(i, 0) != y[i] || m.At(i, 2) != 0 {
+			t.Fatalf("row %d: neighbouring columns changed", i)
+		}
+	}
+
+	// strided smoothing in place does not allocate
+	if err := s.SmoothVecTo(src, src); err != nil {
+		t.Fatalf("Failed to smooth column in place: %v", err)
+	}
+	check("in place", src)
+	allocs := testing.AllocsPerRun(50, func() {
+		if err := s.SmoothVecTo(dst, src); err != nil {
+			t.Fatalf("Failed to smooth column: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("SmoothVecTo allocated %f times per call, want 0", allocs)
+	}
+
+	// any other mat.Vector is read element by element
+	m.SetCol(0, y)
+	z, err = s.SmoothVec(mat.TransposeVec{Vector: m.ColView(0)})
+	if err != nil {
+		t.Fatalf("Failed to smooth generic vector: %v", err)
+	}
+	check("generic", z)
+
+	if _, err := s.SmoothVec(mat.NewVecDense(2, nil)); err == nil {
+		t.Errorf("expected an error for a too short vector")
+	}
+	if err := s.SmoothVecTo(mat.NewVecDense(3, nil), src); err == nil {
+		t.Errorf("expected an error for mismatched lengths")
+	}
+}
@@ -19,31 +19,14 @@
 package smoother
 
 import (
-	"errors"
+	"math"
 
-	"github.com/james-bowman/sparse"
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/lapack/lapack64"
 	"gonum.org/v1/gonum/mat"
 )
 
-// speye creates an identity matrix of size N in Compressed Sparse Row (CSR) format.
-// First we initialize three slices: data, indices, and indptr, which are used to create the CSR matrix.
-// The data slice is then filled with 1s, and the indices and indptr slices are filled with increasing
-// integers from 0 to N-1.
-func speye(n int) *sparse.CSR {
-	data := make([]float64, n)
-	indices := make([]int, n)
-	indptr := make([]int, n+1)
-
-	for i := 0; i < n; i++ {
-		data[i] = 1
-		indices[i] = i
-		indptr[i] = i
-	}
-	indptr[n] = n
-
-	return sparse.NewCSR(n, n, indices, indptr, data)
-}
-
 // vecDiff calculates the element-wise difference between two slices a and b, which should be the same length.
 // A new slice where each element is the difference between the corresponding elements in a and b is returned.
 func vecDiff(a, b []float64) []float64 {
@@ -54,83 +37,293 @@ func vecDiff(a, b []float64) []float64 {
 	return diff
 }
 
-// differenceMatrix creates a difference matrix of size n with order d by first creating a vector of coefficients,
-// which are then used to fill a Compressed Spares Row (CSR) matrix.
-func differenceMatrix(n int, order int) *sparse.CSR {
+// differenceCoefficients returns the order+1 coefficients of a single row of a difference matrix with order d.
+func differenceCoefficients(order int) []float64 {
 	coeffs := make([]float64, 2*order+1)
 	coeffs[order] = 1.0
 
 	for i := 0; i < order; i++ {
 		coeffs = vecDiff(coeffs[:len(coeffs)-1], coeffs[1:])
 	}
+	return coeffs
+}
 
-	nRows := n - order
-	data := make([]float64, nRows*n)
-	indices := make([]int, nRows*n)
-	indptr := make([]int, nRows+1)
+// differenceMatrix returns the (n-d) x n difference matrix of order d as a band matrix with no subdiagonals and
+// d superdiagonals, row i holding the coefficients of the difference in columns i through i+d.
+func differenceMatrix(n int, d int) *mat.BandDense {
+	coeffs := differenceCoefficients(d)
+	rows := n - d
+	data := make([]float64, rows*(d+1))
+	for i := 0; i < rows; i++ {
+		copy(data[i*(d+1):], coeffs)
+	}
+	return mat.NewBandDense(rows, n, 0, d, data)
+}
 
-	idx := 0
-	for i := 0; i < nRows; i++ {
-		indptr[i] = idx
-		for j := 0; j <= order; j++ {
-			data[idx] = coeffs[j]
-			indices[idx] = i + j
-			idx++
+// penaltyMatrix returns lambda * D' * D as a dense n x n matrix, where D is the difference matrix of order d. The
+// entries are expanded from the band of D'D, which is exact since its entries are integers.
+func penaltyMatrix(n int, lambda float64, d int) *mat.Dense {
+	B := differencePenaltyBand(n, d)
+	P := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j <= min(n-1, i+d); j++ {
+			v := lambda * B.at(i, j)
+			P.Set(i, j, v)
+			P.Set(j, i, v)
 		}
 	}
-	indptr[nRows] = idx
+	return P
+}
+
+// dividedDifferenceMatrix creates the dense divided difference matrix of order d for the increasing sampling
+// positions x, following ddmat.m from the supporting info of the paper. D * y gives the divided differences of
+// order d of y, which reduce to the plain differences scaled by the sample spacing when x is equally spaced.
+func dividedDifferenceMatrix(x []float64, d int) *mat.Dense {
+	m := len(x)
+	D := mat.NewDense(m, m, nil)
+	for i := 0; i < m; i++ {
+		D.Set(i, i, 1)
+	}
 
-	return sparse.NewCSR(nRows, n, indptr, indices, data)
+	for k := 1; k <= d; k++ {
+		rows := m - k
+		next := mat.NewDense(rows, m, nil)
+		for i := 0; i < rows; i++ {
+			dx := x[i+k] - x[i]
+			for j := 0; j < m; j++ {
+				next.Set(i, j, (D.At(i+1, j)-D.At(i, j))/dx)
+			}
+		}
+		D = next
+	}
+	return D
 }
 
-// WESmoother applies the Whittaker-Eilers smoothing function to a given data series y with a specified
-// parameter lambda and order d. It returns the smoothed series and an error if the Cholesky decomposition fails.
-// The data series is assumed to be collected from an equal sample rate.
-//
-// The function is based on the work by Paul H.C. Eilers "A Perfect Smoother".
-// A larger lambda will increase the smoothness of the series, but may also result in a loss of detail.
-func WESmoother(y []float64, lambda float64, d int) ([]float64, error) {
-	m := len(y)
+// gramMatrix returns D' * V * D, where V is the diagonal matrix of the per-row weights v.
+func gramMatrix(D *mat.Dense, v []float64) *mat.Dense {
+	VD := mat.DenseCopyOf(D)
+	for i, vi := range v {
+		row := VD.RawRowView(i)
+		for j := range row {
+			row[j] *= vi
+		}
+	}
 
-	E := speye(m)
-	D := differenceMatrix(m, d)
+	P := &mat.Dense{}
+	P.Mul(D.T(), VD)
+	return P
+}
 
-	// Convert sparse matrices to dense
-	EDense := mat.DenseCopyOf(E.ToDense())
-	DDense := mat.DenseCopyOf(D.ToDense())
+// addDifferencePenalty adds D' * V * D to the n x n matrix P in place, where D is the difference matrix of the
+// given order and V is the diagonal matrix of the per-row penalty weights v (of length n - order). Each row of D
+// only touches order+1 neighbouring values, so this is done without building D.
+func addDifferencePenalty(P *mat.Dense, order int, v []float64) {
+	coeffs := differenceCoefficients(order)
+	for i, vi := range v {
+		if vi == 0 {
+			continue
+		}
+		for a, ca := range coeffs {
+			for b, cb := range coeffs {
+				P.Set(i+a, i+b, P.At(i+a, i+b)+vi*ca*cb)
+			}
+		}
+	}
+}
 
-	// Compute D' * D
-	DTD := &mat.Dense{}
-	DTD.Mul(DDense.T(), DDense)
+// adaptivePenaltyMatrix returns D' * V * D for a difference matrix D of order d, where V holds a separate lambda
+// for every row of D. The lambdas are given per sample and each row uses the smallest lambda among the d+1
+// samples it spans, so that a locally relaxed region is not stiffened by its neighbours.
+func adaptivePenaltyMatrix(lambdas []float64, d int) *mat.Dense {
+	m := len(lambdas)
+	v := make([]float64, max(m-d, 0))
+	for i := range v {
+		v[i] = lambdas[i]
+		for j := 1; j <= d; j++ {
+			v[i] = math.Min(v[i], lambdas[i+j])
+		}
+	}
 
-	// Scale D' * D by lambda
-	DTD.Scale(lambda, DTD)
+	P := mat.NewDense(m, m, nil)
+	addDifferencePenalty(P, d, v)
+	return P
+}
 
-	// Add E and lambda * D' * D
-	A := &mat.Dense{}
-	A.Add(EDense, DTD)
-	r, _ := A.Dims()
-	sym := mat.NewSymDense(r, nil)
+// penalizedSystem is the factorized penalized least squares system W + P, restricted to the indices that are not
+// pinned. W is the diagonal matrix of the weights w (all ones when w is nil) and P is a symmetric penalty matrix.
+type penalizedSystem struct {
+	w      []float64
+	P      *mat.Dense
+	pinned []bool
+	free   []int
+	chol   mat.Cholesky
+}
+
+// weight returns the weight of the i-th value of the system.
+func (s *penalizedSystem) weight(i int) float64 {
+	if s.w == nil {
+		return 1
+	}
+	return s.w[i]
+}
+
+// factorizePenalized computes the Cholesky decomposition of W + P for every index not marked in pinned.
+func factorizePenalized(w []float64, P *mat.Dense, pinned []bool) (*penalizedSystem, error) {
+	m, _ := P.Dims()
+	s := &penalizedSystem{w: w, P: P, pinned: pinned, free: make([]int, 0, m)}
+	for i := 0; i < m; i++ {
+		if pinned == nil || !pinned[i] {
+			s.free = append(s.free, i)
+		}
+	}
+	r := len(s.free)
+	if r == 0 {
+		return s, nil
+	}
 
-	// Copy the upper triangular part of A to sym
-	for i := 0; i < r; i++ {
-		for j := i; j < r; j++ {
-			sym.SetSym(i, j, A.At(i, j))
+	// Copy the upper triangular part of W + P for the free indices
+	sym := mat.NewSymDense(r, nil)
+	for a, i := range s.free {
+		sym.SetSym(a, a, s.weight(i)+P.At(i, i))
+		for c := a + 1; c < r; c++ {
+			sym.SetSym(a, c, P.At(i, s.free[c]))
 		}
 	}
 
-	// Convert A to a dense matrix and compute its Cholesky decomposition
-	var chol mat.Cholesky
-	ok := chol.Factorize(sym)
+	// Compute the Cholesky decomposition
+	ok := s.chol.Factorize(sym)
 	if !ok {
-		return nil, errors.New("cholesky decomposition failed")
+		return nil, ErrNotPositiveDefinite
+	}
+	return s, nil
+}
+
+// solve solves (W + P) * z = W * y for z. Every pinned index is held at its value in y; the remaining values are
+// solved for with the pinned values moved to the right hand side, so the constraint is met exactly rather than
+// approximated with a huge weight.
+func (s *penalizedSystem) solve(y []float64) ([]float64, error) {
+	z := make([]float64, len(y))
+	copy(z, y)
+	r := len(s.free)
+	if r == 0 {
+		return z, nil
+	}
+
+	b := mat.NewVecDense(r, nil)
+	for a, i := range s.free {
+		rhs := s.weight(i) * y[i]
+		if s.pinned != nil {
+			for j, p := range s.pinned {
+				if p {
+					rhs -= s.P.At(i, j) * y[j]
+				}
+			}
+		}
+		b.SetVec(a, rhs)
+	}
+
+	// Solve the system of linear equations C * x = b for the free values
+	x := mat.NewVecDense(r, nil)
+	if err := s.chol.SolveVecTo(x, b); err != nil {
+		return nil, err
+	}
+	for a, i := range s.free {
+		z[i] = x.AtVec(a)
+	}
+
+	return z, nil
+}
+
+// inverseDiagonal returns the diagonal of the inverse of W + P. Pinned indices have no variance and are zero.
+func (s *penalizedSystem) inverseDiagonal() ([]float64, error) {
+	diag := make([]float64, len(s.free)+countPinned(s.pinned))
+	if len(s.free) == 0 {
+		return diag, nil
+	}
+
+	var inv mat.SymDense
+	if err := s.chol.InverseTo(&inv); err != nil {
+		return nil, err
 	}
+	for a, i := range s.free {
+		diag[i] = inv.At(a, a)
+	}
+	return diag, nil
+}
 
-	// Solve the system of linear equations C * z = y for z
-	z := make([]float64, m)
-	b := mat.NewVecDense(m, y)
-	x := mat.NewVecDense(m, z)
-	err := chol.SolveVecTo(x, b)
+// countPinned returns the number of pinned indices.
+func countPinned(pinned []bool) int {
+	var n int
+	for _, p := range pinned {
+		if p {
+			n++
+		}
+	}
+	return n
+}
+
+// solvePenalized solves the penalized least squares system (W + P) * z = W * y for z, holding every index marked
+// in pinned at its value in y.
+func solvePenalized(y, w []float64, P *mat.Dense, pinned []bool) ([]float64, error) {
+	s, err := factorizePenalized(w, P, pinned)
+	if err != nil {
+		return nil, err
+	}
+	return s.solve(y)
+}
 
-	return z, err
+// WESmoother applies the Whittaker-Eilers smoothing function to a given data series y with a specified
+// parameter lambda and order d. It returns the smoothed series and an error if the Cholesky decomposition fails.
+// The data series is assumed to be collected from an equal sample rate.
+//
+// The function is based on the work by Paul H.C. Eilers "A Perfect Smoother".
+// A larger lambda will increase the smoothness of the series, but may also result in a loss of detail.
+//
+// The common first and second order cases are solved as tridiagonal and pentadiagonal systems in O(n), higher
+// orders with a band Cholesky factorization in O(n·d²), unless SetSolver selects another solver.
+func WESmoother(y []float64, lambda float64, d int) ([]float64, error) {
+	if err := Validate(y, lambda, d); err != nil {
+		return nil, err
+	}
+	z := make([]float64, len(y))
+	if err := smoothTo(CurrentSolver(), z, y, lambda, d); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// WESmootherInPlace is WESmoother, overwriting y with its smooth instead of allocating a new slice, which halves
+// the peak memory of smoothing a huge series whose raw values are no longer needed. y only changes if the smooth
+// succeeds.
+func WESmootherInPlace(y []float64, lambda float64, d int) error {
+	if err := Validate(y, lambda, d); err != nil {
+		return err
+	}
+	return smoothTo(CurrentSolver(), y, y, lambda, d)
+}
+
+// smoothBandCholeskyTo sets z, which may be y, to the solution of (I + λD'D) z = y for differences of order d. The
+// system is assembled straight into the upper band storage of bandwidth d and factorized in place with the LAPACK
+// band Cholesky routines Dpbtrf and Dpbtrs, so it takes O(n·d²) time and never forms a dense matrix.
+// mat.BandCholesky is not used, as its factorization also estimates the condition number in O(n²).
+func smoothBandCholeskyTo(z, y []float64, lambda float64, d int) error {
+	n := len(y)
+	P := pooledPenaltyBand(n, d)
+	defer putBand(P)
+	for i := range P.data {
+		P.data[i] *= lambda
+	}
+	for i := 0; i < n; i++ {
+		P.add(i, i, 1)
+	}
+
+	// The upper band storage of LAPACK matches symBand row by row
+	A := blas64.SymmetricBand{Uplo: blas.Upper, N: n, K: d, Data: P.data, Stride: d + 1}
+	L, ok := lapack64.Pbtrf(A)
+	if !ok {
+		return ErrNotPositiveDefinite
+	}
+	copy(z, y)
+	lapack64.Pbtrs(L, blas64.General{Rows: n, Cols: 1, Data: z, Stride: 1})
+	return nil
 }
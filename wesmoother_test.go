@@ -1,48 +1,21 @@
 package smoother
 
 import (
-	"bufio"
-	"os"
-	"strconv"
-	"strings"
+	"math"
 	"testing"
-)
-
-func loadFile(filename string) ([]float64, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var numbers []float64
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		number, err := strconv.ParseFloat(strings.TrimSpace(line), 64)
-		if err != nil {
-			// ignore any non-numeric lines
-			continue
-		}
-		numbers = append(numbers, number)
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
 
-	return numbers, nil
-}
+	"github.com/grutz/go-whittaker-eilers/datasets"
+)
 
 func TestWESmoother(t *testing.T) {
-	filenames := []string{"docs/nmr.dat", "docs/wood.txt"}
+	names := []string{"nmr", "wood"}
 	lambda := 10.0
 	d := 2
 
-	for _, filename := range filenames {
-		data, err := loadFile(filename)
+	for _, name := range names {
+		data, err := datasets.Load(name)
 		if err != nil {
-			t.Fatalf("Failed to load file: %v", err)
+			t.Fatalf("Failed to load dataset: %v", err)
 		}
 
 		_, err = WESmoother(data, lambda, d)
@@ -53,14 +26,14 @@ func TestWESmoother(t *testing.T) {
 }
 
 func BenchmarkWESmoother(b *testing.B) {
-	filenames := []string{"docs/nmr.dat", "docs/wood.txt"}
+	names := []string{"nmr", "wood"}
 	lambda := 10.0
 	d := 2
 
-	for _, filename := range filenames {
-		data, err := loadFile(filename)
+	for _, name := range names {
+		data, err := datasets.Load(name)
 		if err != nil {
-			b.Fatalf("Failed to load file: %v", err)
+			b.Fatalf("Failed to load dataset: %v", err)
 		}
 
 		b.ResetTimer()
@@ -73,3 +46,69 @@ func BenchmarkWESmoother(b *testing.B) {
 		}
 	}
 }
+
+func TestSmoothBandCholesky(t *testing.T) {
+	data, err := datasets.Load("wood")
+	if err != nil {
+		t.Fatalf("Failed to load dataset: %v", err)
+	}
+	for _, d := range []int{1, 2, 3, 4} {
+		z := make([]float64, len(data))
+		if err := smoothBandCholeskyTo(z, data, 10, d); err != nil {
+			t.Fatalf("order %d: Failed to smooth: %v", d, err)
+		}
+		want, err := solvePenalized(data, nil, penaltyMatrix(len(data), 10, d), nil)
+		if err != nil {
+			t.Fatalf("order %d: Failed to solve: %v", d, err)
+		}
+		for i := range want {
+			if math.Abs(z[i]-want[i]) > 1e-8 {
+				t.Fatalf("order %d, index %d: got %f, want %f", d, i, z[i], want[i])
+			}
+		}
+	}
+}
+
+func BenchmarkSmoothBandCholesky(b *testing.B) {
+	y := make([]float64, 100000)
+	for i := range y {
+		y[i] = math.Sin(float64(i) / 25)
+	}
+	z := make([]float64, len(y))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := smoothBandCholeskyTo(z, y, 100, 3); err != nil {
+			b.Fatalf("Failed to smooth: %v", err)
+		}
+	}
+}
+
+func TestWESmootherInPlace(t *testing.T) {
+	data, err := datasets.Load("wood")
+	if err != nil {
+		t.Fatalf("Failed to load dataset: %v", err)
+	}
+	for _, d := range []int{1, 2, 3} {
+		want, err := WESmoother(data, 10, d)
+		if err != nil {
+			t.Fatalf("order %d: Failed to apply WESmoother: %v", d, err)
+		}
+		y := append([]float64(nil), data...)
+		if err := WESmootherInPlace(y, 10, d); err != nil {
+			t.Fatalf("order %d: Failed to smooth in place: %v", d, err)
+		}
+		for i := range want {
+			if y[i] != want[i] {
+				t.Fatalf("order %d, index %d: got %f, want %f", d, i, y[i], want[i])
+			}
+		}
+	}
+
+	y := []float64{1, 2, math.NaN(), 4}
+	if err := WESmootherInPlace(y, 10, 2); err == nil || y[0] != 1 || y[3] != 4 {
+		t.Errorf("got %v and %v, want an error and y unchanged", err, y)
+	}
+	if allocs := testing.AllocsPerRun(5, func() { _ = WESmootherInPlace(data, 10, 2) }); allocs != 0 && !raceEnabled {
+		t.Errorf("got %f allocations, want none", allocs)
+	}
+}